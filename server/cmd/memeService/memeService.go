@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/BassemHalim/memesHub/internal/config"
+	"github.com/BassemHalim/memesHub/internal/interceptors"
+	"github.com/BassemHalim/memesHub/internal/logging"
+	"github.com/BassemHalim/memesHub/internal/metrics"
+	"github.com/BassemHalim/memesHub/internal/server"
+	"github.com/BassemHalim/memesHub/internal/tracing"
+	"github.com/spf13/pflag"
+	"google.golang.org/grpc"
+)
+
+// memeService serves the MemeService over gRPC for gateways started with grpc.meme_service_addr
+func run(ctx context.Context, args []string) error {
+	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	flags := pflag.NewFlagSet("memeService", pflag.ContinueOnError)
+	loader := config.NewLoader(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	cfg, err := loader.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	// the HTTP settings are only used by the gateway
+	errs := []error{cfg.Database.Validate(), cfg.Storage.Validate(), cfg.Auth.Validate(), cfg.Tracing.Validate(), cfg.GRPC.Validate()}
+	if cfg.GRPC.ServiceToken == "" {
+		errs = append(errs, errors.New("grpc.service_token must be set, the gateway authenticates with it"))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid config:\n%w", err)
+	}
+
+	lvl := new(slog.LevelVar)
+	lvl.Set(cfg.SlogLevel())
+	log := slog.New(logging.NewHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: lvl}))).With("Service", "MEME_SERVICE")
+
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ctxFlush, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctxFlush); err != nil {
+			log.Error("Failed to flush the traces", "ERROR", err)
+		}
+	}()
+
+	memeService, err := server.NewMemeServiceFromConfig(cfg, log)
+	if err != nil {
+		return err
+	}
+	grpcServer, healthServer, err := server.NewGRPCServer(memeService, cfg.GRPC, grpc.ChainUnaryInterceptor(
+		interceptors.Logging(log),
+		interceptors.Metrics,
+		interceptors.Recovery(log),
+		interceptors.Auth(cfg.GRPC.ServiceToken, cfg.Auth.JWTSecret),
+	))
+	if err != nil {
+		return err
+	}
+	lis, err := net.Listen("tcp", cfg.GRPC.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", cfg.GRPC.ListenAddr, err)
+	}
+
+	go memeService.ReportHealth(ctx, healthServer, 10*time.Second)
+	// retry image moves that failed after their transaction committed
+	go memeService.RunStorageReconciler(ctx, 5*time.Minute)
+
+	metricsServer := &http.Server{Addr: cfg.GRPC.MetricsAddr, Handler: metrics.Handler()}
+	go func() {
+		if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error("Failed to serve the metrics", "Address", cfg.GRPC.MetricsAddr, "ERROR", err)
+		}
+	}()
+	defer metricsServer.Close()
+
+	errc := make(chan error, 1)
+	go func() {
+		log.Info("Starting meme service", "Address", cfg.GRPC.ListenAddr, "TLS", cfg.GRPC.TLSCertFile != "")
+		errc <- grpcServer.Serve(lis)
+	}()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Info("Shutting down meme service...")
+	// report NOT_SERVING so gateways stop sending requests, then finish the in-flight ones
+	healthServer.Shutdown()
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		log.Error("Meme service forced to shutdown")
+		grpcServer.Stop()
+	}
+	log.Info("Meme service exited gracefully")
+	return nil
+}
+
+func main() {
+	if err := run(context.Background(), os.Args[1:]); err != nil {
+		slog.Error("Failed to start the meme service", "ERROR", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/BassemHalim/memesHub/pkg/api"
+	"github.com/BassemHalim/memesHub/pkg/client"
+)
+
+var commands = map[string]*command{
+	"login": {
+		usage: "login",
+		help:  "log in and save the admin token for the next commands",
+		run:   login,
+	},
+	"pending": {
+		usage: "pending",
+		help:  "list the memes waiting for approval",
+		flags: pageFlags,
+		run:   pending,
+	},
+	"approve": {
+		usage: "approve ID...",
+		help:  "publish pending memes",
+		run:   eachID("approved", func(ctx context.Context, c *client.Client, id string) error { return c.Approve(ctx, id) }),
+	},
+	"reject": {
+		usage: "reject ID...",
+		help:  "move pending memes to the trash",
+		run:   eachID("rejected", func(ctx context.Context, c *client.Client, id string) error { return c.DeleteMeme(ctx, id) }),
+	},
+	"delete": {
+		usage: "delete ID...",
+		help:  "move memes to the trash",
+		run:   eachID("deleted", func(ctx context.Context, c *client.Client, id string) error { return c.DeleteMeme(ctx, id) }),
+	},
+	"retag": {
+		usage: "retag ID (--add TAGS | --set TAGS)",
+		help:  "add tags to a meme or replace its tags",
+		flags: func(f *pflag.FlagSet) {
+			f.StringSlice("add", nil, "comma separated tags to add")
+			f.StringSlice("set", nil, "comma separated tags replacing the current ones")
+		},
+		run: retag,
+	},
+	"upload": {
+		usage: "upload DIR --tags TAGS",
+		help:  "upload every JPEG, PNG and GIF image of a folder, named after their file",
+		flags: func(f *pflag.FlagSet) {
+			f.StringSlice("tags", nil, "comma separated tags of every meme")
+		},
+		run: upload,
+	},
+	"cache flush": {
+		usage: "cache flush",
+		help:  "empty the response cache",
+		run: func(ctx context.Context, env *env, args []string) error {
+			if err := env.client.FlushCache(ctx); err != nil {
+				return err
+			}
+			return env.print(map[string]string{"status": "flushed"}, []string{"STATUS"}, [][]string{{"flushed"}})
+		},
+	},
+	"banner set": {
+		usage: "banner set --text TEXT",
+		help:  "set the site banner, an empty text hides it",
+		flags: func(f *pflag.FlagSet) {
+			f.String("text", "", "banner text")
+			f.String("bg-color", "", "background color, e.g. #000000")
+			f.String("fg-color", "", "text color, e.g. #ffffff")
+		},
+		run: setBanner,
+	},
+	"export": {
+		usage: "export",
+		help:  "print every published meme",
+		flags: func(f *pflag.FlagSet) {
+			f.String("sort", client.SortNewest, "newest, oldest, most_tagged, most_downloaded or most_shared")
+		},
+		run: export,
+	},
+}
+
+func pageFlags(f *pflag.FlagSet) {
+	f.Int("page", 1, "page to list")
+	f.Int("page-size", 20, "memes per page")
+	f.Bool("all", false, "list every page")
+}
+
+// print writes v as JSON or the rows as a table
+func (e *env) print(v any, header []string, rows [][]string) error {
+	if e.json {
+		return printJSON(e.out, v)
+	}
+	return printTable(e.out, header, rows)
+}
+
+func (e *env) printMemes(memes []api.Meme) error {
+	rows := make([][]string, 0, len(memes))
+	for _, m := range memes {
+		rows = append(rows, []string{
+			m.ID,
+			m.Name,
+			strings.Join(m.Tags, ","),
+			strconv.Itoa(int(m.DownloadCount)),
+			strconv.Itoa(int(m.ShareCount)),
+			m.MediaURL,
+		})
+	}
+	return e.print(memes, []string{"ID", "NAME", "TAGS", "DOWNLOADS", "SHARES", "URL"}, rows)
+}
+
+func login(ctx context.Context, env *env, args []string) error {
+	if err := env.client.Login(ctx); err != nil {
+		return err
+	}
+	path, err := tokenFile()
+	if err != nil {
+		return err
+	}
+	// run saves the new token after the command returns
+	return env.print(map[string]string{"status": "logged in", "token_file": path}, []string{"STATUS", "TOKEN FILE"}, [][]string{{"logged in", path}})
+}
+
+func pending(ctx context.Context, env *env, args []string) error {
+	page, _ := env.flags.GetInt("page")
+	pageSize, _ := env.flags.GetInt("page-size")
+	all, _ := env.flags.GetBool("all")
+	opts := client.PageOptions{Page: page, PageSize: pageSize}
+
+	if !all {
+		resp, err := env.client.Pending(ctx, opts)
+		if err != nil {
+			return err
+		}
+		if env.json {
+			return printJSON(env.out, resp)
+		}
+		return env.printMemes(resp.Memes)
+	}
+	memes := []api.Meme{}
+	for meme, err := range env.client.PendingAll(ctx, opts) {
+		if err != nil {
+			return err
+		}
+		memes = append(memes, meme)
+	}
+	return env.printMemes(memes)
+}
+
+// idResult is the outcome of an action on one meme
+type idResult struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// eachID runs action on every ID argument, it keeps going after a failure and fails at the end
+func eachID(done string, action func(ctx context.Context, c *client.Client, id string) error) func(context.Context, *env, []string) error {
+	return func(ctx context.Context, env *env, args []string) error {
+		if len(args) == 0 {
+			return errors.New("expected at least one meme ID")
+		}
+		results := make([]idResult, 0, len(args))
+		failed := 0
+		for _, id := range args {
+			if err := action(ctx, env.client, id); err != nil {
+				failed++
+				results = append(results, idResult{ID: id, Status: "failed", Error: err.Error()})
+				continue
+			}
+			results = append(results, idResult{ID: id, Status: done})
+		}
+		if err := printResults(env, "ID", results); err != nil {
+			return err
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d failed", failed, len(args))
+		}
+		return nil
+	}
+}
+
+func printResults(env *env, idHeader string, results []idResult) error {
+	rows := make([][]string, 0, len(results))
+	for _, r := range results {
+		rows = append(rows, []string{r.ID, r.Status, r.Error})
+	}
+	return env.print(results, []string{idHeader, "STATUS", "ERROR"}, rows)
+}
+
+func retag(ctx context.Context, env *env, args []string) error {
+	if len(args) != 1 {
+		return errors.New("expected a single meme ID")
+	}
+	add, _ := env.flags.GetStringSlice("add")
+	set, _ := env.flags.GetStringSlice("set")
+	var err error
+	switch {
+	case len(add) > 0 && len(set) > 0:
+		return errors.New("use either --add or --set")
+	case len(add) > 0:
+		err = env.client.AddTags(ctx, args[0], add)
+	case len(set) > 0:
+		err = env.client.UpdateMeme(ctx, args[0], api.PatchRequest{Tags: set}, nil)
+	default:
+		return errors.New("expected --add or --set")
+	}
+	if err != nil {
+		return err
+	}
+	return printResults(env, "ID", []idResult{{ID: args[0], Status: "retagged"}})
+}
+
+// imageExtensions are the formats the API accepts
+var imageExtensions = map[string]bool{".jpg": true, ".jpeg": true, ".png": true, ".gif": true}
+
+// uploadResult is the outcome of uploading one file
+type uploadResult struct {
+	File  string `json:"file"`
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+func upload(ctx context.Context, env *env, args []string) error {
+	if len(args) != 1 {
+		return errors.New("expected the folder to upload")
+	}
+	tags, _ := env.flags.GetStringSlice("tags")
+	if len(tags) == 0 {
+		return errors.New("--tags is required, every meme needs at least one tag")
+	}
+	entries, err := os.ReadDir(args[0])
+	if err != nil {
+		return err
+	}
+	results := []uploadResult{}
+	failed := 0
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || !imageExtensions[ext] {
+			continue
+		}
+		result := uploadResult{File: entry.Name()}
+		meme, err := uploadFile(ctx, env.client, filepath.Join(args[0], entry.Name()), tags)
+		if err != nil {
+			failed++
+			result.Error = err.Error()
+		} else {
+			result.ID = meme.ID
+		}
+		results = append(results, result)
+	}
+
+	rows := make([][]string, 0, len(results))
+	for _, r := range results {
+		rows = append(rows, []string{r.File, r.ID, r.Error})
+	}
+	if err := env.print(results, []string{"FILE", "ID", "ERROR"}, rows); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d uploads failed", failed, len(results))
+	}
+	return nil
+}
+
+// uploadFile uploads the image at path named after the file without its extension
+func uploadFile(ctx context.Context, c *client.Client, path string, tags []string) (*api.Meme, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return c.UploadFile(ctx, name, tags, f)
+}
+
+func setBanner(ctx context.Context, env *env, args []string) error {
+	text, _ := env.flags.GetString("text")
+	bg, _ := env.flags.GetString("bg-color")
+	fg, _ := env.flags.GetString("fg-color")
+	banner := api.Banner{Text: text, BgColor: bg, FgColor: fg}
+	if err := env.client.SetBanner(ctx, banner); err != nil {
+		return err
+	}
+	return env.print(banner, []string{"TEXT", "BACKGROUND", "FOREGROUND"}, [][]string{{text, bg, fg}})
+}
+
+func export(ctx context.Context, env *env, args []string) error {
+	sort, _ := env.flags.GetString("sort")
+	memes := []api.Meme{}
+	for meme, err := range env.client.AdminTimelineAll(ctx, client.TimelineOptions{PageOptions: client.PageOptions{PageSize: 100}, Sort: sort}) {
+		if err != nil {
+			return err
+		}
+		memes = append(memes, meme)
+	}
+	return env.printMemes(memes)
+}
@@ -0,0 +1,212 @@
+// memehubctl moderates and maintains memesHub through the REST API.
+//
+//	memehubctl <command> [flags]
+//
+// The server and admin credentials are read from --server, --user and --password or from
+// MEMEHUB_SERVER, MEMEHUB_USER and MEMEHUB_PASSWORD. `memehubctl login` saves the admin token of
+// the server so the following commands only need the server. Every command prints a table, or
+// JSON with --output json.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+
+	"github.com/BassemHalim/memesHub/pkg/client"
+)
+
+const defaultServer = "http://localhost:8080"
+
+// command is a subcommand, run gets the client and the arguments left after parsing the flags
+type command struct {
+	usage string
+	help  string
+	flags func(*pflag.FlagSet)
+	run   func(ctx context.Context, env *env, args []string) error
+}
+
+// env is what every command runs with
+type env struct {
+	client *client.Client
+	// the flags of the command, parsed
+	flags *pflag.FlagSet
+	out   io.Writer
+	json  bool
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: memehubctl <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, name := range names {
+		fmt.Fprintf(tw, "  %s\t%s\n", commands[name].usage, commands[name].help)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run memehubctl <command> --help for the flags of a command.")
+}
+
+// configDir is the directory of the per user configs, tests point it at a temporary one
+var configDir = os.UserConfigDir
+
+// tokenFile is where login saves the admin tokens, next to the other per user configs. It maps
+// the server URLs to their token so a token is never sent to another server.
+func tokenFile() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "memehubctl", "tokens.json"), nil
+}
+
+// serverKey is the key of server in the token file, trailing slashes don't make another server
+func serverKey(server string) string {
+	return strings.TrimRight(server, "/")
+}
+
+func loadTokens() (map[string]string, error) {
+	path, err := tokenFile()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tokens := map[string]string{}
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("invalid token file %s: %w", path, err)
+	}
+	return tokens, nil
+}
+
+// loadToken returns the token saved for server, if any
+func loadToken(server string) string {
+	tokens, err := loadTokens()
+	if err != nil {
+		return ""
+	}
+	return tokens[serverKey(server)]
+}
+
+func saveToken(server string, token string) error {
+	path, err := tokenFile()
+	if err != nil {
+		return err
+	}
+	tokens, err := loadTokens()
+	if err != nil {
+		tokens = map[string]string{}
+	}
+	tokens[serverKey(server)] = token
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func run(ctx context.Context, args []string, stdout io.Writer) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stdout)
+		return nil
+	}
+	// the banner and cache commands have subcommands, e.g. "banner set"
+	name := args[0]
+	args = args[1:]
+	if _, ok := commands[name]; !ok && len(args) > 0 {
+		name += " " + args[0]
+		args = args[1:]
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		usage(os.Stderr)
+		return fmt.Errorf("unknown command %q", name)
+	}
+
+	flags := pflag.NewFlagSet("memehubctl "+name, pflag.ContinueOnError)
+	server := flags.String("server", envOr("MEMEHUB_SERVER", defaultServer), "memesHub URL")
+	user := flags.String("user", os.Getenv("MEMEHUB_USER"), "admin user")
+	password := flags.String("password", os.Getenv("MEMEHUB_PASSWORD"), "admin password, prefer MEMEHUB_PASSWORD so it isn't in the shell history")
+	output := flags.StringP("output", "o", "table", "output format: table or json")
+	if cmd.flags != nil {
+		cmd.flags(flags)
+	}
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: memehubctl %s\n\n%s\n\nFlags:\n%s", cmd.usage, cmd.help, flags.FlagUsages())
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if *output != "table" && *output != "json" {
+		return fmt.Errorf("unknown output format %q, use table or json", *output)
+	}
+
+	token := loadToken(*server)
+	c := client.New(client.Options{BaseURL: *server, Username: *user, Password: *password, Token: token})
+	err := cmd.run(ctx, &env{client: c, flags: flags, out: stdout, json: *output == "json"}, flags.Args())
+	// keep the token the client logged in with for the next commands
+	if newToken := c.Token(); newToken != "" && newToken != token {
+		if saveErr := saveToken(*server, newToken); saveErr != nil {
+			fmt.Fprintln(os.Stderr, "memehubctl: failed to save the token:", saveErr)
+		}
+	}
+	return err
+}
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	if err := run(ctx, os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "memehubctl:", err)
+		os.Exit(1)
+	}
+}
+
+// printJSON writes v indented, the format scripts parse
+func printJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// printTable writes the rows aligned under header
+func printTable(w io.Writer, header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/BassemHalim/memesHub/pkg/api"
+)
+
+const (
+	testUser     = "admin"
+	testPassword = "secret"
+)
+
+// fakeAPI serves the admin endpoints memehubctl calls and records what they were asked to do
+type fakeAPI struct {
+	*httptest.Server
+	token string
+
+	mu       sync.Mutex
+	approved []string
+	flushed  bool
+	banner   api.Banner
+	// the Authorization headers of every request
+	authorizations []string
+}
+
+func newFakeAPI(t *testing.T, token string) *fakeAPI {
+	f := &fakeAPI{token: token}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/login", func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != testUser || password != testPassword {
+			writeError(w, http.StatusUnauthorized, "Invalid credentials")
+			return
+		}
+		json.NewEncoder(w).Encode(api.LoginResponse{Token: f.token, Role: "admin"})
+	})
+	admin := http.NewServeMux()
+	admin.HandleFunc("GET /api/v1/admin/memes/pending", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(api.MemePage{
+			Memes:      []api.Meme{{ID: "1", Name: "drake", Tags: []string{"funny", "drake"}, MediaURL: "https://imgs.example.com/imgs/1.png"}},
+			TotalCount: 1,
+			Page:       1,
+			TotalPages: 1,
+		})
+	})
+	admin.HandleFunc("PATCH /api/v1/admin/meme/{id}/approve", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") == "missing" {
+			writeError(w, http.StatusNotFound, "Meme not found")
+			return
+		}
+		f.mu.Lock()
+		f.approved = append(f.approved, r.PathValue("id"))
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	})
+	admin.HandleFunc("DELETE /api/v1/admin/cache", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.flushed = true
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	})
+	admin.HandleFunc("PUT /api/v1/admin/banner", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		json.NewDecoder(r.Body).Decode(&f.banner)
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.Handle("/api/v1/admin/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.authorizations = append(f.authorizations, r.Header.Get("Authorization"))
+		f.mu.Unlock()
+		if r.Header.Get("Authorization") != "Bearer "+f.token {
+			writeError(w, http.StatusUnauthorized, "Invalid token")
+			return
+		}
+		admin.ServeHTTP(w, r)
+	}))
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(api.Error{Code: http.StatusText(status), Message: message})
+}
+
+// setup keeps the saved tokens and the MEMEHUB_ variables of the user out of the test
+func setup(t *testing.T) {
+	dir := t.TempDir()
+	previous := configDir
+	configDir = func() (string, error) { return dir, nil }
+	t.Cleanup(func() { configDir = previous })
+	for _, key := range []string{"MEMEHUB_SERVER", "MEMEHUB_USER", "MEMEHUB_PASSWORD"} {
+		t.Setenv(key, "")
+	}
+}
+
+func runArgs(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	err := run(context.Background(), args, &out)
+	return out.String(), err
+}
+
+func TestRunParsesArguments(t *testing.T) {
+	setup(t)
+	srv := newFakeAPI(t, "token")
+
+	out, err := runArgs(t)
+	if err != nil || !strings.Contains(out, "Usage: memehubctl <command> [flags]") {
+		t.Errorf("No arguments should print the usage, got %q, %v", out, err)
+	}
+	if _, err := runArgs(t, "approve", "--help"); err != nil {
+		t.Errorf("--help shouldn't fail, got %v", err)
+	}
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"publish", "1"}, want: `unknown command "publish 1"`},
+		{args: []string{"pending", "--server", srv.URL, "--page", "two"}, want: "invalid argument"},
+		{args: []string{"pending", "--server", srv.URL, "-o", "yaml"}, want: `unknown output format "yaml"`},
+		{args: []string{"approve", "--server", srv.URL}, want: "expected at least one meme ID"},
+		{args: []string{"retag", "1", "--server", srv.URL}, want: "expected --add or --set"},
+	}
+	for _, tt := range tests {
+		if _, err := runArgs(t, tt.args...); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("run(%q) = %v, want an error containing %q", tt.args, err, tt.want)
+		}
+	}
+}
+
+func TestRunEachIDKeepsGoingAfterFailures(t *testing.T) {
+	setup(t)
+	srv := newFakeAPI(t, "token")
+
+	out, err := runArgs(t, "approve", "1", "missing", "2", "--server", srv.URL, "--user", testUser, "--password", testPassword, "-o", "json")
+	if err == nil || err.Error() != "1 of 3 failed" {
+		t.Errorf("Expected 1 of 3 failed, got %v", err)
+	}
+	if strings.Join(srv.approved, ",") != "1,2" {
+		t.Errorf("Expected memes 1 and 2 to be approved, got %v", srv.approved)
+	}
+	var results []idResult
+	if err := json.Unmarshal([]byte(out), &results); err != nil {
+		t.Fatalf("Invalid JSON output %q: %v", out, err)
+	}
+	if len(results) != 3 || results[0].Status != "approved" || results[1].Status != "failed" || results[1].Error == "" || results[2].Status != "approved" {
+		t.Errorf("Unexpected results %+v", results)
+	}
+}
+
+func TestRunSubcommands(t *testing.T) {
+	setup(t)
+	srv := newFakeAPI(t, "token")
+	login := []string{"--server", srv.URL, "--user", testUser, "--password", testPassword}
+
+	if _, err := runArgs(t, append([]string{"cache", "flush"}, login...)...); err != nil {
+		t.Fatal("cache flush failed:", err)
+	}
+	if !srv.flushed {
+		t.Error("cache flush should flush the cache")
+	}
+	if _, err := runArgs(t, append([]string{"banner", "set", "--text", "Ramadan Kareem", "--bg-color", "#000"}, login...)...); err != nil {
+		t.Fatal("banner set failed:", err)
+	}
+	if srv.banner.Text != "Ramadan Kareem" || srv.banner.BgColor != "#000" {
+		t.Errorf("Unexpected banner %+v", srv.banner)
+	}
+	if _, err := runArgs(t, "cache"); err == nil {
+		t.Error("cache without its subcommand should fail")
+	}
+}
+
+func TestRunOutputFormats(t *testing.T) {
+	setup(t)
+	srv := newFakeAPI(t, "token")
+	args := []string{"pending", "--server", srv.URL, "--user", testUser, "--password", testPassword}
+
+	table, err := runArgs(t, args...)
+	if err != nil {
+		t.Fatal("pending failed:", err)
+	}
+	lines := strings.Split(strings.TrimSpace(table), "\n")
+	if len(lines) != 2 || strings.Join(strings.Fields(lines[0]), " ") != "ID NAME TAGS DOWNLOADS SHARES URL" ||
+		strings.Join(strings.Fields(lines[1]), " ") != "1 drake funny,drake 0 0 https://imgs.example.com/imgs/1.png" {
+		t.Errorf("Unexpected table:\n%s", table)
+	}
+
+	out, err := runArgs(t, append(args, "--output", "json")...)
+	if err != nil {
+		t.Fatal("pending failed:", err)
+	}
+	var page api.MemePage
+	if err := json.Unmarshal([]byte(out), &page); err != nil {
+		t.Fatalf("Invalid JSON output %q: %v", out, err)
+	}
+	if len(page.Memes) != 1 || page.Memes[0].Name != "drake" || page.TotalCount != 1 {
+		t.Errorf("Unexpected page %+v", page)
+	}
+}
+
+func TestRunSavesTokenPerServer(t *testing.T) {
+	setup(t)
+	production := newFakeAPI(t, "production-token")
+	staging := newFakeAPI(t, "staging-token")
+
+	if _, err := runArgs(t, "login", "--server", production.URL+"/", "--user", testUser, "--password", testPassword); err != nil {
+		t.Fatal("login failed:", err)
+	}
+	// the saved token is reused without credentials
+	if _, err := runArgs(t, "cache", "flush", "--server", production.URL); err != nil {
+		t.Fatal("The saved token should be reused:", err)
+	}
+
+	// another server never gets the token of production
+	if _, err := runArgs(t, "cache", "flush", "--server", staging.URL); err == nil {
+		t.Error("The staging server should reject the request without a token")
+	}
+	for _, authorization := range staging.authorizations {
+		if strings.Contains(authorization, "production-token") {
+			t.Fatal("The production token was sent to the staging server")
+		}
+	}
+
+	if _, err := runArgs(t, "login", "--server", staging.URL, "--user", testUser, "--password", testPassword); err != nil {
+		t.Fatal("login failed:", err)
+	}
+	if loadToken(production.URL) != "production-token" || loadToken(staging.URL) != "staging-token" {
+		t.Errorf("Expected a token per server, got %q and %q", loadToken(production.URL), loadToken(staging.URL))
+	}
+}
@@ -12,95 +12,132 @@ import (
 
 	"github.com/BassemHalim/memesHub/internal/auth"
 	"github.com/BassemHalim/memesHub/internal/config"
+	"github.com/BassemHalim/memesHub/internal/fetcher"
 	"github.com/BassemHalim/memesHub/internal/fileserver"
+	"github.com/BassemHalim/memesHub/internal/logging"
 	"github.com/BassemHalim/memesHub/internal/middleware"
+	"github.com/BassemHalim/memesHub/internal/notifications"
 	"github.com/BassemHalim/memesHub/internal/server"
+	"github.com/BassemHalim/memesHub/internal/tracing"
 
 	"github.com/patrickmn/go-cache"
+	"github.com/spf13/pflag"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 
 	rateLimiter "github.com/BassemHalim/memesHub/internal/rate-limiter/IP_ratelimiter"
 	"golang.org/x/time/rate"
 )
 
-func run(ctx context.Context) error {
+// how long /readyz fails before the server stops accepting connections, it should be longer than
+// the interval load balancers probe it at
+const drainDelay = 5 * time.Second
+
+// loadConfig parses the command line flags and loads the config from them, the env and config.yaml
+func loadConfig(name string, args []string) (*config.Loader, *config.Config, error) {
+	flags := pflag.NewFlagSet(name, pflag.ContinueOnError)
+	loader := config.NewLoader(flags)
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	cfg, err := loader.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	return loader, cfg, nil
+}
+
+// printConfig implements `memesHub config print [flags]`, it prints the effective config and
+// fails if it is invalid
+func printConfig(args []string) error {
+	loader, cfg, err := loadConfig("memesHub config print", args)
+	if err != nil {
+		return err
+	}
+	if err := loader.Print(os.Stdout); err != nil {
+		return err
+	}
+	return cfg.Validate()
+}
+
+func run(ctx context.Context, args []string) error {
 	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
 	lvl := new(slog.LevelVar)
-	log := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: lvl})).With("Service", "MEME_GATEWAY")
-	cfg, err := config.NewConfig()
+	log := slog.New(logging.NewHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: lvl}))).With("Service", "MEME_GATEWAY")
+	loader, cfg, err := loadConfig("memesHub", args)
 	if err != nil {
-		log.Error("Failed to load config file", "Error", err)
-		panic("Failed to load config")
+		return fmt.Errorf("failed to load config: %w", err)
 	}
-	log.Info("Config", "Domains", cfg.WhitelistedDomains, "Upload File Size", cfg.MaxUploadSize, "PORT", cfg.Port)
-
-	if cfg.LogLevel == -4 {
-		lvl.Set(slog.LevelDebug)
-		log.Debug("-----------------Debugging Mode---------------")
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid config:\n%w", err)
 	}
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ctxFlush, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctxFlush); err != nil {
+			log.Error("Failed to flush the traces", "ERROR", err)
+		}
+	}()
+
+	configStore := config.NewStore(cfg)
+	loader.Watch(configStore, log)
+	log.Info("Config loaded", "CONFIG", loader)
+
+	lvl.Set(cfg.SlogLevel())
+	log.Debug("-----------------Debugging Mode---------------")
 
-	limiter := rateLimiter.NewRateLimiter(rate.Limit(cfg.TokenRate), int(cfg.BurstRate))
+	limiter := rateLimiter.NewRateLimiter(rate.Limit(cfg.TokenRate), int(cfg.BurstRate), log)
 	log.Info("Rate Limiter", "RATE", cfg.TokenRate, "BURST", cfg.BurstRate)
 
 	c := cache.New(2*time.Hour, 2*time.Hour) // TODO: make these configurable
 
-	gateway, err := server.New(cfg, limiter, log, &http.Client{Transport: &http.Transport{ForceAttemptHTTP2: true},
-		Timeout: 1 * time.Second}, c)
+	memeFetcher := fetcher.New(fetcher.Options{
+		AllowedDomains: cfg.WhitelistedDomains,
+		MaxBytes:       cfg.MaxUploadSize,
+		Timeout:        5 * time.Second,
+	})
+
+	// apply config.yaml changes to the running components
+	configStore.Subscribe(func(cfg *config.Config) {
+		lvl.Set(cfg.SlogLevel())
+		limiter.SetLimits(rate.Limit(cfg.TokenRate), int(cfg.BurstRate))
+		memeFetcher.SetLimits(cfg.WhitelistedDomains, cfg.MaxUploadSize)
+	})
+
+	gateway, err := server.New(configStore, limiter, log, memeFetcher, c)
 	if err != nil {
 		log.Error("failed to create server", "ERROR", err)
 		return err
 	}
+	defer gateway.Close()
 
-	getTimelineHandler := http.HandlerFunc(gateway.GetTimeline)
-	searchMemesHandler := http.HandlerFunc(gateway.SearchMemes)
-	searchTagsHandler := http.HandlerFunc(gateway.SearchTags)
-	getMemeHandler := http.HandlerFunc(gateway.GetMeme)
-	uploadMemeHandler := http.HandlerFunc(gateway.UploadMeme)
-	deleteMemeHandler := http.HandlerFunc(gateway.DeleteMeme)
-	updateTagsHandler := http.HandlerFunc(gateway.UpdateTags)
-	patchMemeHandler := http.HandlerFunc(gateway.PatchMeme)
-	flushCache := http.HandlerFunc(gateway.FlushCache)
-	trackDownloadHandler := http.HandlerFunc(gateway.TrackDownload)
-	trackShareHandler := http.HandlerFunc(gateway.TrackShare)
-	getPendingMemesHandler := http.HandlerFunc(gateway.GetPendingMemes)
-	approveMemeHandler := http.HandlerFunc(gateway.ApproveMeme)
-	getBannerHandler := http.HandlerFunc(gateway.GetBanner)
-	updateBannerHandler := http.HandlerFunc(gateway.UpdateBanner)
-
-	apiRouter := http.NewServeMux()
-	apiRouter.HandleFunc("/login", auth.Login)
-	apiRouter.Handle("GET /memes", middleware.GzipMiddleware(middleware.Cache(limiter.RateLimit(getTimelineHandler), 60)))
-	apiRouter.Handle("GET /memes/search", middleware.GzipMiddleware(middleware.Cache(limiter.RateLimit(searchMemesHandler), 2*60)))
-	apiRouter.Handle("GET /tags/search", middleware.GzipMiddleware(middleware.Cache(limiter.RateLimit(searchTagsHandler), 2*60)))
-	apiRouter.Handle("GET /meme/{id}", middleware.Cache(limiter.RateLimit(getMemeHandler), 24*60))
-	apiRouter.Handle("POST /meme", limiter.RateLimit(uploadMemeHandler))
-	apiRouter.Handle("POST /memes/{id}/download", middleware.ValidateBrowserRequest(cfg.ApplicationDomains)(limiter.RateLimit(trackDownloadHandler)))
-	apiRouter.Handle("POST /memes/{id}/share", middleware.ValidateBrowserRequest(cfg.ApplicationDomains)(limiter.RateLimit(trackShareHandler)))
-	apiRouter.Handle("GET /banner", getBannerHandler)
-
-	adminRouter := http.NewServeMux()
-	adminRouter.Handle("DELETE /meme/{id}", limiter.RateLimit(middleware.Auth(deleteMemeHandler)))
-	adminRouter.Handle("PATCH /meme/{id}/tags", limiter.RateLimit(middleware.Auth(updateTagsHandler)))
-	adminRouter.Handle("PATCH /meme/{id}", limiter.RateLimit(middleware.Auth(patchMemeHandler)))
-	adminRouter.Handle("GET /memes", middleware.GzipMiddleware(middleware.Auth(getTimelineHandler))) // same as /api/memes but without caching or rate limiting
-	adminRouter.Handle("DELETE /cache", middleware.Auth(flushCache))
-	adminRouter.Handle("GET /memes/pending", middleware.Auth(getPendingMemesHandler))
-	adminRouter.Handle("PATCH /meme/{id}/approve", middleware.Auth(approveMemeHandler))
-	adminRouter.Handle("PUT /banner", middleware.Auth(updateBannerHandler))
-
-	apiRouter.Handle("/admin/", http.StripPrefix("/admin", adminRouter))
+	notifications.Configure(cfg.Notifications.URL, cfg.Notifications.TelegramChatID)
 
+	routerOptions := server.RouterOptions{
+		Admin:              auth.New(cfg.Auth.JWTSecret, cfg.Auth.AdminUser, cfg.Auth.AdminPassHash, log),
+		JWTSecret:          cfg.Auth.JWTSecret,
+		ApplicationDomains: cfg.ApplicationDomains,
+	}
 	fileServer, err := fileserver.New(log)
 	if err != nil {
 		log.Error("Failed to create file server", "ERROR", err)
+	} else {
+		routerOptions.Media = http.HandlerFunc(fileServer.Handler)
 	}
-	serveMedia := http.HandlerFunc(fileServer.Handler)
-	mainRouter := http.NewServeMux()
-	mainRouter.Handle("/imgs/", limiter.RateLimit(serveMedia))
-	mainRouter.Handle("/api/", http.StripPrefix("/api", apiRouter))
+	mainRouter := gateway.Router(routerOptions)
 
-	corsRouter := middleware.CORS(mainRouter)
+	// permanently delete memes that have been in the trash for longer than the retention period
+	go gateway.PurgeTrash(ctx, 24*time.Hour, cfg.TrashRetentionDays)
+	// retry image moves that failed after their transaction committed
+	go gateway.RunStorageReconciler(ctx, 5*time.Minute)
+
+	// continue the caller's trace from the traceparent header and start a span per request
+	corsRouter := otelhttp.NewHandler(middleware.AccessLog(log)(middleware.Metrics(middleware.CORS(mainRouter))), "memesHub")
 	// Start server
 	log.Info("Starting server", "PORT", cfg.Port)
 	server := &http.Server{
@@ -116,6 +153,10 @@ func run(ctx context.Context) error {
 	for {
 		select {
 		case <-ctx.Done():
+			log.Info("Draining before shutdown", "Delay", drainDelay)
+			// fail readiness and give load balancers time to notice before refusing connections
+			gateway.Drain()
+			time.Sleep(drainDelay)
 			log.Info("Shutting down server...")
 			ctxShutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 			defer cancel()
@@ -131,8 +172,15 @@ func run(ctx context.Context) error {
 }
 
 func main() {
+	if len(os.Args) > 2 && os.Args[1] == "config" && os.Args[2] == "print" {
+		if err := printConfig(os.Args[3:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 	ctx := context.Background()
-	if err := run(ctx); err != nil {
+	if err := run(ctx, os.Args[1:]); err != nil {
 		slog.Error("Failed to start server", "ERROR", err)
 		os.Exit(1)
 	}
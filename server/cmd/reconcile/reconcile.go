@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/BassemHalim/memesHub/internal/config"
+	"github.com/BassemHalim/memesHub/internal/db"
+	"github.com/BassemHalim/memesHub/internal/reconcile"
+	"github.com/BassemHalim/memesHub/internal/storage"
+	"github.com/spf13/pflag"
+)
+
+// reconcile compares the meme table with the images in the R2 bucket and reports
+// (or with --dry-run=false fixes) images without a meme and memes without an image
+func main() {
+	flags := pflag.NewFlagSet("reconcile", pflag.ExitOnError)
+	dryRun := flags.Bool("dry-run", true, "only print the diff without fixing anything")
+	gracePeriod := flags.Duration("grace-period", time.Hour, "ignore unreferenced images and memes missing their image younger than this")
+	loader := config.NewLoader(flags)
+	flags.Parse(os.Args[1:])
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	log := slog.New(slog.NewJSONHandler(os.Stderr, nil)).With("Service", "RECONCILE")
+	cfg, err := loader.Load()
+	if err != nil {
+		log.Error("Failed to load config", "ERROR", err)
+		os.Exit(1)
+	}
+	// only the database and storage are used
+	if err := errors.Join(cfg.Database.Validate(), cfg.Storage.Validate()); err != nil {
+		log.Error("Invalid config", "ERROR", err)
+		os.Exit(1)
+	}
+	database, err := db.New(cfg.Database)
+	if err != nil {
+		log.Error("Failed to connect to the database", "ERROR", err)
+		os.Exit(1)
+	}
+	defer database.Close()
+	store := storage.NewR2(storage.R2Options{
+		Bucket:          cfg.Storage.R2Bucket,
+		AccountID:       cfg.Storage.R2AccountID,
+		AccessKeyID:     cfg.Storage.R2AccessKeyID,
+		AccessKeySecret: cfg.Storage.R2AccessKeySecret,
+		BaseURL:         cfg.Storage.BaseURL,
+	}, log)
+
+	reconciler := reconcile.New(database, store, log, *gracePeriod)
+	report, err := reconciler.Diff(ctx)
+	if err != nil {
+		log.Error("Failed to diff the database and storage", "ERROR", err)
+		os.Exit(1)
+	}
+	report.Print(os.Stdout)
+	if *dryRun {
+		return
+	}
+	if failed := reconciler.Fix(ctx, report); failed > 0 {
+		log.Error("Some problems could not be fixed", "Failed", failed)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,133 @@
+// Package apierror writes the JSON error responses of the HTTP API. Every error has the same
+// envelope:
+//
+//	{"code": "NOT_FOUND", "message": "Meme not found", "request_id": "...", "details": {...}}
+//
+// code is the name of the gRPC status code matching the HTTP status so clients can branch on it
+// without parsing the message.
+package apierror
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/BassemHalim/memesHub/internal/logging"
+	"github.com/BassemHalim/memesHub/pkg/api"
+)
+
+// Response is the error envelope, its JSON shape is part of the public API
+type Response = api.Error
+
+// HTTPStatus returns the HTTP status of a gRPC status code
+func HTTPStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.FailedPrecondition:
+		return http.StatusConflict
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Canceled:
+		// the client closed the request, nginx's non standard status
+		return 499
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Code returns the gRPC status code closest to an HTTP status
+func Code(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusRequestEntityTooLarge:
+		return codes.OutOfRange
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	default:
+		if httpStatus >= 400 && httpStatus < 500 {
+			return codes.InvalidArgument
+		}
+		return codes.Internal
+	}
+}
+
+// codeName turns codes.NotFound into "NOT_FOUND"
+func codeName(code codes.Code) string {
+	name := code.String()
+	var b strings.Builder
+	for i, c := range name {
+		if i > 0 && c >= 'A' && c <= 'Z' && name[i-1] >= 'a' && name[i-1] <= 'z' {
+			b.WriteByte('_')
+		}
+		b.WriteRune(c)
+	}
+	return strings.ToUpper(b.String())
+}
+
+// Write responds with httpStatus and the error envelope
+func Write(w http.ResponseWriter, r *http.Request, httpStatus int, message string, details any) {
+	write(w, r, httpStatus, Code(httpStatus), message, details)
+}
+
+// FromStatus responds with the HTTP status matching the gRPC status of err. The message of client
+// errors is the one set by the service, server errors use fallback so internals aren't leaked.
+func FromStatus(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	st, ok := status.FromError(err)
+	if !ok {
+		// context errors become Canceled or DeadlineExceeded, anything else is Unknown
+		st = status.FromContextError(err)
+	}
+	httpStatus := HTTPStatus(st.Code())
+	message := st.Message()
+	if httpStatus >= http.StatusInternalServerError || message == "" {
+		message = fallback
+	}
+	write(w, r, httpStatus, st.Code(), message, nil)
+}
+
+func write(w http.ResponseWriter, r *http.Request, httpStatus int, code codes.Code, message string, details any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(httpStatus)
+	json.NewEncoder(w).Encode(Response{
+		Code:      codeName(code),
+		Message:   message,
+		RequestID: logging.RequestID(r.Context()),
+		Details:   details,
+	})
+}
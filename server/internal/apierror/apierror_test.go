@@ -0,0 +1,91 @@
+package apierror
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/BassemHalim/memesHub/internal/logging"
+)
+
+func decode(t *testing.T, w *httptest.ResponseRecorder) Response {
+	t.Helper()
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected Content-Type application/json, got %q", ct)
+	}
+	var resp Response
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode the error: %v", err)
+	}
+	return resp
+}
+
+func TestWrite(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/meme/1", nil)
+	r = r.WithContext(logging.WithRequestID(r.Context(), "req-1"))
+	w := httptest.NewRecorder()
+
+	Write(w, r, http.StatusBadRequest, "Bad ID", map[string]string{"id": "not a UUID"})
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+	resp := decode(t, w)
+	if resp.Code != "INVALID_ARGUMENT" || resp.Message != "Bad ID" || resp.RequestID != "req-1" {
+		t.Errorf("Unexpected envelope %+v", resp)
+	}
+	if details, ok := resp.Details.(map[string]any); !ok || details["id"] != "not a UUID" {
+		t.Errorf("Expected the details to be kept, got %v", resp.Details)
+	}
+}
+
+func TestFromStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		code    string
+		message string
+	}{
+		{"not found", status.Error(codes.NotFound, "Meme not found"), http.StatusNotFound, "NOT_FOUND", "Meme not found"},
+		{"invalid argument", status.Error(codes.InvalidArgument, "Invalid meme ID format"), http.StatusBadRequest, "INVALID_ARGUMENT", "Invalid meme ID format"},
+		{"failed precondition", status.Error(codes.FailedPrecondition, "Not uploaded"), http.StatusConflict, "FAILED_PRECONDITION", "Not uploaded"},
+		// server errors don't leak the service message
+		{"internal", status.Error(codes.Internal, "pq: relation missing"), http.StatusInternalServerError, "INTERNAL", "fallback"},
+		{"deadline", context.DeadlineExceeded, http.StatusGatewayTimeout, "DEADLINE_EXCEEDED", "fallback"},
+		{"plain error", errors.New("boom"), http.StatusInternalServerError, "UNKNOWN", "fallback"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+
+			FromStatus(w, r, tt.err, "fallback")
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+			resp := decode(t, w)
+			if resp.Code != tt.code || resp.Message != tt.message {
+				t.Errorf("Expected %s %q, got %s %q", tt.code, tt.message, resp.Code, resp.Message)
+			}
+			if resp.RequestID != "" {
+				t.Errorf("Requests without an ID shouldn't have one in the error, got %q", resp.RequestID)
+			}
+		})
+	}
+}
+
+func TestCodeRoundTrip(t *testing.T) {
+	for _, code := range []codes.Code{codes.InvalidArgument, codes.NotFound, codes.Unauthenticated, codes.PermissionDenied, codes.ResourceExhausted, codes.Unavailable} {
+		if got := Code(HTTPStatus(code)); got != code {
+			t.Errorf("%s maps to %d which maps back to %s", code, HTTPStatus(code), got)
+		}
+	}
+}
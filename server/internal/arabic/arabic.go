@@ -0,0 +1,46 @@
+// Package arabic normalizes the spellings of Arabic text that readers consider the same word, so
+// tags and searches match however they were typed. The normalize_arabic SQL function of
+// migrations/012_arabic_normalization.sql must fold text exactly like Normalize, the tags are
+// normalized in Go when they are saved and the searches in SQL.
+package arabic
+
+import "strings"
+
+// folds maps the letters written in several ways to the plain letter
+var folds = map[rune]rune{
+	'\u0623': '\u0627', // alef with hamza above to alef
+	'\u0625': '\u0627', // alef with hamza below
+	'\u0622': '\u0627', // alef with madda above
+	'\u0671': '\u0627', // alef wasla
+	'\u0629': '\u0647', // taa marbuta to haa
+	'\u0649': '\u064A', // alef maqsura to yaa
+}
+
+// isMark reports whether r is written over or under a letter, or stretches it, without changing
+// the word
+func isMark(r rune) bool {
+	switch {
+	case r >= '\u0610' && r <= '\u061A': // honorifics and Quranic marks
+	case r >= '\u064B' && r <= '\u065F': // tashkeel: harakat, tanween, shadda, sukun...
+	case r == '\u0640': // tatweel
+	case r == '\u0670': // superscript alef
+	case r >= '\u06D6' && r <= '\u06ED': // Quranic annotations
+	default:
+		return false
+	}
+	return true
+}
+
+// Normalize removes the diacritics and tatweel of s and folds the alef variants to alef, taa
+// marbuta to haa and alef maqsura to yaa. Text without Arabic letters is returned as is.
+func Normalize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if isMark(r) {
+			return -1
+		}
+		if folded, ok := folds[r]; ok {
+			return folded
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,99 @@
+package arabic
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/lib/pq"
+)
+
+// matching are spellings of the same word that must normalize to the same text
+var matching = []struct {
+	name string
+	a, b string
+}{
+	{name: "alef with hamza above", a: "أحمد", b: "احمد"},
+	{name: "alef with hamza below", a: "إسلام", b: "اسلام"},
+	{name: "alef with madda", a: "آمال", b: "امال"},
+	{name: "alef wasla", a: "ٱلله", b: "الله"},
+	{name: "hamza variants", a: "أإآ", b: "إآأ"},
+	{name: "taa marbuta", a: "مدرسة", b: "مدرسه"},
+	{name: "alef maqsura", a: "مصطفى", b: "مصطفي"},
+	{name: "tashkeel", a: "ضَحِكٌ", b: "ضحك"},
+	{name: "shadda and sukun", a: "محمّد عْ", b: "محمد ع"},
+	{name: "tanween", a: "شكراً", b: "شكرا"},
+	{name: "superscript alef", a: "هٰذا", b: "هذا"},
+	{name: "tatweel", a: "ضـــحك", b: "ضحك"},
+	{name: "everything", a: "الـقِطَّة الْمُضْحِكَة", b: "القطه المضحكه"},
+	{name: "mixed with latin", a: "ميم قطة cat", b: "ميم قطه cat"},
+}
+
+// distinct are different words that must stay apart
+var distinct = []struct {
+	name string
+	a, b string
+}{
+	{name: "different letters", a: "ضحك", b: "صحك"},
+	{name: "waw with hamza isn't folded", a: "مؤمن", b: "مومن"},
+	{name: "yaa with hamza isn't folded", a: "سئل", b: "سيل"},
+	{name: "latin case is kept", a: "Cat", b: "cat"},
+	{name: "spaces are kept", a: "ضحك كثير", b: "ضحككثير"},
+}
+
+func TestNormalize(t *testing.T) {
+	for _, tt := range matching {
+		t.Run(tt.name, func(t *testing.T) {
+			if a, b := Normalize(tt.a), Normalize(tt.b); a != b {
+				t.Errorf("%q and %q should match, normalized to %q and %q", tt.a, tt.b, a, b)
+			}
+		})
+	}
+	for _, tt := range distinct {
+		t.Run(tt.name, func(t *testing.T) {
+			if a := Normalize(tt.a); a == Normalize(tt.b) {
+				t.Errorf("%q and %q should not match, both normalized to %q", tt.a, tt.b, a)
+			}
+		})
+	}
+}
+
+func TestNormalizeIsIdempotent(t *testing.T) {
+	for _, tt := range matching {
+		once := Normalize(tt.a)
+		if twice := Normalize(once); twice != once {
+			t.Errorf("Normalize(%q) = %q, normalized again %q", tt.a, once, twice)
+		}
+	}
+}
+
+// The tags are normalized in Go and the searches in SQL so both must agree. It needs a database
+// with the migrations applied, like the one of postgres.Dockerfile.
+func TestNormalizeMatchesSQL(t *testing.T) {
+	url := os.Getenv("MEMEHUB_TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("MEMEHUB_TEST_DATABASE_URL must be set to test normalize_arabic against Postgres")
+	}
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var texts []string
+	for _, tt := range matching {
+		texts = append(texts, tt.a, tt.b)
+	}
+	for _, tt := range distinct {
+		texts = append(texts, tt.a, tt.b)
+	}
+	for _, text := range texts {
+		var normalized string
+		if err := db.QueryRow("SELECT normalize_arabic($1)", text).Scan(&normalized); err != nil {
+			t.Fatal(err)
+		}
+		if want := Normalize(text); normalized != want {
+			t.Errorf("normalize_arabic(%q) = %q, Normalize returns %q", text, normalized, want)
+		}
+	}
+}
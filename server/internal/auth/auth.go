@@ -2,12 +2,16 @@ package auth
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
-	"os"
 
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
+
+	"github.com/BassemHalim/memesHub/internal/apierror"
+	"github.com/BassemHalim/memesHub/pkg/api"
 )
 
 func hashedPassword() {
@@ -19,71 +23,103 @@ func hashedPassword() {
 	fmt.Println(string(hashedPassword)) // Store this in ADMIN_PASS_HASH
 }
 
-func GenerateAdminJWT(username string) (string, error) {
-	jwtSecret, ok := os.LookupEnv("JWT_SECRET")
-	if !ok {
-		return "", fmt.Errorf("JWT_SECRET not set")
+// Admin logs in the admin account and issues its tokens
+type Admin struct {
+	jwtSecret string
+	user      string
+	passHash  string
+	log       *slog.Logger
+}
+
+// New returns the admin account, login is disabled when user or passHash is empty
+func New(jwtSecret string, user string, passHash string, log *slog.Logger) *Admin {
+	return &Admin{jwtSecret: jwtSecret, user: user, passHash: passHash, log: log}
+}
+
+func (a *Admin) GenerateAdminJWT(username string) (string, error) {
+	if a.jwtSecret == "" {
+		return "", fmt.Errorf("the JWT secret is not set")
 	}
 	claims := jwt.MapClaims{
 		"username": username,
 		"role":     "admin",
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(jwtSecret))
+	tokenString, err := token.SignedString([]byte(a.jwtSecret))
 	if err != nil {
 		return "", fmt.Errorf("failed to sign token: %v", err)
 	}
 	return tokenString, nil
 }
 
-func Login(rw http.ResponseWriter, r *http.Request) {
+// ErrNotAdmin is returned for valid tokens that weren't issued to the admin
+var ErrNotAdmin = errors.New("not an admin token")
+
+// ValidateAdminToken checks that tokenString is an admin token signed with jwtSecret
+func ValidateAdminToken(jwtSecret string, tokenString string) error {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// Don't forget to validate the alg is what you expect:
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(jwtSecret), nil
+	})
+	if err != nil {
+		return err
+	}
+	if !token.Valid {
+		return errors.New("invalid token")
+	}
+	if claims, ok := token.Claims.(jwt.MapClaims); ok && claims["role"] != "admin" {
+		return ErrNotAdmin
+	}
+	return nil
+}
+
+func (a *Admin) Login(rw http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(rw, "Method not allowed", http.StatusMethodNotAllowed)
+		apierror.Write(rw, r, http.StatusMethodNotAllowed, "Method not allowed", nil)
 		return
 	}
 	rw.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
 
 	username, password, ok := r.BasicAuth()
 	if !ok {
-		http.Error(rw, "Unauthorized", http.StatusUnauthorized)
+		apierror.Write(rw, r, http.StatusUnauthorized, "Unauthorized", nil)
 		return
 	}
 	if username == "" || password == "" {
-		http.Error(rw, "Unauthorized", http.StatusUnauthorized)
+		apierror.Write(rw, r, http.StatusUnauthorized, "Unauthorized", nil)
 		return
 	}
-	adminUser, ok := os.LookupEnv("ADMIN_USER")
-	if !ok {
-		fmt.Println("ADMIN_USER not set")
-		http.Error(rw, "Internal Server Error", http.StatusInternalServerError)
-		return
-	}
-	hashedAdminPass, ok := os.LookupEnv("ADMIN_PASS_HASH")
-	if !ok {
-		fmt.Println("ADMIN_PASS_HASH not set")
-		http.Error(rw, "Internal Server Error", http.StatusInternalServerError)
+	if a.user == "" || a.passHash == "" {
+		a.log.ErrorContext(r.Context(), "The admin user is not configured")
+		apierror.Write(rw, r, http.StatusInternalServerError, "Internal Server Error", nil)
 		return
 	}
 
-	if username != adminUser {
-		http.Error(rw, "Unauthorized", http.StatusUnauthorized)
+	if username != a.user {
+		a.log.WarnContext(r.Context(), "Failed admin login", "Reason", "unknown user")
+		apierror.Write(rw, r, http.StatusUnauthorized, "Unauthorized", nil)
 		return
 	}
-	if err := bcrypt.CompareHashAndPassword([]byte(hashedAdminPass), []byte(password)); err != nil {
-		http.Error(rw, "Unauthorized", http.StatusUnauthorized)
+	if err := bcrypt.CompareHashAndPassword([]byte(a.passHash), []byte(password)); err != nil {
+		a.log.WarnContext(r.Context(), "Failed admin login", "Reason", "wrong password")
+		apierror.Write(rw, r, http.StatusUnauthorized, "Unauthorized", nil)
 		return
 	}
-	tokenString, err := GenerateAdminJWT(username)
+	tokenString, err := a.GenerateAdminJWT(username)
 	if err != nil {
-		http.Error(rw, "Internal Server Error", http.StatusInternalServerError)
+		a.log.ErrorContext(r.Context(), "Failed to issue the admin token", "ERROR", err)
+		apierror.Write(rw, r, http.StatusInternalServerError, "Internal Server Error", nil)
 		return
 	}
-	resp, err := json.Marshal(map[string]string{
-		"token": tokenString,
-		"role":  "admin",
+	resp, err := json.Marshal(api.LoginResponse{
+		Token: tokenString,
+		Role:  "admin",
 	})
 	if err != nil {
-		http.Error(rw, "Internal Server Error", http.StatusInternalServerError)
+		apierror.Write(rw, r, http.StatusInternalServerError, "Internal Server Error", nil)
 		return
 	}
 
@@ -0,0 +1,254 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+)
+
+// Config is an immutable snapshot of the configuration, changes to config.yaml publish a new one
+// through the Store. WhitelistedDomains, MaxUploadSize, TokenRate, BurstRate and LogLevel are
+// applied while running, the other settings need a restart.
+type Config struct {
+	WhitelistedDomains []string `json:"whitelisted_domains"`
+	ApplicationDomains []string `json:"application_domains"`
+	MaxUploadSize      int64    `json:"max_upload_size"`
+	Port               int32    `json:"port"`
+	TokenRate          int32    `json:"rate_limit"`
+	BurstRate          int32    `json:"burst_rate"`
+	LogLevel           int8     `json:"log_level"`
+	TrashRetentionDays int32    `json:"trash_retention_days"`
+
+	Database      DatabaseConfig      `json:"database"`
+	Storage       StorageConfig       `json:"storage"`
+	Auth          AuthConfig          `json:"auth"`
+	Notifications NotificationsConfig `json:"notifications"`
+	Tracing       TracingConfig       `json:"tracing"`
+	GRPC          GRPCConfig          `json:"grpc"`
+	Ranking       RankingConfig       `json:"ranking"`
+}
+
+type DatabaseConfig struct {
+	Host     string `json:"host"`
+	Port     int32  `json:"port"`
+	User     string `json:"user"`
+	Password string `json:"password"`
+	Name     string `json:"name"`
+}
+
+type StorageConfig struct {
+	// public URL the images are served from
+	BaseURL           string `json:"base_url"`
+	R2Bucket          string `json:"r2_bucket"`
+	R2AccountID       string `json:"r2_account_id"`
+	R2AccessKeyID     string `json:"r2_access_key_id"`
+	R2AccessKeySecret string `json:"r2_access_key_secret"`
+	// signs the upload URLs of local storage, a random key is used when empty
+	UploadSigningKey string `json:"upload_signing_key"`
+}
+
+type AuthConfig struct {
+	JWTSecret     string `json:"jwt_secret"`
+	AdminUser     string `json:"admin_user"`
+	AdminPassHash string `json:"admin_pass_hash"`
+}
+
+type NotificationsConfig struct {
+	// new memes are posted to this endpoint, notifications are disabled when empty
+	URL            string `json:"url"`
+	TelegramChatID int64  `json:"telegram_chat_id"`
+}
+
+type TracingConfig struct {
+	// none, stdout or otlp
+	Exporter string `json:"exporter"`
+	// OTLP/HTTP endpoint like http://localhost:4318, the path defaults to /v1/traces
+	OTLPEndpoint string `json:"otlp_endpoint"`
+	// share of the traces started by the gateway that are recorded, between 0 and 1
+	SampleRatio float64 `json:"sample_ratio"`
+}
+
+type GRPCConfig struct {
+	// the gateway calls the meme service at this address over gRPC when set, otherwise it runs
+	// the meme service in-process
+	MemeServiceAddr string `json:"meme_service_addr"`
+	// address the standalone meme service listens on
+	ListenAddr string `json:"listen_addr"`
+	// certificate and key of the meme service, it serves plaintext gRPC when they are empty
+	TLSCertFile string `json:"tls_cert_file"`
+	TLSKeyFile  string `json:"tls_key_file"`
+	// CA the gateway verifies the meme service certificate with, it connects in plaintext when empty
+	TLSCAFile string `json:"tls_ca_file"`
+	// shared secret the gateway authenticates to the meme service with
+	ServiceToken string `json:"service_token"`
+	// address the standalone meme service serves /metrics on
+	MetricsAddr string `json:"metrics_addr"`
+}
+
+// RankingConfig weighs what makes a search result relevant, the score of a meme is the sum of
+// its text rank, the logarithms of its download and share counts and its recency, which halves
+// every RecencyHalfLifeDays, each multiplied by their weight
+type RankingConfig struct {
+	TextWeight          float64 `json:"text_weight"`
+	DownloadWeight      float64 `json:"download_weight"`
+	ShareWeight         float64 `json:"share_weight"`
+	RecencyWeight       float64 `json:"recency_weight"`
+	RecencyHalfLifeDays float64 `json:"recency_half_life_days"`
+}
+
+// Validate returns every invalid setting at once
+func (c *Config) Validate() error {
+	var errs []error
+	if len(c.WhitelistedDomains) == 0 {
+		errs = append(errs, errors.New("whitelisted_domains must not be empty"))
+	}
+	for _, domain := range c.WhitelistedDomains {
+		if domain == "" {
+			errs = append(errs, errors.New("whitelisted_domains must not contain empty domains"))
+			break
+		}
+	}
+	if c.MaxUploadSize <= 0 {
+		errs = append(errs, fmt.Errorf("max_upload_size must be positive, got %d", c.MaxUploadSize))
+	}
+	if c.Port <= 0 || c.Port > 65535 {
+		errs = append(errs, fmt.Errorf("port must be between 1 and 65535, got %d", c.Port))
+	}
+	if c.TokenRate <= 0 {
+		errs = append(errs, fmt.Errorf("rate_limit must be positive, got %d", c.TokenRate))
+	}
+	if c.BurstRate <= 0 {
+		errs = append(errs, fmt.Errorf("burst_rate must be positive, got %d", c.BurstRate))
+	}
+	switch slog.Level(c.LogLevel) {
+	case slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError:
+	default:
+		errs = append(errs, fmt.Errorf("log_level must be one of -4 (debug), 0 (info), 4 (warn) or 8 (error), got %d", c.LogLevel))
+	}
+	if c.TrashRetentionDays <= 0 {
+		errs = append(errs, fmt.Errorf("trash_retention_days must be positive, got %d", c.TrashRetentionDays))
+	}
+	// the database and storage are only used by the in-process meme service
+	if c.GRPC.MemeServiceAddr == "" {
+		errs = append(errs, c.Database.Validate(), c.Storage.Validate())
+	}
+	errs = append(errs, c.Auth.Validate(), c.Notifications.Validate(), c.Tracing.Validate(), c.GRPC.Validate(), c.Ranking.Validate())
+	return errors.Join(errs...)
+}
+
+func (c *DatabaseConfig) Validate() error {
+	var errs []error
+	if c.Host == "" {
+		errs = append(errs, errors.New("database.host must be set"))
+	}
+	if c.Port <= 0 || c.Port > 65535 {
+		errs = append(errs, fmt.Errorf("database.port must be between 1 and 65535, got %d", c.Port))
+	}
+	if c.User == "" {
+		errs = append(errs, errors.New("database.user must be set"))
+	}
+	if c.Name == "" {
+		errs = append(errs, errors.New("database.name must be set"))
+	}
+	return errors.Join(errs...)
+}
+
+func (c *StorageConfig) Validate() error {
+	var errs []error
+	required := []struct{ key, value string }{
+		{"storage.base_url", c.BaseURL},
+		{"storage.r2_bucket", c.R2Bucket},
+		{"storage.r2_account_id", c.R2AccountID},
+		{"storage.r2_access_key_id", c.R2AccessKeyID},
+		{"storage.r2_access_key_secret", c.R2AccessKeySecret},
+	}
+	for _, setting := range required {
+		if setting.value == "" {
+			errs = append(errs, fmt.Errorf("%s must be set", setting.key))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *AuthConfig) Validate() error {
+	var errs []error
+	if c.JWTSecret == "" {
+		errs = append(errs, errors.New("auth.jwt_secret must be set"))
+	}
+	if (c.AdminUser == "") != (c.AdminPassHash == "") {
+		errs = append(errs, errors.New("auth.admin_user and auth.admin_pass_hash must be set together"))
+	}
+	return errors.Join(errs...)
+}
+
+func (c *NotificationsConfig) Validate() error {
+	if c.URL == "" {
+		return nil
+	}
+	var errs []error
+	if u, err := url.Parse(c.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("notifications.url must be an http(s) URL, got %q", c.URL))
+	}
+	if c.TelegramChatID == 0 {
+		errs = append(errs, errors.New("notifications.telegram_chat_id must be set when notifications.url is"))
+	}
+	return errors.Join(errs...)
+}
+
+func (c *TracingConfig) Validate() error {
+	var errs []error
+	switch c.Exporter {
+	case "none", "stdout":
+	case "otlp":
+		if u, err := url.Parse(c.OTLPEndpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("tracing.otlp_endpoint must be an http(s) URL when tracing.exporter is otlp, got %q", c.OTLPEndpoint))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("tracing.exporter must be one of none, stdout or otlp, got %q", c.Exporter))
+	}
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("tracing.sample_ratio must be between 0 and 1, got %g", c.SampleRatio))
+	}
+	return errors.Join(errs...)
+}
+
+func (c *GRPCConfig) Validate() error {
+	var errs []error
+	if c.ListenAddr == "" {
+		errs = append(errs, errors.New("grpc.listen_addr must be set"))
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		errs = append(errs, errors.New("grpc.tls_cert_file and grpc.tls_key_file must be set together"))
+	}
+	if c.MemeServiceAddr != "" && c.ServiceToken == "" {
+		errs = append(errs, errors.New("grpc.service_token must be set when grpc.meme_service_addr is"))
+	}
+	return errors.Join(errs...)
+}
+
+func (c *RankingConfig) Validate() error {
+	var errs []error
+	weights := []struct {
+		key    string
+		weight float64
+	}{
+		{"ranking.text_weight", c.TextWeight},
+		{"ranking.download_weight", c.DownloadWeight},
+		{"ranking.share_weight", c.ShareWeight},
+		{"ranking.recency_weight", c.RecencyWeight},
+	}
+	for _, w := range weights {
+		if w.weight < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative, got %g", w.key, w.weight))
+		}
+	}
+	if c.RecencyHalfLifeDays <= 0 {
+		errs = append(errs, fmt.Errorf("ranking.recency_half_life_days must be positive, got %g", c.RecencyHalfLifeDays))
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Config) SlogLevel() slog.Level {
+	return slog.Level(c.LogLevel)
+}
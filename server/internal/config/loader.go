@@ -0,0 +1,257 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// setting is a config key, it is read from config.yaml where nested keys are sections, from the
+// env variable and from a flag named after the key with "." and "_" replaced by "-".
+// Flags override env variables which override config.yaml.
+type setting struct {
+	key    string
+	env    string
+	def    any
+	usage  string
+	secret bool
+}
+
+var settings = []setting{
+	{key: "whitelisted_domains", env: "WHITELISTED_DOMAINS", def: []string{"gstatic.com"}, usage: "domains images can be downloaded from"},
+	{key: "application_domains", env: "APPLICATION_DOMAINS", def: []string{"localhost", "qasrelmemez.com"}, usage: "domains of the web app allowed to track engagement"},
+	{key: "max_upload_size", env: "MAX_UPLOAD_SIZE", def: int64(2000000), usage: "maximum image size in bytes"},
+	{key: "port", env: "PORT", def: 8080, usage: "HTTP port"},
+	{key: "rate_limit", env: "RATE_LIMIT", def: 100, usage: "requests per second allowed per client"},
+	{key: "burst_rate", env: "BURST_RATE", def: 10, usage: "requests a client can burst above the rate limit"},
+	{key: "log_level", env: "LOG_LEVEL", def: 0, usage: "-4 (debug), 0 (info), 4 (warn) or 8 (error)"},
+	{key: "trash_retention_days", env: "TRASH_RETENTION_DAYS", def: 30, usage: "days deleted memes are kept in the trash"},
+
+	{key: "database.host", env: "DB_HOST", def: "localhost", usage: "Postgres host"},
+	{key: "database.port", env: "DB_PORT", def: 5432, usage: "Postgres port"},
+	{key: "database.user", env: "DB_USER", def: "postgres", usage: "Postgres user"},
+	{key: "database.password", env: "DB_PASSWORD", def: "postgres", usage: "Postgres password", secret: true},
+	{key: "database.name", env: "DB_NAME", def: "memedb", usage: "Postgres database"},
+
+	{key: "storage.base_url", env: "STORAGE_BASE_URL", def: "", usage: "public URL the images are served from"},
+	{key: "storage.r2_bucket", env: "R2_BUCKET_NAME", def: "", usage: "R2 bucket storing the images"},
+	{key: "storage.r2_account_id", env: "R2_ACCOUNT_ID", def: "", usage: "Cloudflare account of the R2 bucket"},
+	{key: "storage.r2_access_key_id", env: "R2_ACCESS_KEY_ID", def: "", usage: "R2 access key ID", secret: true},
+	{key: "storage.r2_access_key_secret", env: "R2_ACCESS_KEY_SECRET", def: "", usage: "R2 access key secret", secret: true},
+	{key: "storage.upload_signing_key", env: "UPLOAD_SIGNING_KEY", def: "", usage: "key signing local storage upload URLs", secret: true},
+
+	{key: "auth.jwt_secret", env: "JWT_SECRET", def: "", usage: "key signing admin tokens", secret: true},
+	{key: "auth.admin_user", env: "ADMIN_USER", def: "", usage: "admin username"},
+	{key: "auth.admin_pass_hash", env: "ADMIN_PASS_HASH", def: "", usage: "bcrypt hash of the admin password", secret: true},
+
+	{key: "notifications.url", env: "NOTIFICATION_SERVICE_HOST", def: "", usage: "endpoint new memes are posted to"},
+	{key: "notifications.telegram_chat_id", env: "TELEGRAM_CHAT_ID", def: int64(0), usage: "Telegram chat receiving the notifications"},
+
+	{key: "tracing.exporter", env: "TRACING_EXPORTER", def: "none", usage: "where spans are sent: none, stdout or otlp"},
+	{key: "tracing.otlp_endpoint", env: "OTEL_EXPORTER_OTLP_ENDPOINT", def: "", usage: "OTLP/HTTP collector URL, e.g. http://localhost:4318"},
+	{key: "tracing.sample_ratio", env: "TRACING_SAMPLE_RATIO", def: 1.0, usage: "share of new traces that are recorded, between 0 and 1"},
+
+	{key: "grpc.meme_service_addr", env: "MEME_SERVICE_ADDR", def: "", usage: "gRPC address of the meme service, it runs in-process when empty"},
+	{key: "grpc.listen_addr", env: "GRPC_LISTEN_ADDR", def: ":9090", usage: "address the standalone meme service listens on"},
+	{key: "grpc.tls_cert_file", env: "GRPC_TLS_CERT_FILE", def: "", usage: "TLS certificate of the meme service"},
+	{key: "grpc.tls_key_file", env: "GRPC_TLS_KEY_FILE", def: "", usage: "TLS key of the meme service"},
+	{key: "grpc.tls_ca_file", env: "GRPC_TLS_CA_FILE", def: "", usage: "CA verifying the meme service certificate"},
+	{key: "grpc.service_token", env: "GRPC_SERVICE_TOKEN", def: "", usage: "token the gateway authenticates to the meme service with", secret: true},
+	{key: "grpc.metrics_addr", env: "GRPC_METRICS_ADDR", def: ":9091", usage: "address the standalone meme service serves /metrics on"},
+
+	{key: "ranking.text_weight", env: "RANKING_TEXT_WEIGHT", def: 1.0, usage: "weight of how well a meme matches the search"},
+	{key: "ranking.download_weight", env: "RANKING_DOWNLOAD_WEIGHT", def: 0.1, usage: "weight of the logarithm of the download count in search results"},
+	{key: "ranking.share_weight", env: "RANKING_SHARE_WEIGHT", def: 0.15, usage: "weight of the logarithm of the share count in search results"},
+	{key: "ranking.recency_weight", env: "RANKING_RECENCY_WEIGHT", def: 0.5, usage: "weight of the recency of a meme in search results"},
+	{key: "ranking.recency_half_life_days", env: "RANKING_RECENCY_HALF_LIFE_DAYS", def: 30.0, usage: "days it takes the recency of a meme to halve"},
+}
+
+func flagName(key string) string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(key)
+}
+
+// Loader reads the config from config.yaml, the environment and command line flags
+type Loader struct {
+	v     *viper.Viper
+	flags *pflag.FlagSet
+}
+
+// NewLoader registers a flag for every setting and --config on flags. The flags must be parsed
+// before calling Load.
+func NewLoader(flags *pflag.FlagSet) *Loader {
+	l := &Loader{v: viper.New(), flags: flags}
+	flags.String("config", "config.yaml", "path of the YAML config file, it is optional")
+	for _, s := range settings {
+		name := flagName(s.key)
+		switch def := s.def.(type) {
+		case string:
+			flags.String(name, def, s.usage)
+		case int:
+			flags.Int(name, def, s.usage)
+		case int64:
+			flags.Int64(name, def, s.usage)
+		case float64:
+			flags.Float64(name, def, s.usage)
+		case []string:
+			flags.StringSlice(name, def, s.usage)
+		default:
+			panic(fmt.Sprintf("unsupported default %T for %s", def, s.key))
+		}
+		l.v.SetDefault(s.key, s.def)
+		l.v.BindEnv(s.key, s.env)
+		l.v.BindPFlag(s.key, flags.Lookup(name))
+	}
+	return l
+}
+
+func (l *Loader) configFile() string {
+	path, _ := l.flags.GetString("config")
+	return path
+}
+
+// Load reads the settings, a missing config file leaves them to the env, flags and defaults
+func (l *Loader) Load() (*Config, error) {
+	l.v.SetConfigFile(l.configFile())
+	l.v.SetConfigType("yaml")
+	if err := l.v.ReadInConfig(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read the config file: %w", err)
+	}
+	return &Config{
+		WhitelistedDomains: l.stringSlice("whitelisted_domains"),
+		ApplicationDomains: l.stringSlice("application_domains"),
+		MaxUploadSize:      l.v.GetInt64("max_upload_size"),
+		Port:               l.v.GetInt32("port"),
+		TokenRate:          l.v.GetInt32("rate_limit"),
+		BurstRate:          l.v.GetInt32("burst_rate"),
+		LogLevel:           int8(l.v.GetInt("log_level")),
+		TrashRetentionDays: l.v.GetInt32("trash_retention_days"),
+		Database: DatabaseConfig{
+			Host:     l.v.GetString("database.host"),
+			Port:     l.v.GetInt32("database.port"),
+			User:     l.v.GetString("database.user"),
+			Password: l.v.GetString("database.password"),
+			Name:     l.v.GetString("database.name"),
+		},
+		Storage: StorageConfig{
+			BaseURL:           l.v.GetString("storage.base_url"),
+			R2Bucket:          l.v.GetString("storage.r2_bucket"),
+			R2AccountID:       l.v.GetString("storage.r2_account_id"),
+			R2AccessKeyID:     l.v.GetString("storage.r2_access_key_id"),
+			R2AccessKeySecret: l.v.GetString("storage.r2_access_key_secret"),
+			UploadSigningKey:  l.v.GetString("storage.upload_signing_key"),
+		},
+		Auth: AuthConfig{
+			JWTSecret:     l.v.GetString("auth.jwt_secret"),
+			AdminUser:     l.v.GetString("auth.admin_user"),
+			AdminPassHash: l.v.GetString("auth.admin_pass_hash"),
+		},
+		Notifications: NotificationsConfig{
+			URL:            l.v.GetString("notifications.url"),
+			TelegramChatID: l.v.GetInt64("notifications.telegram_chat_id"),
+		},
+		Tracing: TracingConfig{
+			Exporter:     l.v.GetString("tracing.exporter"),
+			OTLPEndpoint: l.v.GetString("tracing.otlp_endpoint"),
+			SampleRatio:  l.v.GetFloat64("tracing.sample_ratio"),
+		},
+		GRPC: GRPCConfig{
+			MemeServiceAddr: l.v.GetString("grpc.meme_service_addr"),
+			ListenAddr:      l.v.GetString("grpc.listen_addr"),
+			TLSCertFile:     l.v.GetString("grpc.tls_cert_file"),
+			TLSKeyFile:      l.v.GetString("grpc.tls_key_file"),
+			TLSCAFile:       l.v.GetString("grpc.tls_ca_file"),
+			ServiceToken:    l.v.GetString("grpc.service_token"),
+			MetricsAddr:     l.v.GetString("grpc.metrics_addr"),
+		},
+		Ranking: RankingConfig{
+			TextWeight:          l.v.GetFloat64("ranking.text_weight"),
+			DownloadWeight:      l.v.GetFloat64("ranking.download_weight"),
+			ShareWeight:         l.v.GetFloat64("ranking.share_weight"),
+			RecencyWeight:       l.v.GetFloat64("ranking.recency_weight"),
+			RecencyHalfLifeDays: l.v.GetFloat64("ranking.recency_half_life_days"),
+		},
+	}, nil
+}
+
+// env variables hold lists as comma separated values
+func (l *Loader) stringSlice(key string) []string {
+	value, ok := l.v.Get(key).(string)
+	if !ok {
+		return l.v.GetStringSlice(key)
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// Watch publishes config file changes to store. Changes that fail to load or validate are logged
+// and the previous config stays in use.
+func (l *Loader) Watch(store *Store, log *slog.Logger) {
+	if _, err := os.Stat(l.configFile()); err != nil {
+		log.Info("No config file to watch, config changes need a restart", "File", l.configFile())
+		return
+	}
+	l.v.OnConfigChange(func(e fsnotify.Event) {
+		log.Info("Config file changed", "File", e.Name)
+		store.reload(log, l.Load)
+	})
+	l.v.WatchConfig()
+}
+
+// source reports where the effective value of s comes from
+func (l *Loader) source(s setting) string {
+	if flag := l.flags.Lookup(flagName(s.key)); flag != nil && flag.Changed {
+		return "flag --" + flag.Name
+	}
+	if value, ok := os.LookupEnv(s.env); ok && value != "" {
+		return "env " + s.env
+	}
+	if l.v.InConfig(s.key) {
+		return l.configFile()
+	}
+	return "default"
+}
+
+// value is the effective value of s as printed, secrets are redacted
+func (l *Loader) value(s setting) string {
+	value := fmt.Sprint(l.v.Get(s.key))
+	if _, ok := s.def.([]string); ok {
+		value = "[" + strings.Join(l.stringSlice(s.key), " ") + "]"
+	}
+	if s.secret && value != "" {
+		value = "<redacted>"
+	}
+	return value
+}
+
+// Print writes the effective value of every setting and where it comes from, secrets are redacted
+func (l *Loader) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE")
+	for _, s := range settings {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", s.key, l.value(s), l.source(s))
+	}
+	return tw.Flush()
+}
+
+// LogValue logs the effective value of every setting like Print, as a single group
+func (l *Loader) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, len(settings))
+	for _, s := range settings {
+		attrs = append(attrs, slog.String(s.key, l.value(s)))
+	}
+	return slog.GroupValue(attrs...)
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newTestLoader(t *testing.T, args ...string) *Loader {
+	t.Helper()
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	loader := NewLoader(flags)
+	if err := flags.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return loader
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeConfigFile(t, `
+port: 9000
+rate_limit: 50
+burst_rate: 5
+database:
+  host: db.internal
+  name: memes
+`)
+	t.Setenv("RATE_LIMIT", "70")
+	t.Setenv("DB_HOST", "db.env")
+	loader := newTestLoader(t, "--config", path, "--rate-limit", "90")
+
+	cfg, err := loader.Load()
+	if err != nil {
+		t.Fatal("Load should succeed", err)
+	}
+	if cfg.Port != 9000 || cfg.BurstRate != 5 || cfg.Database.Name != "memes" {
+		t.Errorf("The config file should override the defaults, got %+v", cfg)
+	}
+	if cfg.Database.Host != "db.env" {
+		t.Errorf("The env should override the config file, got %s", cfg.Database.Host)
+	}
+	if cfg.TokenRate != 90 {
+		t.Errorf("The flag should override the env and config file, got %d", cfg.TokenRate)
+	}
+	if cfg.MaxUploadSize != 2000000 || cfg.Database.User != "postgres" {
+		t.Errorf("Unset keys should keep their defaults, got %+v", cfg)
+	}
+}
+
+func TestLoadSections(t *testing.T) {
+	t.Setenv("TRACING_EXPORTER", "otlp")
+	t.Setenv("TRACING_SAMPLE_RATIO", "0.25")
+	loader := newTestLoader(t, "--tracing-otlp-endpoint", "http://collector:4318")
+
+	cfg, err := loader.Load()
+	if err != nil {
+		t.Fatal("Load should succeed", err)
+	}
+	want := TracingConfig{Exporter: "otlp", OTLPEndpoint: "http://collector:4318", SampleRatio: 0.25}
+	if cfg.Tracing != want {
+		t.Errorf("Expected tracing %+v, got %+v", want, cfg.Tracing)
+	}
+	wantRanking := RankingConfig{TextWeight: 1, DownloadWeight: 0.1, ShareWeight: 0.15, RecencyWeight: 0.5, RecencyHalfLifeDays: 30}
+	if cfg.Ranking != wantRanking {
+		t.Errorf("Expected the default ranking %+v, got %+v", wantRanking, cfg.Ranking)
+	}
+}
+
+func TestLoadEnvLists(t *testing.T) {
+	t.Setenv("WHITELISTED_DOMAINS", "gstatic.com, redd.it,,twimg.com")
+	cfg, err := newTestLoader(t, "--config", filepath.Join(t.TempDir(), "missing.yaml")).Load()
+	if err != nil {
+		t.Fatal("A missing config file should not fail", err)
+	}
+	want := []string{"gstatic.com", "redd.it", "twimg.com"}
+	if strings.Join(cfg.WhitelistedDomains, " ") != strings.Join(want, " ") {
+		t.Errorf("Expected %v, got %v", want, cfg.WhitelistedDomains)
+	}
+}
+
+func TestLoadInvalidConfigFile(t *testing.T) {
+	path := writeConfigFile(t, "port: [8080\n")
+	if _, err := newTestLoader(t, "--config", path).Load(); err == nil {
+		t.Error("A malformed config file should fail to load")
+	}
+}
+
+func TestPrint(t *testing.T) {
+	path := writeConfigFile(t, "port: 9000\n")
+	t.Setenv("JWT_SECRET", "very-secret")
+	loader := newTestLoader(t, "--config", path, "--database-host", "db.flag")
+	if _, err := loader.Load(); err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	if err := loader.Print(&out); err != nil {
+		t.Fatal(err)
+	}
+	printed := out.String()
+	if strings.Contains(printed, "very-secret") {
+		t.Error("Secrets should be redacted")
+	}
+	rows := map[string][]string{
+		"auth.jwt_secret": {"<redacted>", "env JWT_SECRET"},
+		"database.host":   {"db.flag", "flag --database-host"},
+		"port":            {"9000", path},
+		"rate_limit":      {"100", "default"},
+	}
+	for key, want := range rows {
+		var row string
+		for _, line := range strings.Split(printed, "\n") {
+			if strings.HasPrefix(line, key+" ") {
+				row = line
+			}
+		}
+		for _, w := range want {
+			if !strings.Contains(row, w) {
+				t.Errorf("The %s row should contain %q:\n%s", key, w, printed)
+			}
+		}
+	}
+}
+
+func TestLogValue(t *testing.T) {
+	t.Setenv("JWT_SECRET", "very-secret")
+	loader := newTestLoader(t, "--database-host", "db.flag")
+	if _, err := loader.Load(); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	slog.New(slog.NewJSONHandler(&out, nil)).Info("Config loaded", "CONFIG", loader)
+	if strings.Contains(out.String(), "very-secret") {
+		t.Error("Secrets should be redacted")
+	}
+	var record struct {
+		Config map[string]string `json:"CONFIG"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+		t.Fatalf("Expected a single JSON record, got %q: %v", out.String(), err)
+	}
+	if record.Config["database.host"] != "db.flag" || record.Config["auth.jwt_secret"] != "<redacted>" || len(record.Config) != len(settings) {
+		t.Errorf("Unexpected config %v", record.Config)
+	}
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Store holds the current Config. Readers get a consistent snapshot without locking and
+// subscribers are told about every new snapshot so they can reconfigure themselves.
+type Store struct {
+	current atomic.Pointer[Config]
+	// serializes updates so subscribers see them in order
+	mu          sync.Mutex
+	subscribers []func(*Config)
+}
+
+func NewStore(cfg *Config) *Store {
+	s := &Store{}
+	s.current.Store(cfg)
+	return s
+}
+
+// Current returns the latest config, it must not be modified
+func (s *Store) Current() *Config {
+	return s.current.Load()
+}
+
+// Subscribe calls fn with every config published after it subscribed
+func (s *Store) Subscribe(fn func(*Config)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers = append(s.subscribers, fn)
+}
+
+// Update validates cfg and publishes it, an invalid config is returned as an error and the
+// current one is kept
+func (s *Store) Update(cfg *Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.current.Store(cfg)
+	for _, fn := range s.subscribers {
+		fn(cfg)
+	}
+	return nil
+}
+
+// reload publishes the config returned by load, logging why it was rejected if it can't be used
+func (s *Store) reload(log *slog.Logger, load func() (*Config, error)) {
+	cfg, err := load()
+	if err != nil {
+		log.Error("Failed to reload the config, keeping the current one", "Error", err)
+		return
+	}
+	old := s.Current()
+	if err := s.Update(cfg); err != nil {
+		log.Error("Rejected invalid config, keeping the current one", "Error", err)
+		return
+	}
+	if cfg.Port != old.Port || cfg.TrashRetentionDays != old.TrashRetentionDays || !slices.Equal(cfg.ApplicationDomains, old.ApplicationDomains) || cfg.Ranking != old.Ranking {
+		log.Warn("port, trash_retention_days, application_domains and ranking changes take effect after a restart")
+	}
+	log.Info("Config reloaded", "Domains", cfg.WhitelistedDomains, "Upload File Size", cfg.MaxUploadSize,
+		"RATE", cfg.TokenRate, "BURST", cfg.BurstRate, "Log Level", cfg.LogLevel)
+}
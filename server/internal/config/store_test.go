@@ -0,0 +1,137 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func validConfig() *Config {
+	return &Config{
+		WhitelistedDomains: []string{"gstatic.com"},
+		ApplicationDomains: []string{"localhost"},
+		MaxUploadSize:      2000000,
+		Port:               8080,
+		TokenRate:          100,
+		BurstRate:          10,
+		LogLevel:           0,
+		TrashRetentionDays: 30,
+		Database:           DatabaseConfig{Host: "localhost", Port: 5432, User: "postgres", Name: "memedb"},
+		Storage: StorageConfig{
+			BaseURL:           "https://imgs.example.com",
+			R2Bucket:          "memes",
+			R2AccountID:       "account",
+			R2AccessKeyID:     "key-id",
+			R2AccessKeySecret: "key-secret",
+		},
+		Auth:    AuthConfig{JWTSecret: "secret"},
+		Tracing: TracingConfig{Exporter: "none", SampleRatio: 1},
+		GRPC:    GRPCConfig{ListenAddr: ":9090"},
+		Ranking: RankingConfig{TextWeight: 1, RecencyHalfLifeDays: 30},
+	}
+}
+
+func TestValidateListsEveryError(t *testing.T) {
+	cfg := validConfig()
+	if err := cfg.Validate(); err != nil {
+		t.Fatal("The config should be valid", err)
+	}
+
+	cfg.WhitelistedDomains = nil
+	cfg.MaxUploadSize = 0
+	cfg.BurstRate = -1
+	cfg.LogLevel = 3
+	cfg.Tracing.Exporter = "jaeger"
+	cfg.Ranking.ShareWeight = -1
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("The config should be invalid")
+	}
+	for _, key := range []string{"whitelisted_domains", "max_upload_size", "burst_rate", "log_level", "tracing.exporter", "ranking.share_weight"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("The error should mention %s: %v", key, err)
+		}
+	}
+}
+
+func TestValidateRemoteMemeService(t *testing.T) {
+	cfg := validConfig()
+	cfg.Database = DatabaseConfig{}
+	cfg.Storage = StorageConfig{}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("The in-process meme service needs the database and storage")
+	}
+
+	cfg.GRPC.MemeServiceAddr = "memeservice:9090"
+	cfg.GRPC.ServiceToken = "token"
+	if err := cfg.Validate(); err != nil {
+		t.Error("The gateway doesn't need the database or storage of a remote meme service", err)
+	}
+
+	cfg.GRPC.TLSCertFile = "cert.pem"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "grpc.tls_key_file") {
+		t.Errorf("The TLS certificate needs its key, got %v", err)
+	}
+}
+
+func TestStoreUpdateNotifiesSubscribers(t *testing.T) {
+	store := NewStore(validConfig())
+	var notified []*Config
+	store.Subscribe(func(cfg *Config) { notified = append(notified, cfg) })
+
+	next := validConfig()
+	next.MaxUploadSize = 1000
+	if err := store.Update(next); err != nil {
+		t.Fatal("The update should succeed", err)
+	}
+	if store.Current() != next {
+		t.Error("The new config should be current")
+	}
+	if len(notified) != 1 || notified[0] != next {
+		t.Errorf("The subscriber should be notified once with the new config, got %v", notified)
+	}
+}
+
+func TestStoreRejectsInvalidUpdate(t *testing.T) {
+	initial := validConfig()
+	store := NewStore(initial)
+	notified := false
+	store.Subscribe(func(cfg *Config) { notified = true })
+
+	invalid := validConfig()
+	invalid.TokenRate = 0
+	if err := store.Update(invalid); err == nil {
+		t.Error("The invalid config should be rejected")
+	}
+	if store.Current() != initial || notified {
+		t.Error("The current config should be kept and subscribers not notified")
+	}
+}
+
+func TestStoreReloadLogsRejectedConfig(t *testing.T) {
+	initial := validConfig()
+	store := NewStore(initial)
+	var logs bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&logs, nil))
+
+	store.reload(log, func() (*Config, error) { return nil, errors.New("yaml: line 3: mapping values are not allowed") })
+	invalid := validConfig()
+	invalid.MaxUploadSize = -1
+	store.reload(log, func() (*Config, error) { return invalid, nil })
+
+	if store.Current() != initial {
+		t.Error("Failed reloads should keep the current config")
+	}
+	if !strings.Contains(logs.String(), "mapping values") || !strings.Contains(logs.String(), "max_upload_size") {
+		t.Errorf("Both rejected reloads should be logged, got %s", logs.String())
+	}
+
+	next := validConfig()
+	next.WhitelistedDomains = []string{"gstatic.com", "redd.it"}
+	store.reload(log, func() (*Config, error) { return next, nil })
+	if store.Current() != next {
+		t.Error("A valid reload should become current")
+	}
+}
@@ -4,24 +4,24 @@ import (
 	"database/sql"
 	"fmt"
 
-	"github.com/BassemHalim/memesHub/internal/utils"
+	"github.com/BassemHalim/memesHub/internal/config"
+	"github.com/XSAM/otelsql"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
 )
 
-func buildDBConnString() string {
-	host := utils.GetEnvOrDefault("DB_HOST", "localhost")
-	port := utils.GetEnvOrDefault("DB_PORT", "5432")
-	user := utils.GetEnvOrDefault("DB_USER", "postgres")
-	password := utils.GetEnvOrDefault("DB_PASSWORD", "postgres")
-	DBname := utils.GetEnvOrDefault("DB_NAME", "memedb")
-
+func buildDBConnString(cfg config.DatabaseConfig) string {
 	return fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		host, port, user, password, DBname,
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
+		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name,
 	)
 }
 
-func New() (*sql.DB, error) {
-	db, err := sql.Open("postgres", buildDBConnString())
+func New(cfg config.DatabaseConfig) (*sql.DB, error) {
+	// every query is traced as a child of the span in its context
+	db, err := otelsql.Open("postgres", buildDBConnString(cfg),
+		otelsql.WithAttributes(semconv.DBSystemNamePostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{OmitRows: true, OmitConnResetSession: true}),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("error connecting to the database: %v", err)
 	}
@@ -0,0 +1,159 @@
+package fetcher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// post pages only need their <head>, anything bigger isn't a page we want to parse
+const maxPageBytes = 2 * 1024 * 1024
+
+// Adapter resolves the post URLs of a social media platform to the URL of the posted image
+type Adapter struct {
+	// stored in images.social_media_platform
+	Platform string
+	// post pages are served from these hosts
+	Hosts []string
+	// paths of post pages, other pages on the hosts aren't posts
+	Path *regexp.Regexp
+	// the CDNs serving the images of the platform, allowed on top of the configured domains
+	MediaDomains []string
+}
+
+var defaultAdapters = []Adapter{
+	{
+		Platform:     "Reddit",
+		Hosts:        []string{"reddit.com", "www.reddit.com", "old.reddit.com", "new.reddit.com", "np.reddit.com"},
+		Path:         regexp.MustCompile(`^/r/[^/]+/(comments|s)/[^/]+`),
+		MediaDomains: []string{"redd.it", "redditmedia.com"},
+	},
+	{
+		Platform:     "Imgur",
+		Hosts:        []string{"imgur.com", "www.imgur.com", "m.imgur.com"},
+		Path:         regexp.MustCompile(`^/((a|gallery|t/[^/]+)/)?[A-Za-z0-9-]+/?$`),
+		MediaDomains: []string{"i.imgur.com"},
+	},
+	{
+		Platform:     "X",
+		Hosts:        []string{"x.com", "www.x.com", "twitter.com", "www.twitter.com", "mobile.twitter.com"},
+		Path:         regexp.MustCompile(`^/[^/]+/status/\d+`),
+		MediaDomains: []string{"twimg.com"},
+	},
+	{
+		Platform:     "Instagram",
+		Hosts:        []string{"instagram.com", "www.instagram.com"},
+		Path:         regexp.MustCompile(`^/(p|reel)/[^/]+`),
+		MediaDomains: []string{"cdninstagram.com", "fbcdn.net"},
+	},
+	{
+		Platform:     "Pinterest",
+		Hosts:        []string{"pinterest.com", "www.pinterest.com"},
+		Path:         regexp.MustCompile(`^/pin/[^/]+`),
+		MediaDomains: []string{"pinimg.com"},
+	},
+	{
+		Platform:     "FB",
+		Hosts:        []string{"facebook.com", "www.facebook.com", "m.facebook.com"},
+		Path:         regexp.MustCompile(`^/(photo(\.php)?|[^/]+/(posts|photos)/|share/p/)`),
+		MediaDomains: []string{"fbcdn.net"},
+	},
+	{
+		Platform:     "LinkedIn",
+		Hosts:        []string{"linkedin.com", "www.linkedin.com"},
+		Path:         regexp.MustCompile(`^/(posts|feed/update)/`),
+		MediaDomains: []string{"licdn.com"},
+	},
+}
+
+// Matches reports whether u is a post page of the adapter's platform
+func (a Adapter) Matches(u *url.URL) bool {
+	return slices.Contains(a.Hosts, strings.ToLower(u.Hostname())) && a.Path.MatchString(u.EscapedPath())
+}
+
+func (f *Fetcher) adapterFor(u *url.URL) (Adapter, bool) {
+	for _, adapter := range f.adapters {
+		if adapter.Matches(u) {
+			return adapter, true
+		}
+	}
+	return Adapter{}, false
+}
+
+// resolvePost downloads the post page and returns the image it advertises through OpenGraph.
+// The page and its redirects are restricted to the hosts of the adapter.
+func (f *Fetcher) resolvePost(ctx context.Context, adapter Adapter, post *url.URL) (string, error) {
+	resp, err := f.get(ctx, post.String(), adapter.Hosts, "text/html")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	page, err := readLimited(resp, maxPageBytes)
+	if err != nil {
+		return "", err
+	}
+	mediaURL, ok := OpenGraphImage(bytes.NewReader(page), resp.Request.URL)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNoMedia, post)
+	}
+	return mediaURL, nil
+}
+
+// OpenGraphImage returns the og:image of an HTML page, falling back to twitter:image.
+// Relative URLs are resolved against base.
+func OpenGraphImage(page io.Reader, base *url.URL) (string, bool) {
+	var twitterImage string
+	tokenizer := html.NewTokenizer(page)
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return resolveReference(base, twitterImage)
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			if token.Data == "body" {
+				// OpenGraph tags live in <head>
+				return resolveReference(base, twitterImage)
+			}
+			if token.Data != "meta" {
+				continue
+			}
+			var key, content string
+			for _, attr := range token.Attr {
+				switch attr.Key {
+				case "property", "name":
+					key = strings.ToLower(attr.Val)
+				case "content":
+					content = strings.TrimSpace(attr.Val)
+				}
+			}
+			switch key {
+			case "og:image", "og:image:url", "og:image:secure_url":
+				if mediaURL, ok := resolveReference(base, content); ok {
+					return mediaURL, true
+				}
+			case "twitter:image", "twitter:image:src":
+				if twitterImage == "" {
+					twitterImage = content
+				}
+			}
+		}
+	}
+}
+
+func resolveReference(base *url.URL, ref string) (string, bool) {
+	if ref == "" {
+		return "", false
+	}
+	parsed, err := url.Parse(ref)
+	if err != nil {
+		return "", false
+	}
+	return base.ResolveReference(parsed).String(), true
+}
@@ -0,0 +1,204 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestOpenGraphImage(t *testing.T) {
+	tests := []struct {
+		fixture  string
+		base     string
+		expected string
+	}{
+		{
+			fixture:  "reddit_post.html",
+			base:     "https://www.reddit.com/r/ProgrammerHumor/comments/1abc2de/when_the_code_works_on_the_first_try/",
+			expected: "https://preview.redd.it/abcdef123456.png?width=640&format=png&auto=webp&s=0123abcd",
+		},
+		{
+			fixture:  "imgur_post.html",
+			base:     "https://imgur.com/AbCd123",
+			expected: "https://i.imgur.com/AbCd123.jpeg?fb",
+		},
+		{
+			fixture:  "x_post.html",
+			base:     "https://x.com/someone/status/1234567890",
+			expected: "https://pbs.twimg.com/media/GAbCdEfXYZ.jpg:large",
+		},
+		{
+			fixture:  "instagram_post.html",
+			base:     "https://www.instagram.com/p/C1a2B3c4D5e/",
+			expected: "https://scontent.cdninstagram.com/v/t51.29350-15/412345678_n.jpg?stp=dst-jpg_e35&_nc_ht=scontent.cdninstagram.com",
+		},
+		{
+			fixture:  "relative_image.html",
+			base:     "https://www.reddit.com/r/memes/comments/1abc2de/post/",
+			expected: "https://www.reddit.com/media/meme.png",
+		},
+		{
+			fixture: "no_media.html",
+			base:    "https://www.instagram.com/p/C1a2B3c4D5e/",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			page, err := os.Open("testdata/" + tt.fixture)
+			if err != nil {
+				t.Fatal("Failed to open fixture", err)
+			}
+			defer page.Close()
+			base, _ := url.Parse(tt.base)
+
+			mediaURL, ok := OpenGraphImage(page, base)
+			if ok != (tt.expected != "") || mediaURL != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, mediaURL)
+			}
+		})
+	}
+}
+
+func TestAdapterFor(t *testing.T) {
+	f := New(Options{})
+	tests := []struct {
+		url      string
+		platform string
+	}{
+		{url: "https://www.reddit.com/r/memes/comments/1abc2de/title/", platform: "Reddit"},
+		{url: "https://old.reddit.com/r/memes/comments/1abc2de/", platform: "Reddit"},
+		{url: "https://www.reddit.com/r/memes/s/AbCdEf", platform: "Reddit"},
+		{url: "https://imgur.com/AbCd123", platform: "Imgur"},
+		{url: "https://imgur.com/gallery/monday-mood-AbCd123", platform: "Imgur"},
+		{url: "https://x.com/someone/status/1234567890", platform: "X"},
+		{url: "https://twitter.com/someone/status/1234567890?s=20", platform: "X"},
+		{url: "https://www.instagram.com/p/C1a2B3c4D5e/", platform: "Instagram"},
+		{url: "https://www.instagram.com/reel/C1a2B3c4D5e/", platform: "Instagram"},
+		{url: "https://www.pinterest.com/pin/123456789/", platform: "Pinterest"},
+		{url: "https://www.facebook.com/someone/posts/pfbid0abc", platform: "FB"},
+		{url: "https://www.facebook.com/photo.php?fbid=123", platform: "FB"},
+		{url: "https://www.linkedin.com/posts/someone_activity-123", platform: "LinkedIn"},
+		{url: "https://WWW.REDDIT.COM/r/memes/comments/1abc2de/", platform: "Reddit"},
+		// not posts
+		{url: "https://www.reddit.com/r/memes/"},
+		{url: "https://x.com/someone"},
+		{url: "https://www.instagram.com/someone/"},
+		// images and lookalike hosts are left to the image fetcher
+		{url: "https://i.redd.it/abcdef123456.png"},
+		{url: "https://pbs.twimg.com/media/GAbCdEfXYZ.jpg"},
+		{url: "https://reddit.com.evil.com/r/memes/comments/1abc2de/"},
+		{url: "https://evilreddit.com/r/memes/comments/1abc2de/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			parsed, _ := url.Parse(tt.url)
+			adapter, ok := f.adapterFor(parsed)
+			if ok != (tt.platform != "") || adapter.Platform != tt.platform {
+				t.Errorf("Expected %q, got %q", tt.platform, adapter.Platform)
+			}
+		})
+	}
+}
+
+func TestFetchMeme(t *testing.T) {
+	img := testPNG(t)
+	var port string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Host + r.URL.Path {
+		case "posts.example.com" + port + "/post/1":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(`<html><head><meta property="og:image" content="https://cdn.example.com` + port + `/meme.png"></head></html>`))
+		case "posts.example.com" + port + "/post/relative":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(`<html><head><meta property="og:image" content="/meme.png"></head></html>`))
+		case "posts.example.com" + port + "/post/empty":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(`<html><head><title>Log in</title></head></html>`))
+		case "posts.example.com" + port + "/post/elsewhere":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(`<html><head><meta property="og:image" content="https://other.example.com` + port + `/meme.png"></head></html>`))
+		case "posts.example.com" + port + "/post/redirect":
+			http.Redirect(w, r, "https://other.example.com"+port+"/post/1", http.StatusFound)
+		case "posts.example.com" + port + "/meme.png", "cdn.example.com" + port + "/meme.png", "images.example.com" + port + "/meme.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(img)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	port = ":" + strings.Split(server.Listener.Addr().String(), ":")[1]
+
+	f := newTestFetcher(t, server, int64(len(img)+100))
+	// only images.example.com is configured, the post pages and the CDN come from the adapter
+	f.SetLimits([]string{"images.example.com"}, int64(len(img)+100))
+	f.adapters = []Adapter{{
+		Platform:     "Test",
+		Hosts:        []string{"posts.example.com"},
+		Path:         regexp.MustCompile(`^/post/`),
+		MediaDomains: []string{"cdn.example.com", "posts.example.com"},
+	}}
+	resolver := f.resolver.(staticResolver)
+	for _, host := range []string{"posts.example.com", "cdn.example.com", "other.example.com"} {
+		resolver[host] = []netip.Addr{netip.MustParseAddr("127.0.0.1")}
+	}
+
+	tests := []struct {
+		name        string
+		url         string
+		expectedURL string
+		postURL     string
+		expectedErr error
+	}{
+		{
+			name:        "post",
+			url:         "https://posts.example.com" + port + "/post/1",
+			expectedURL: "https://cdn.example.com" + port + "/meme.png",
+			postURL:     "https://posts.example.com" + port + "/post/1",
+		},
+		{
+			name:        "relative image",
+			url:         "https://posts.example.com" + port + "/post/relative",
+			expectedURL: "https://posts.example.com" + port + "/meme.png",
+			postURL:     "https://posts.example.com" + port + "/post/relative",
+		},
+		{
+			name:        "direct image",
+			url:         "https://images.example.com" + port + "/meme.png",
+			expectedURL: "https://images.example.com" + port + "/meme.png",
+		},
+		{name: "cdn is only allowed for posts", url: "https://cdn.example.com" + port + "/meme.png", expectedErr: ErrDomainNotAllowed},
+		{name: "post without image", url: "https://posts.example.com" + port + "/post/empty", expectedErr: ErrNoMedia},
+		{name: "missing post", url: "https://posts.example.com" + port + "/post/missing", expectedErr: ErrBadStatus},
+		{name: "image on another domain", url: "https://posts.example.com" + port + "/post/elsewhere", expectedErr: ErrDomainNotAllowed},
+		{name: "post redirects to another domain", url: "https://posts.example.com" + port + "/post/redirect", expectedErr: ErrDomainNotAllowed},
+		{name: "http post", url: "http://posts.example.com" + port + "/post/1", expectedErr: ErrInvalidURL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetched, err := f.FetchMeme(context.Background(), tt.url)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Fatalf("Expected %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("Fetch should succeed", err)
+			}
+			if fetched.URL != tt.expectedURL || fetched.PostURL != tt.postURL {
+				t.Errorf("Expected image %s from post %q, got %s from post %q", tt.expectedURL, tt.postURL, fetched.URL, fetched.PostURL)
+			}
+			if tt.postURL != "" && fetched.Platform != "Test" {
+				t.Errorf("Expected platform Test, got %q", fetched.Platform)
+			}
+		})
+	}
+}
@@ -0,0 +1,335 @@
+// Package fetcher downloads images from user supplied URLs without exposing internal services.
+//
+// Every URL, including each redirect hop, must be https and on an allowed domain. The host is
+// resolved by the fetcher itself and the connection is made to a checked address, so DNS
+// rebinding can't point an allowed name at a private address between the check and the dial.
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInvalidURL       = errors.New("invalid url")
+	ErrDomainNotAllowed = errors.New("domain is not allowed")
+	ErrForbiddenAddress = errors.New("host resolves to a forbidden address")
+	ErrTooManyRedirects = errors.New("too many redirects")
+	ErrBadStatus        = errors.New("unexpected response status")
+	ErrTooLarge         = errors.New("image is too large")
+	ErrNotImage         = errors.New("response is not an image")
+	ErrNoMedia          = errors.New("post has no image")
+)
+
+const userAgent = "memesHub/1.0 (+https://qasrelmemez.com)"
+
+type Options struct {
+	// hosts equal to or subdomains of these domains can be fetched
+	AllowedDomains []string
+	// responses larger than this are rejected while streaming
+	MaxBytes     int64
+	Timeout      time.Duration
+	MaxRedirects int
+}
+
+// resolver is satisfied by *net.Resolver
+type resolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+type Fetcher struct {
+	client *http.Client
+	// guards allowedDomains and maxBytes which change when the config is reloaded
+	mu             sync.RWMutex
+	allowedDomains []string
+	maxBytes       int64
+	adapters       []Adapter
+	maxRedirects   int
+	resolver       resolver
+	// reports whether connecting to addr is allowed, tests relax it to reach httptest servers
+	addrAllowed func(addr netip.Addr) bool
+}
+
+func New(opts Options) *Fetcher {
+	f := &Fetcher{
+		allowedDomains: opts.AllowedDomains,
+		maxBytes:       opts.MaxBytes,
+		maxRedirects:   opts.MaxRedirects,
+		adapters:       defaultAdapters,
+		resolver:       net.DefaultResolver,
+		addrAllowed:    IsPublicAddr,
+	}
+	if f.maxRedirects <= 0 {
+		f.maxRedirects = 3
+	}
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	f.client = &http.Client{
+		Timeout: opts.Timeout,
+		Transport: &http.Transport{
+			// a proxy would make the connection on our behalf and skip the address check
+			Proxy: nil,
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				return f.dial(ctx, dialer, network, addr)
+			},
+			ForceAttemptHTTP2:   true,
+			TLSHandshakeTimeout: 5 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > f.maxRedirects {
+				return ErrTooManyRedirects
+			}
+			return checkURL(req.URL, allowedDomainsFrom(req.Context()))
+		},
+	}
+	return f
+}
+
+// SetLimits replaces the allowed domains and the maximum image size for the following fetches
+func (f *Fetcher) SetLimits(allowedDomains []string, maxBytes int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.allowedDomains = allowedDomains
+	f.maxBytes = maxBytes
+}
+
+func (f *Fetcher) limits() ([]string, int64) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.allowedDomains, f.maxBytes
+}
+
+// Image is a downloaded image whose content type was sniffed from its bytes
+type Image struct {
+	Data        []byte
+	ContentType string
+	// the URL the image was served from after following redirects
+	URL string
+	// the social media post the image was resolved from, empty for direct image URLs
+	PostURL  string
+	Platform string
+}
+
+type allowedDomainsKey struct{}
+
+// every hop of a request is checked against the domains of the request that started it
+func withAllowedDomains(ctx context.Context, domains []string) context.Context {
+	return context.WithValue(ctx, allowedDomainsKey{}, domains)
+}
+
+func allowedDomainsFrom(ctx context.Context) []string {
+	domains, _ := ctx.Value(allowedDomainsKey{}).([]string)
+	return domains
+}
+
+// FetchMeme downloads the image at rawURL. Social media post URLs are first resolved to the
+// URL of their image by the matching adapter.
+func (f *Fetcher) FetchMeme(ctx context.Context, rawURL string) (*Image, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidURL, err)
+	}
+	adapter, ok := f.adapterFor(parsed)
+	if !ok {
+		return f.FetchImage(ctx, rawURL)
+	}
+	mediaURL, err := f.resolvePost(ctx, adapter, parsed)
+	if err != nil {
+		return nil, err
+	}
+	allowedDomains, maxBytes := f.limits()
+	image, err := f.fetchImage(ctx, mediaURL, append(slices.Clone(allowedDomains), adapter.MediaDomains...), maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	image.PostURL = parsed.String()
+	image.Platform = adapter.Platform
+	return image, nil
+}
+
+// FetchImage downloads the image at rawURL, reading at most MaxBytes
+func (f *Fetcher) FetchImage(ctx context.Context, rawURL string) (*Image, error) {
+	allowedDomains, maxBytes := f.limits()
+	return f.fetchImage(ctx, rawURL, allowedDomains, maxBytes)
+}
+
+func (f *Fetcher) fetchImage(ctx context.Context, rawURL string, allowedDomains []string, maxBytes int64) (*Image, error) {
+	resp, err := f.get(ctx, rawURL, allowedDomains, "image/*")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" && !strings.HasPrefix(contentType, "image/") {
+		return nil, fmt.Errorf("%w: served as %s", ErrNotImage, contentType)
+	}
+	data, err := readLimited(resp, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// the served content type can't be trusted, check the bytes
+	contentType := http.DetectContentType(data)
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, fmt.Errorf("%w: detected %s", ErrNotImage, contentType)
+	}
+	return &Image{
+		Data:        data,
+		ContentType: contentType,
+		URL:         resp.Request.URL.String(),
+	}, nil
+}
+
+// get sends a GET to rawURL, allowing it and its redirects only on allowedDomains.
+// The caller must close the body of the returned 200 response.
+func (f *Fetcher) get(ctx context.Context, rawURL string, allowedDomains []string, accept string) (*http.Response, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidURL, err)
+	}
+	if err := checkURL(parsed, allowedDomains); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(withAllowedDomains(ctx, allowedDomains), http.MethodGet, parsed.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidURL, err)
+	}
+	req.Header.Set("Accept", accept)
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := f.client.Do(req)
+	if err != nil {
+		// surface our own errors instead of the url.Error wrapping them
+		for _, sentinel := range []error{ErrDomainNotAllowed, ErrForbiddenAddress, ErrTooManyRedirects, ErrInvalidURL} {
+			if errors.Is(err, sentinel) {
+				return nil, fmt.Errorf("%w: %s", sentinel, err)
+			}
+		}
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: %s", ErrBadStatus, resp.Status)
+	}
+	return resp, nil
+}
+
+// readLimited reads the body of resp failing with ErrTooLarge past maxBytes
+func readLimited(resp *http.Response, maxBytes int64) ([]byte, error) {
+	if maxBytes > 0 && resp.ContentLength > maxBytes {
+		return nil, fmt.Errorf("%w: %d bytes", ErrTooLarge, resp.ContentLength)
+	}
+	body := io.Reader(resp.Body)
+	if maxBytes > 0 {
+		// read one byte past the limit to tell a full body from a truncated one
+		body = io.LimitReader(resp.Body, maxBytes+1)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the response: %w", err)
+	}
+	if maxBytes > 0 && int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrTooLarge, maxBytes)
+	}
+	return data, nil
+}
+
+// checkURL validates the parts of a URL that don't need DNS
+func checkURL(u *url.URL, allowedDomains []string) error {
+	if u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme must be https", ErrInvalidURL)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: credentials are not allowed", ErrInvalidURL)
+	}
+	if !IsAllowedHost(u.Hostname(), allowedDomains) {
+		return fmt.Errorf("%w: %s", ErrDomainNotAllowed, u.Hostname())
+	}
+	return nil
+}
+
+// dial resolves host and connects to the first allowed address. Any forbidden address fails the
+// dial so a name can't mix public and private records to get lucky.
+func (f *Fetcher) dial(ctx context.Context, dialer *net.Dialer, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := f.resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no addresses found for %s", host)
+	}
+	for _, addr := range addrs {
+		if !f.addrAllowed(addr.Unmap()) {
+			return nil, fmt.Errorf("%w: %s resolves to %s", ErrForbiddenAddress, host, addr)
+		}
+	}
+	var lastErr error
+	for _, addr := range addrs {
+		conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(addr.Unmap().String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// IsAllowedHost reports whether host is one of domains or a subdomain of one of them.
+// Matching is on label boundaries so evilgstatic.com doesn't match gstatic.com.
+func IsAllowedHost(host string, domains []string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "" {
+		return false
+	}
+	for _, domain := range domains {
+		domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+		if domain == "" {
+			continue
+		}
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+var forbiddenPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "this" network
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64 can reach any IPv4 address
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local NAT64
+	netip.MustParsePrefix("2001:db8::/32"),  // documentation
+	netip.MustParsePrefix("2002::/16"),      // 6to4 embeds IPv4 addresses
+}
+
+// IsPublicAddr reports whether addr is a globally routable unicast address
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() || !addr.IsGlobalUnicast() {
+		return false
+	}
+	for _, prefix := range forbiddenPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
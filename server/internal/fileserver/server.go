@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/BassemHalim/memesHub/internal/apierror"
 )
 
 type FileServer struct {
@@ -43,7 +45,7 @@ func (s *FileServer) Handler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if !allowedExts[ext] {
-		http.Error(w, "Forbidden file type", http.StatusForbidden)
+		apierror.Write(w, r, http.StatusForbidden, "Forbidden file type", nil)
 		return
 	}
 
@@ -0,0 +1,144 @@
+// Package interceptors has the gRPC interceptors of the meme service. The server chains them as
+//
+//	grpc.ChainUnaryInterceptor(Logging(log), Metrics, Recovery(log), Auth(serviceToken, jwtSecret))
+//
+// so auth failures and recovered panics are logged and counted like any other error.
+package interceptors
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/BassemHalim/memesHub/internal/auth"
+	"github.com/BassemHalim/memesHub/internal/logging"
+	"github.com/BassemHalim/memesHub/internal/metrics"
+)
+
+const (
+	authorizationKey = "authorization"
+	// same ID as the X-Request-ID header of the gateway request that made the call
+	requestIDKey = "x-request-id"
+)
+
+// publicServices can be called without credentials so load balancers and grpcurl work
+var publicServices = []string{"/grpc.health.v1.Health/", "/grpc.reflection."}
+
+// Logging logs every call with its status code and duration. The request ID sent by the gateway
+// is attached to the context so the records logged by the handler include it.
+func Logging(log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if ids := md.Get(requestIDKey); len(ids) > 0 {
+				ctx = logging.WithRequestID(ctx, ids[0])
+			}
+		}
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		level := slog.LevelInfo
+		switch code {
+		case codes.OK, codes.NotFound, codes.InvalidArgument, codes.AlreadyExists, codes.FailedPrecondition, codes.Canceled:
+		case codes.Unauthenticated, codes.PermissionDenied:
+			level = slog.LevelWarn
+		default:
+			level = slog.LevelError
+		}
+		log.LogAttrs(ctx, level, "RPC",
+			slog.String("Method", info.FullMethod),
+			slog.String("Code", code.String()),
+			slog.Duration("Latency", time.Since(start)),
+		)
+		return resp, err
+	}
+}
+
+// Metrics records every call in the grpc_server_* metrics
+func Metrics(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	metrics.ObserveRPC(info.FullMethod, status.Code(err).String(), time.Since(start))
+	return resp, err
+}
+
+// Recovery turns a panic in a handler into a codes.Internal error instead of crashing the service
+func Recovery(log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				log.ErrorContext(ctx, "Recovered from a panic", "Method", info.FullMethod, "Panic", p, "Stack", string(debug.Stack()))
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// Auth rejects calls without the service token of the gateway or an admin JWT signed with
+// jwtSecret in the authorization metadata, as "Bearer <token>"
+func Auth(serviceToken string, jwtSecret string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		for _, prefix := range publicServices {
+			if strings.HasPrefix(info.FullMethod, prefix) {
+				return handler(ctx, req)
+			}
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get(authorizationKey)
+		if len(values) == 0 {
+			return nil, status.Error(codes.Unauthenticated, "missing credentials")
+		}
+		token, ok := strings.CutPrefix(values[0], "Bearer ")
+		if !ok || token == "" {
+			return nil, status.Error(codes.Unauthenticated, "the credentials must be a bearer token")
+		}
+		if serviceToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(serviceToken)) == 1 {
+			return handler(ctx, req)
+		}
+		if jwtSecret == "" {
+			return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+		}
+		if err := auth.ValidateAdminToken(jwtSecret, token); err != nil {
+			if errors.Is(err, auth.ErrNotAdmin) {
+				return nil, status.Error(codes.PermissionDenied, "admin role required")
+			}
+			return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+		}
+		return handler(ctx, req)
+	}
+}
+
+// serviceToken attaches the gateway's token to every call
+type serviceToken string
+
+func (t serviceToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{authorizationKey: "Bearer " + string(t)}, nil
+}
+
+// the meme service can run without TLS on a private network
+func (t serviceToken) RequireTransportSecurity() bool {
+	return false
+}
+
+// WithServiceToken is the dial option the gateway authenticates its calls with
+func WithServiceToken(token string) grpc.DialOption {
+	return grpc.WithPerRPCCredentials(serviceToken(token))
+}
+
+// PropagateRequestID sends the request ID of the gateway request with every call
+func PropagateRequestID(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if id := logging.RequestID(ctx); id != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, requestIDKey, id)
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
@@ -0,0 +1,161 @@
+package interceptors
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/BassemHalim/memesHub/internal/logging"
+	"github.com/BassemHalim/memesHub/internal/metrics"
+	pb "github.com/BassemHalim/memesHub/internal/proto/memeService"
+)
+
+const (
+	testServiceToken = "service-token"
+	testJWTSecret    = "jwt-secret"
+)
+
+// fakeMemeService panics on GetMeme for the "panic" ID and returns the meme otherwise
+type fakeMemeService struct {
+	pb.UnimplementedMemeServiceServer
+}
+
+func (fakeMemeService) GetMeme(ctx context.Context, req *pb.GetMemeRequest) (*pb.MemeResponse, error) {
+	if req.Id == "panic" {
+		panic("boom")
+	}
+	return &pb.MemeResponse{Id: req.Id}, nil
+}
+
+// serve starts a meme service with every interceptor over bufconn, the logs are written to logs
+func serve(t *testing.T, logs io.Writer) *grpc.ClientConn {
+	t.Helper()
+	log := slog.New(logging.NewHandler(slog.NewJSONHandler(logs, nil)))
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		Logging(log),
+		Metrics,
+		Recovery(log),
+		Auth(testServiceToken, testJWTSecret),
+	))
+	pb.RegisterMemeServiceServer(srv, fakeMemeService{})
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+
+	lis := bufconn.Listen(1 << 20)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(PropagateRequestID),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func signedToken(t *testing.T, role string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"username": "admin", "role": role}).SignedString([]byte(testJWTSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), authorizationKey, "Bearer "+token)
+}
+
+func TestAuth(t *testing.T) {
+	client := pb.NewMemeServiceClient(serve(t, io.Discard))
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		code codes.Code
+	}{
+		{"no credentials", context.Background(), codes.Unauthenticated},
+		{"not a bearer token", metadata.AppendToOutgoingContext(context.Background(), authorizationKey, testServiceToken), codes.Unauthenticated},
+		{"wrong service token", withToken("wrong-token"), codes.Unauthenticated},
+		{"service token", withToken(testServiceToken), codes.OK},
+		{"admin JWT", withToken(signedToken(t, "admin")), codes.OK},
+		{"non admin JWT", withToken(signedToken(t, "user")), codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.GetMeme(tt.ctx, &pb.GetMemeRequest{Id: "7218d21c-ac37-4ebe-b436-c51486d23b95"})
+			if status.Code(err) != tt.code {
+				t.Errorf("Expected %s, got %v", tt.code, err)
+			}
+		})
+	}
+}
+
+func TestAuthSkipsHealthChecks(t *testing.T) {
+	conn := serve(t, io.Discard)
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal("Health checks shouldn't need credentials", err)
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("Expected SERVING, got %s", resp.Status)
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	client := pb.NewMemeServiceClient(serve(t, io.Discard))
+	ctx := withToken(testServiceToken)
+
+	_, err := client.GetMeme(ctx, &pb.GetMemeRequest{Id: "panic"})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("A panic should become Internal, got %v", err)
+	}
+	if _, err := client.GetMeme(ctx, &pb.GetMemeRequest{Id: "after-panic"}); err != nil {
+		t.Error("The service should keep serving after a panic", err)
+	}
+}
+
+func TestLoggingAndMetrics(t *testing.T) {
+	var logs bytes.Buffer
+	client := pb.NewMemeServiceClient(serve(t, &logs))
+
+	ctx := logging.WithRequestID(withToken(testServiceToken), "req-42")
+	if _, err := client.GetMeme(ctx, &pb.GetMemeRequest{Id: "logged"}); err != nil {
+		t.Fatal(err)
+	}
+	client.GetMeme(context.Background(), &pb.GetMemeRequest{Id: "unauthenticated"})
+
+	for _, want := range []string{`"Method":"/meme.MemeService/GetMeme","Code":"OK"`, `"RequestID":"req-42"`, `"Code":"Unauthenticated"`} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("The logs should contain %s, got %s", want, logs.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	scraped := w.Body.String()
+	for _, code := range []string{"OK", "Unauthenticated"} {
+		if !strings.Contains(scraped, `memeshub_grpc_server_handled_total{code="`+code+`",method="/meme.MemeService/GetMeme"}`) {
+			t.Errorf("Expected a GetMeme series with code %s", code)
+		}
+	}
+}
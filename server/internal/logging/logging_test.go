@@ -0,0 +1,32 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestHandlerAddsRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(NewHandler(slog.NewJSONHandler(&buf, nil))).With("Service", "TEST")
+
+	log.InfoContext(WithRequestID(context.Background(), "req-1"), "with id")
+	log.Info("without id")
+
+	dec := json.NewDecoder(&buf)
+	var withID, withoutID map[string]any
+	if err := dec.Decode(&withID); err != nil {
+		t.Fatal(err)
+	}
+	if err := dec.Decode(&withoutID); err != nil {
+		t.Fatal(err)
+	}
+	if withID["RequestID"] != "req-1" || withID["Service"] != "TEST" {
+		t.Errorf("The record should have the request ID and the logger attrs, got %v", withID)
+	}
+	if _, ok := withoutID["RequestID"]; ok {
+		t.Errorf("Records without a request shouldn't have an ID, got %v", withoutID)
+	}
+}
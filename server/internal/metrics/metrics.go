@@ -0,0 +1,122 @@
+// Package metrics defines the Prometheus metrics of the gateway and the meme service, they are
+// served on /metrics
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "memeshub"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route pattern, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route pattern, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "meme_service_query_duration_seconds",
+		Help:      "Duration of the MemeService methods, which are dominated by their database queries.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	cacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_lookups_total",
+		Help:      "Response cache lookups by key type and result (hit or miss).",
+	}, []string{"key", "result"})
+
+	rateLimited = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limiter_rejections_total",
+		Help:      "Requests rejected by the rate limiter.",
+	})
+
+	storageDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "storage_operation_duration_seconds",
+		Help:      "Latency of the image storage operations.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+
+	storageErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "storage_operation_errors_total",
+		Help:      "Failed image storage operations.",
+	}, []string{"operation"})
+
+	grpcRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_server_handled_total",
+		Help:      "RPCs handled by the meme service by method and status code.",
+	}, []string{"method", "code"})
+
+	grpcRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_server_handling_seconds",
+		Help:      "Latency of the RPCs handled by the meme service by method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "code"})
+)
+
+// Handler serves the metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveRequest records a served HTTP request, route is the pattern it matched so the number of
+// series doesn't grow with the IDs in the path
+func ObserveRequest(route string, method string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	httpRequests.WithLabelValues(route, method, code).Inc()
+	httpRequestDuration.WithLabelValues(route, method, code).Observe(duration.Seconds())
+}
+
+// ObserveQuery records how long a MemeService method took since start, use it as
+// defer metrics.ObserveQuery("GetMeme", time.Now())
+func ObserveQuery(method string, start time.Time) {
+	queryDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+// CacheLookup records a cache lookup of a key type like "timeline" or "meme"
+func CacheLookup(key string, found bool) {
+	result := "miss"
+	if found {
+		result = "hit"
+	}
+	cacheLookups.WithLabelValues(key, result).Inc()
+}
+
+func RateLimited() {
+	rateLimited.Inc()
+}
+
+// ObserveStorage records a storage operation that started at start and failed if err is not nil
+func ObserveStorage(operation string, start time.Time, err error) {
+	storageDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		storageErrors.WithLabelValues(operation).Inc()
+	}
+}
+
+// ObserveRPC records an RPC handled by the meme service, method is the full method name like
+// /meme.MemeService/GetMeme
+func ObserveRPC(method string, code string, duration time.Duration) {
+	grpcRequests.WithLabelValues(method, code).Inc()
+	grpcRequestDuration.WithLabelValues(method, code).Observe(duration.Seconds())
+}
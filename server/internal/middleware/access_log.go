@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/BassemHalim/memesHub/internal/logging"
+	"github.com/BassemHalim/memesHub/internal/utils"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// requestID returns the ID sent by the client or the proxy in front of the gateway, or a new one
+// when it is missing or could break the logs
+func requestID(r *http.Request) string {
+	id := r.Header.Get(requestIDHeader)
+	if id == "" || len(id) > 128 {
+		return uuid.NewString()
+	}
+	for _, c := range id {
+		if c <= ' ' || c > '~' {
+			return uuid.NewString()
+		}
+	}
+	return id
+}
+
+// AccessLog logs every request once it is served and tags it with a request ID. The ID is echoed
+// in the X-Request-ID response header and attached to the request context so records logged with
+// log.InfoContext(r.Context(), ...) include it. It should wrap Metrics, which resolves the route.
+func AccessLog(log *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			id := requestID(r)
+			w.Header().Set(requestIDHeader, id)
+			r = r.WithContext(logging.WithRequestID(r.Context(), id))
+			r, route := withRoute(r)
+			rec := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+
+			level := slog.LevelInfo
+			if rec.code() >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			log.LogAttrs(r.Context(), level, "Request",
+				slog.String("Method", r.Method),
+				slog.String("Route", *route),
+				slog.String("Path", r.URL.Path),
+				slog.Int("Status", rec.code()),
+				slog.Int("Bytes", rec.bytes),
+				slog.Duration("Latency", time.Since(start)),
+				slog.String("IP", utils.ClientIP(r)),
+			)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/BassemHalim/memesHub/internal/logging"
+)
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(logging.NewHandler(slog.NewJSONHandler(&buf, nil)))
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /meme/{id}", func(w http.ResponseWriter, r *http.Request) {
+		log.InfoContext(r.Context(), "Handling")
+		w.Write([]byte("meme"))
+	})
+	handler := AccessLog(log)(Metrics(mux))
+
+	tests := []struct {
+		name      string
+		requestID string
+		keepID    bool
+	}{
+		{name: "propagated id", requestID: "edge-1234", keepID: true},
+		{name: "generated id"},
+		{name: "id with a newline is replaced", requestID: "forged\n{\"Status\":200}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest(http.MethodGet, "/meme/7218d21c-ac37-4ebe-b436-c51486d23b95", nil)
+			req.Header.Set("X-Request-ID", tt.requestID)
+			req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			id := w.Header().Get("X-Request-ID")
+			if id == "" || (tt.keepID && id != tt.requestID) || (!tt.keepID && id == tt.requestID) {
+				t.Fatalf("Unexpected request ID %q", id)
+			}
+			dec := json.NewDecoder(&buf)
+			var handling, access map[string]any
+			if err := dec.Decode(&handling); err != nil {
+				t.Fatal(err)
+			}
+			if err := dec.Decode(&access); err != nil {
+				t.Fatal(err)
+			}
+			if handling["RequestID"] != id {
+				t.Errorf("The handler's logs should have the request ID, got %v", handling)
+			}
+			want := map[string]any{"RequestID": id, "Method": "GET", "Route": "/meme/{id}", "Status": 200.0, "Bytes": 4.0, "IP": "203.0.113.7"}
+			for key, value := range want {
+				if access[key] != value {
+					t.Errorf("Expected %s=%v in the access log, got %v", key, value, access[key])
+				}
+			}
+		})
+	}
+}
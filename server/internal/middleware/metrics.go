@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/BassemHalim/memesHub/internal/metrics"
+)
+
+type routeKey struct{}
+
+// statusRecorder remembers the status code and the size of the response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	n, err := s.ResponseWriter.Write(b)
+	s.bytes += n
+	return n, err
+}
+
+// code returns the status of the response, handlers that write nothing respond 200
+func (s *statusRecorder) code() int {
+	if s.status == 0 {
+		return http.StatusOK
+	}
+	return s.status
+}
+
+// withRoute returns r with a place for Route to report the pattern the request matched, an outer
+// middleware may already have added it
+func withRoute(r *http.Request) (*http.Request, *string) {
+	if route, ok := r.Context().Value(routeKey{}).(*string); ok {
+		return r, route
+	}
+	route := new(string)
+	return r.WithContext(context.WithValue(r.Context(), routeKey{}, route)), route
+}
+
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// Metrics records the count and latency of every request by the route pattern it matched and names
+// the request's span after it. It must wrap the outermost router, routers mounted under a prefix
+// report their pattern through Route.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		r, route := withRoute(r)
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		// a ServeMux sets the pattern on the request it was given
+		if *route == "" {
+			*route = patternPath(r.Pattern)
+		}
+		if *route == "" {
+			*route = "unmatched"
+		}
+		metrics.ObserveRequest(*route, r.Method, rec.code(), time.Since(start))
+		// the server span is named before routing, name it after the route now that it's known
+		span := trace.SpanFromContext(r.Context())
+		span.SetName(r.Method + " " + *route)
+		span.SetAttributes(semconv.HTTPRoute(*route))
+	})
+}
+
+// Route reports the pattern matched by mux to Metrics, prefixed with the prefix mux is mounted
+// under since http.StripPrefix removed it
+func Route(prefix string, mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, r)
+		route, ok := r.Context().Value(routeKey{}).(*string)
+		// the innermost router finishes first and has the most specific pattern
+		if !ok || *route != "" || r.Pattern == "" {
+			return
+		}
+		*route = prefix + patternPath(r.Pattern)
+	})
+}
+
+// patternPath drops the method from a pattern like "GET /meme/{id}", it is its own label
+func patternPath(pattern string) string {
+	if i := strings.IndexByte(pattern, ' '); i >= 0 {
+		return strings.TrimLeft(pattern[i+1:], " ")
+	}
+	return pattern
+}
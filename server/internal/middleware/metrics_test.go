@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/BassemHalim/memesHub/internal/metrics"
+)
+
+func scrapeMetrics(t *testing.T) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(w.Body)
+	return string(body)
+}
+
+func TestMetricsRoutePattern(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	adminRouter := http.NewServeMux()
+	adminRouter.Handle("DELETE /meme/{id}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	apiRouter := http.NewServeMux()
+	apiRouter.Handle("GET /meme/{id}", ok)
+	apiRouter.Handle("/admin/", http.StripPrefix("/admin", Route("/api/admin", adminRouter)))
+	mainRouter := http.NewServeMux()
+	mainRouter.Handle("/api/", http.StripPrefix("/api", Route("/api", apiRouter)))
+	mainRouter.Handle("GET /healthz", ok)
+	handler := Metrics(mainRouter)
+
+	requests := []struct {
+		method string
+		path   string
+		series string
+	}{
+		{http.MethodGet, "/api/meme/7218d21c-ac37-4ebe-b436-c51486d23b95", `route="/api/meme/{id}",status="200"`},
+		{http.MethodDelete, "/api/admin/meme/7218d21c-ac37-4ebe-b436-c51486d23b95", `route="/api/admin/meme/{id}",status="204"`},
+		{http.MethodGet, "/healthz", `route="/healthz",status="200"`},
+		{http.MethodGet, "/nothing/here", `route="unmatched",status="404"`},
+	}
+	for _, req := range requests {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(req.method, req.path, nil))
+	}
+
+	scraped := scrapeMetrics(t)
+	for _, req := range requests {
+		if !strings.Contains(scraped, `memeshub_http_requests_total{method="`+req.method+`",`+req.series+`}`) {
+			t.Errorf("Expected a %s %s series with %s", req.method, req.path, req.series)
+		}
+	}
+	if strings.Contains(scraped, "7218d21c") {
+		t.Error("The IDs in the path should not be labels")
+	}
+}
@@ -2,52 +2,43 @@ package middleware
 
 import (
 	"compress/gzip"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"strings"
 
-	"github.com/BassemHalim/memesHub/internal/utils"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/BassemHalim/memesHub/internal/apierror"
+	"github.com/BassemHalim/memesHub/internal/auth"
 )
 
-func Auth(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Check if the request has the correct authorization header
-		authHeader := r.Header.Get("Authorization")
-		authType := strings.Split(authHeader, " ")[0]
-		if authHeader == "" || authType != "Bearer" {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-			return
-		}
-		authToken := strings.Split(authHeader, " ")[1]
-		token, err := jwt.Parse(authToken, func(token *jwt.Token) (interface{}, error) {
-			// Don't forget to validate the alg is what you expect:
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			key := utils.GetEnvOrDefault("JWT_SECRET", "use-openssl-rand--base64-128")
-			if len(key) < 100 {
-				fmt.Println("=========================NO SECRET KEY PROVIDED a placeholder is used being used =========================")
+// Auth only lets through requests with an admin token signed with jwtSecret
+func Auth(jwtSecret string, log *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Check if the request has the correct authorization header
+			authHeader := r.Header.Get("Authorization")
+			authType := strings.Split(authHeader, " ")[0]
+			if authHeader == "" || authType != "Bearer" || !strings.Contains(authHeader, " ") {
+				log.WarnContext(r.Context(), "Unauthorized request", "Reason", "missing bearer token")
+				apierror.Write(w, r, http.StatusUnauthorized, "Unauthorized", nil)
+				return
 			}
-			return []byte(key), nil
-		})
-		if err != nil || !token.Valid {
-			fmt.Println("Unauthorized request", "Error", err, token)
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-			return
-		}
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
-			if claims["role"] != "admin" {
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			authToken := strings.Split(authHeader, " ")[1]
+			if err := auth.ValidateAdminToken(jwtSecret, authToken); err != nil {
+				reason := "invalid token"
+				if errors.Is(err, auth.ErrNotAdmin) {
+					reason = "not an admin token"
+				}
+				log.WarnContext(r.Context(), "Unauthorized request", "Reason", reason, "ERROR", err)
+				apierror.Write(w, r, http.StatusUnauthorized, "Unauthorized", nil)
 				return
 			}
-		}
 
-		next.ServeHTTP(w, r)
-	})
+			next.ServeHTTP(w, r)
+		})
+	}
 }
 
 func CORS(next http.Handler) http.Handler {
@@ -57,6 +48,7 @@ func CORS(next http.Handler) http.Handler {
 		w.Header().Set("Vary", "Origin")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS, POST, PATCH, PUT")
 		w.Header().Set("Access-Control-Allow-Headers", "*")
+		w.Header().Set("Access-Control-Expose-Headers", requestIDHeader)
 
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusNoContent)
@@ -112,13 +104,6 @@ func Cache(next http.Handler, mins uint32) http.Handler {
 	})
 }
 
-func Logger(next http.Handler, log *slog.Logger) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		log.Debug("Request", "r", r.Header)
-		next.ServeHTTP(w, r)
-	})
-}
-
 // ValidateBrowserRequest validates that the request comes from a legitimate browser
 // by checking User-Agent and Referer/Origin headers to prevent curl-based abuse
 // that's a naive way to reduce requests from bots but will use it for now
@@ -128,7 +113,7 @@ func ValidateBrowserRequest(allowedDomains []string) func(http.Handler) http.Han
 			// Check for User-Agent header with browser signature
 			userAgent := r.Header.Get("User-Agent")
 			if userAgent == "" || !isBrowserUserAgent(userAgent) {
-				http.Error(w, "Forbidden: Invalid request source", http.StatusForbidden)
+				apierror.Write(w, r, http.StatusForbidden, "Forbidden: Invalid request source", nil)
 				return
 			}
 
@@ -137,7 +122,7 @@ func ValidateBrowserRequest(allowedDomains []string) func(http.Handler) http.Han
 			origin := r.Header.Get("Origin")
 
 			if !isValidDomain(referer, origin, allowedDomains) {
-				http.Error(w, "Forbidden: Invalid request source", http.StatusForbidden)
+				apierror.Write(w, r, http.StatusForbidden, "Forbidden: Invalid request source", nil)
 				return
 			}
 
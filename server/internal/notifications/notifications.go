@@ -8,34 +8,19 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
-	"strconv"
 	"strings"
 )
 
-var API_URL string 
-var CHAT_ID int64  
-var log *slog.Logger
-func init() {
-	log = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})).With("Service", "NOTIFICATION_SERVICE")
-	host_url, found:= os.LookupEnv("NOTIFICATION_SERVICE_HOST")
-	if !found {
-		fmt.Println("NOTIFICATION_SERVICE_HOST environment variable not set")
-		return
-	}
-	API_URL = host_url
-	chat_id, found := os.LookupEnv("TELEGRAM_CHAT_ID")
-	if !found {
-		fmt.Println("TELEGRAM_CHAT_ID environment variable not set")
-		return
-	}
-	int_chat_id, err := strconv.ParseInt(chat_id, 10, 64)
-	if err != nil {
-		fmt.Printf("Error parsing TELEGRAM_CHAT_ID: %v\n", err)
-		return
-	}
-	CHAT_ID = int_chat_id
+var API_URL string
+var CHAT_ID int64
+var log = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})).With("Service", "NOTIFICATION_SERVICE")
 
+// Configure sets where new meme notifications are sent, they are disabled while apiURL is empty
+func Configure(apiURL string, chatID int64) {
+	API_URL = apiURL
+	CHAT_ID = chatID
 }
+
 type Meme struct {
 	Id       string
 	MediaUrl string
@@ -51,6 +36,9 @@ type sendPhotoPayload struct {
 }
 
 func NewMeme(meme Meme) error {
+	if API_URL == "" {
+		return nil
+	}
 	log.Info("New Meme Notification", "MemeID", meme.Id, "Name", meme.Name, "Tags", meme.Tags, "MediaUrl", meme.MediaUrl)
 	method := "POST"
 	payload := sendPhotoPayload{
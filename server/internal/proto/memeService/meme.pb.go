@@ -75,6 +75,149 @@ func (SortOrder) EnumDescriptor() ([]byte, []int) {
 	return file_meme_proto_rawDescGZIP(), []int{0}
 }
 
+// order of the search results, relevance blends the text rank with engagement and recency
+type SearchSort int32
+
+const (
+	SearchSort_SEARCH_RELEVANCE       SearchSort = 0
+	SearchSort_SEARCH_NEWEST          SearchSort = 1
+	SearchSort_SEARCH_MOST_DOWNLOADED SearchSort = 2
+)
+
+// Enum value maps for SearchSort.
+var (
+	SearchSort_name = map[int32]string{
+		0: "SEARCH_RELEVANCE",
+		1: "SEARCH_NEWEST",
+		2: "SEARCH_MOST_DOWNLOADED",
+	}
+	SearchSort_value = map[string]int32{
+		"SEARCH_RELEVANCE":       0,
+		"SEARCH_NEWEST":          1,
+		"SEARCH_MOST_DOWNLOADED": 2,
+	}
+)
+
+func (x SearchSort) Enum() *SearchSort {
+	p := new(SearchSort)
+	*p = x
+	return p
+}
+
+func (x SearchSort) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SearchSort) Descriptor() protoreflect.EnumDescriptor {
+	return file_meme_proto_enumTypes[1].Descriptor()
+}
+
+func (SearchSort) Type() protoreflect.EnumType {
+	return &file_meme_proto_enumTypes[1]
+}
+
+func (x SearchSort) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SearchSort.Descriptor instead.
+func (SearchSort) EnumDescriptor() ([]byte, []int) {
+	return file_meme_proto_rawDescGZIP(), []int{1}
+}
+
+// whether a timeline meme needs any or all of the requested tags
+type TagMatch int32
+
+const (
+	TagMatch_ANY_TAG  TagMatch = 0
+	TagMatch_ALL_TAGS TagMatch = 1
+)
+
+// Enum value maps for TagMatch.
+var (
+	TagMatch_name = map[int32]string{
+		0: "ANY_TAG",
+		1: "ALL_TAGS",
+	}
+	TagMatch_value = map[string]int32{
+		"ANY_TAG":  0,
+		"ALL_TAGS": 1,
+	}
+)
+
+func (x TagMatch) Enum() *TagMatch {
+	p := new(TagMatch)
+	*p = x
+	return p
+}
+
+func (x TagMatch) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TagMatch) Descriptor() protoreflect.EnumDescriptor {
+	return file_meme_proto_enumTypes[2].Descriptor()
+}
+
+func (TagMatch) Type() protoreflect.EnumType {
+	return &file_meme_proto_enumTypes[2]
+}
+
+func (x TagMatch) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TagMatch.Descriptor instead.
+func (TagMatch) EnumDescriptor() ([]byte, []int) {
+	return file_meme_proto_rawDescGZIP(), []int{2}
+}
+
+type CompletionKind int32
+
+const (
+	CompletionKind_COMPLETION_TAG  CompletionKind = 0
+	CompletionKind_COMPLETION_MEME CompletionKind = 1
+)
+
+// Enum value maps for CompletionKind.
+var (
+	CompletionKind_name = map[int32]string{
+		0: "COMPLETION_TAG",
+		1: "COMPLETION_MEME",
+	}
+	CompletionKind_value = map[string]int32{
+		"COMPLETION_TAG":  0,
+		"COMPLETION_MEME": 1,
+	}
+)
+
+func (x CompletionKind) Enum() *CompletionKind {
+	p := new(CompletionKind)
+	*p = x
+	return p
+}
+
+func (x CompletionKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CompletionKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_meme_proto_enumTypes[3].Descriptor()
+}
+
+func (CompletionKind) Type() protoreflect.EnumType {
+	return &file_meme_proto_enumTypes[3]
+}
+
+func (x CompletionKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CompletionKind.Descriptor instead.
+func (CompletionKind) EnumDescriptor() ([]byte, []int) {
+	return file_meme_proto_rawDescGZIP(), []int{3}
+}
+
 type UploadMemeRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -86,6 +229,7 @@ type UploadMemeRequest struct {
 	Name           string   `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
 	Dimensions     []int32  `protobuf:"varint,5,rep,packed,name=dimensions,proto3" json:"dimensions,omitempty"`
 	SocialMediaUrl string   `protobuf:"bytes,6,opt,name=social_media_url,json=socialMediaUrl,proto3" json:"social_media_url,omitempty"` // optional filed to store the uploaded image URL
+	PostUrl        string   `protobuf:"bytes,7,opt,name=post_url,json=postUrl,proto3" json:"post_url,omitempty"`                        // optional social media post the image was resolved from
 }
 
 func (x *UploadMemeRequest) Reset() {
@@ -160,6 +304,13 @@ func (x *UploadMemeRequest) GetSocialMediaUrl() string {
 	return ""
 }
 
+func (x *UploadMemeRequest) GetPostUrl() string {
+	if x != nil {
+		return x.PostUrl
+	}
+	return ""
+}
+
 type UpdateMemeRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -172,6 +323,7 @@ type UpdateMemeRequest struct {
 	Name           string   `protobuf:"bytes,5,opt,name=name,proto3" json:"name,omitempty"`
 	Dimensions     []int32  `protobuf:"varint,6,rep,packed,name=dimensions,proto3" json:"dimensions,omitempty"`
 	SocialMediaUrl string   `protobuf:"bytes,7,opt,name=social_media_url,json=socialMediaUrl,proto3" json:"social_media_url,omitempty"` // optional filed to store the uploaded image URL
+	PostUrl        string   `protobuf:"bytes,8,opt,name=post_url,json=postUrl,proto3" json:"post_url,omitempty"`                        // optional social media post the image was resolved from
 }
 
 func (x *UpdateMemeRequest) Reset() {
@@ -253,6 +405,13 @@ func (x *UpdateMemeRequest) GetSocialMediaUrl() string {
 	return ""
 }
 
+func (x *UpdateMemeRequest) GetPostUrl() string {
+	if x != nil {
+		return x.PostUrl
+	}
+	return ""
+}
+
 type GetMemeRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -351,6 +510,13 @@ type GetTimelineRequest struct {
 	Page      int32     `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
 	PageSize  int32     `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
 	SortOrder SortOrder `protobuf:"varint,3,opt,name=sort_order,json=sortOrder,proto3,enum=meme.SortOrder" json:"sort_order,omitempty"`
+	// only the memes with these tags, see tag_match
+	Tags []string `protobuf:"bytes,4,rep,name=tags,proto3" json:"tags,omitempty"`
+	// never the memes with any of these tags
+	ExcludeTags []string `protobuf:"bytes,5,rep,name=exclude_tags,json=excludeTags,proto3" json:"exclude_tags,omitempty"`
+	TagMatch    TagMatch `protobuf:"varint,6,opt,name=tag_match,json=tagMatch,proto3,enum=meme.TagMatch" json:"tag_match,omitempty"`
+	// only the memes of this mime type, e.g. image/gif
+	MediaType string `protobuf:"bytes,7,opt,name=media_type,json=mediaType,proto3" json:"media_type,omitempty"`
 }
 
 func (x *GetTimelineRequest) Reset() {
@@ -404,14 +570,43 @@ func (x *GetTimelineRequest) GetSortOrder() SortOrder {
 	return SortOrder_NEWEST
 }
 
+func (x *GetTimelineRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *GetTimelineRequest) GetExcludeTags() []string {
+	if x != nil {
+		return x.ExcludeTags
+	}
+	return nil
+}
+
+func (x *GetTimelineRequest) GetTagMatch() TagMatch {
+	if x != nil {
+		return x.TagMatch
+	}
+	return TagMatch_ANY_TAG
+}
+
+func (x *GetTimelineRequest) GetMediaType() string {
+	if x != nil {
+		return x.MediaType
+	}
+	return ""
+}
+
 type SearchMemesRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Query    string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
-	Page     int32  `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
-	PageSize int32  `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Query    string     `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Page     int32      `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize int32      `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Sort     SearchSort `protobuf:"varint,5,opt,name=sort,proto3,enum=meme.SearchSort" json:"sort,omitempty"`
 }
 
 func (x *SearchMemesRequest) Reset() {
@@ -465,6 +660,13 @@ func (x *SearchMemesRequest) GetPageSize() int32 {
 	return 0
 }
 
+func (x *SearchMemesRequest) GetSort() SearchSort {
+	if x != nil {
+		return x.Sort
+	}
+	return SearchSort_SEARCH_RELEVANCE
+}
+
 type SearchTagsRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -576,6 +778,9 @@ type AddTagsResponse struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
+	// used to hold an HTTP status, failures are now returned as a gRPC status
+	//
+	// Deprecated: Marked as deprecated in meme.proto.
 	Success int32 `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 }
 
@@ -609,6 +814,7 @@ func (*AddTagsResponse) Descriptor() ([]byte, []int) {
 	return file_meme_proto_rawDescGZIP(), []int{8}
 }
 
+// Deprecated: Marked as deprecated in meme.proto.
 func (x *AddTagsResponse) GetSuccess() int32 {
 	if x != nil {
 		return x.Success
@@ -661,6 +867,158 @@ func (x *TagsResponse) GetTags() []string {
 	return nil
 }
 
+type AutocompleteRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Prefix string `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	Limit  int32  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+}
+
+func (x *AutocompleteRequest) Reset() {
+	*x = AutocompleteRequest{}
+	mi := &file_meme_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AutocompleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AutocompleteRequest) ProtoMessage() {}
+
+func (x *AutocompleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_meme_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AutocompleteRequest.ProtoReflect.Descriptor instead.
+func (*AutocompleteRequest) Descriptor() ([]byte, []int) {
+	return file_meme_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *AutocompleteRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *AutocompleteRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// a tag or meme name starting with the prefix
+type Completion struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Text string         `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	Kind CompletionKind `protobuf:"varint,2,opt,name=kind,proto3,enum=meme.CompletionKind" json:"kind,omitempty"`
+}
+
+func (x *Completion) Reset() {
+	*x = Completion{}
+	mi := &file_meme_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Completion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Completion) ProtoMessage() {}
+
+func (x *Completion) ProtoReflect() protoreflect.Message {
+	mi := &file_meme_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Completion.ProtoReflect.Descriptor instead.
+func (*Completion) Descriptor() ([]byte, []int) {
+	return file_meme_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Completion) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Completion) GetKind() CompletionKind {
+	if x != nil {
+		return x.Kind
+	}
+	return CompletionKind_COMPLETION_TAG
+}
+
+type AutocompleteResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Completions []*Completion `protobuf:"bytes,1,rep,name=completions,proto3" json:"completions,omitempty"`
+}
+
+func (x *AutocompleteResponse) Reset() {
+	*x = AutocompleteResponse{}
+	mi := &file_meme_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AutocompleteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AutocompleteResponse) ProtoMessage() {}
+
+func (x *AutocompleteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_meme_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AutocompleteResponse.ProtoReflect.Descriptor instead.
+func (*AutocompleteResponse) Descriptor() ([]byte, []int) {
+	return file_meme_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *AutocompleteResponse) GetCompletions() []*Completion {
+	if x != nil {
+		return x.Completions
+	}
+	return nil
+}
+
 type IncrementEngagementRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -671,7 +1029,7 @@ type IncrementEngagementRequest struct {
 
 func (x *IncrementEngagementRequest) Reset() {
 	*x = IncrementEngagementRequest{}
-	mi := &file_meme_proto_msgTypes[10]
+	mi := &file_meme_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -683,7 +1041,7 @@ func (x *IncrementEngagementRequest) String() string {
 func (*IncrementEngagementRequest) ProtoMessage() {}
 
 func (x *IncrementEngagementRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_meme_proto_msgTypes[10]
+	mi := &file_meme_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -696,7 +1054,7 @@ func (x *IncrementEngagementRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use IncrementEngagementRequest.ProtoReflect.Descriptor instead.
 func (*IncrementEngagementRequest) Descriptor() ([]byte, []int) {
-	return file_meme_proto_rawDescGZIP(), []int{10}
+	return file_meme_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *IncrementEngagementRequest) GetMemeId() string {
@@ -717,7 +1075,7 @@ type IncrementEngagementResponse struct {
 
 func (x *IncrementEngagementResponse) Reset() {
 	*x = IncrementEngagementResponse{}
-	mi := &file_meme_proto_msgTypes[11]
+	mi := &file_meme_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -729,7 +1087,7 @@ func (x *IncrementEngagementResponse) String() string {
 func (*IncrementEngagementResponse) ProtoMessage() {}
 
 func (x *IncrementEngagementResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_meme_proto_msgTypes[11]
+	mi := &file_meme_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -742,7 +1100,7 @@ func (x *IncrementEngagementResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use IncrementEngagementResponse.ProtoReflect.Descriptor instead.
 func (*IncrementEngagementResponse) Descriptor() ([]byte, []int) {
-	return file_meme_proto_rawDescGZIP(), []int{11}
+	return file_meme_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *IncrementEngagementResponse) GetSuccess() bool {
@@ -770,7 +1128,7 @@ type GetPendingMemesRequest struct {
 
 func (x *GetPendingMemesRequest) Reset() {
 	*x = GetPendingMemesRequest{}
-	mi := &file_meme_proto_msgTypes[12]
+	mi := &file_meme_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -782,7 +1140,7 @@ func (x *GetPendingMemesRequest) String() string {
 func (*GetPendingMemesRequest) ProtoMessage() {}
 
 func (x *GetPendingMemesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_meme_proto_msgTypes[12]
+	mi := &file_meme_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -795,7 +1153,7 @@ func (x *GetPendingMemesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetPendingMemesRequest.ProtoReflect.Descriptor instead.
 func (*GetPendingMemesRequest) Descriptor() ([]byte, []int) {
-	return file_meme_proto_rawDescGZIP(), []int{12}
+	return file_meme_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *GetPendingMemesRequest) GetPage() int32 {
@@ -822,7 +1180,7 @@ type ApproveMemeRequest struct {
 
 func (x *ApproveMemeRequest) Reset() {
 	*x = ApproveMemeRequest{}
-	mi := &file_meme_proto_msgTypes[13]
+	mi := &file_meme_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -834,7 +1192,7 @@ func (x *ApproveMemeRequest) String() string {
 func (*ApproveMemeRequest) ProtoMessage() {}
 
 func (x *ApproveMemeRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_meme_proto_msgTypes[13]
+	mi := &file_meme_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -847,7 +1205,7 @@ func (x *ApproveMemeRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ApproveMemeRequest.ProtoReflect.Descriptor instead.
 func (*ApproveMemeRequest) Descriptor() ([]byte, []int) {
-	return file_meme_proto_rawDescGZIP(), []int{13}
+	return file_meme_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *ApproveMemeRequest) GetMemeId() string {
@@ -868,7 +1226,7 @@ type ApproveMemeResponse struct {
 
 func (x *ApproveMemeResponse) Reset() {
 	*x = ApproveMemeResponse{}
-	mi := &file_meme_proto_msgTypes[14]
+	mi := &file_meme_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -880,7 +1238,7 @@ func (x *ApproveMemeResponse) String() string {
 func (*ApproveMemeResponse) ProtoMessage() {}
 
 func (x *ApproveMemeResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_meme_proto_msgTypes[14]
+	mi := &file_meme_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -893,7 +1251,7 @@ func (x *ApproveMemeResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ApproveMemeResponse.ProtoReflect.Descriptor instead.
 func (*ApproveMemeResponse) Descriptor() ([]byte, []int) {
-	return file_meme_proto_rawDescGZIP(), []int{14}
+	return file_meme_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *ApproveMemeResponse) GetSuccess() bool {
@@ -920,7 +1278,7 @@ type UnapproveMemeRequest struct {
 
 func (x *UnapproveMemeRequest) Reset() {
 	*x = UnapproveMemeRequest{}
-	mi := &file_meme_proto_msgTypes[15]
+	mi := &file_meme_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -932,7 +1290,7 @@ func (x *UnapproveMemeRequest) String() string {
 func (*UnapproveMemeRequest) ProtoMessage() {}
 
 func (x *UnapproveMemeRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_meme_proto_msgTypes[15]
+	mi := &file_meme_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -945,7 +1303,7 @@ func (x *UnapproveMemeRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UnapproveMemeRequest.ProtoReflect.Descriptor instead.
 func (*UnapproveMemeRequest) Descriptor() ([]byte, []int) {
-	return file_meme_proto_rawDescGZIP(), []int{15}
+	return file_meme_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *UnapproveMemeRequest) GetMemeId() string {
@@ -966,7 +1324,7 @@ type UnapproveMemeResponse struct {
 
 func (x *UnapproveMemeResponse) Reset() {
 	*x = UnapproveMemeResponse{}
-	mi := &file_meme_proto_msgTypes[16]
+	mi := &file_meme_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -978,7 +1336,7 @@ func (x *UnapproveMemeResponse) String() string {
 func (*UnapproveMemeResponse) ProtoMessage() {}
 
 func (x *UnapproveMemeResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_meme_proto_msgTypes[16]
+	mi := &file_meme_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -991,7 +1349,7 @@ func (x *UnapproveMemeResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UnapproveMemeResponse.ProtoReflect.Descriptor instead.
 func (*UnapproveMemeResponse) Descriptor() ([]byte, []int) {
-	return file_meme_proto_rawDescGZIP(), []int{16}
+	return file_meme_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *UnapproveMemeResponse) GetSuccess() bool {
@@ -1008,25 +1366,459 @@ func (x *UnapproveMemeResponse) GetError() string {
 	return ""
 }
 
+type GetDeletedMemesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Page     int32 `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize int32 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+}
+
+func (x *GetDeletedMemesRequest) Reset() {
+	*x = GetDeletedMemesRequest{}
+	mi := &file_meme_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDeletedMemesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeletedMemesRequest) ProtoMessage() {}
+
+func (x *GetDeletedMemesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_meme_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeletedMemesRequest.ProtoReflect.Descriptor instead.
+func (*GetDeletedMemesRequest) Descriptor() ([]byte, []int) {
+	return file_meme_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetDeletedMemesRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *GetDeletedMemesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type RestoreMemeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MemeId string `protobuf:"bytes,1,opt,name=meme_id,json=memeId,proto3" json:"meme_id,omitempty"`
+}
+
+func (x *RestoreMemeRequest) Reset() {
+	*x = RestoreMemeRequest{}
+	mi := &file_meme_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreMemeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreMemeRequest) ProtoMessage() {}
+
+func (x *RestoreMemeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_meme_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreMemeRequest.ProtoReflect.Descriptor instead.
+func (*RestoreMemeRequest) Descriptor() ([]byte, []int) {
+	return file_meme_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *RestoreMemeRequest) GetMemeId() string {
+	if x != nil {
+		return x.MemeId
+	}
+	return ""
+}
+
+type RestoreMemeResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Success bool   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error   string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *RestoreMemeResponse) Reset() {
+	*x = RestoreMemeResponse{}
+	mi := &file_meme_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreMemeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreMemeResponse) ProtoMessage() {}
+
+func (x *RestoreMemeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_meme_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreMemeResponse.ProtoReflect.Descriptor instead.
+func (*RestoreMemeResponse) Descriptor() ([]byte, []int) {
+	return file_meme_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *RestoreMemeResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *RestoreMemeResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type PurgeDeletedMemesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	OlderThanDays int32 `protobuf:"varint,1,opt,name=older_than_days,json=olderThanDays,proto3" json:"older_than_days,omitempty"` // memes deleted more than this many days ago are removed permanently
+}
+
+func (x *PurgeDeletedMemesRequest) Reset() {
+	*x = PurgeDeletedMemesRequest{}
+	mi := &file_meme_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurgeDeletedMemesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurgeDeletedMemesRequest) ProtoMessage() {}
+
+func (x *PurgeDeletedMemesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_meme_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurgeDeletedMemesRequest.ProtoReflect.Descriptor instead.
+func (*PurgeDeletedMemesRequest) Descriptor() ([]byte, []int) {
+	return file_meme_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *PurgeDeletedMemesRequest) GetOlderThanDays() int32 {
+	if x != nil {
+		return x.OlderThanDays
+	}
+	return 0
+}
+
+type PurgeDeletedMemesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Purged int32 `protobuf:"varint,1,opt,name=purged,proto3" json:"purged,omitempty"`
+}
+
+func (x *PurgeDeletedMemesResponse) Reset() {
+	*x = PurgeDeletedMemesResponse{}
+	mi := &file_meme_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurgeDeletedMemesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurgeDeletedMemesResponse) ProtoMessage() {}
+
+func (x *PurgeDeletedMemesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_meme_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurgeDeletedMemesResponse.ProtoReflect.Descriptor instead.
+func (*PurgeDeletedMemesResponse) Descriptor() ([]byte, []int) {
+	return file_meme_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *PurgeDeletedMemesResponse) GetPurged() int32 {
+	if x != nil {
+		return x.Purged
+	}
+	return 0
+}
+
+type CreateUploadSlotRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MediaType string `protobuf:"bytes,1,opt,name=media_type,json=mediaType,proto3" json:"media_type,omitempty"`
+	Size      int64  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"` // exact size in bytes of the image that will be uploaded
+}
+
+func (x *CreateUploadSlotRequest) Reset() {
+	*x = CreateUploadSlotRequest{}
+	mi := &file_meme_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateUploadSlotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateUploadSlotRequest) ProtoMessage() {}
+
+func (x *CreateUploadSlotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_meme_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateUploadSlotRequest.ProtoReflect.Descriptor instead.
+func (*CreateUploadSlotRequest) Descriptor() ([]byte, []int) {
+	return file_meme_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *CreateUploadSlotRequest) GetMediaType() string {
+	if x != nil {
+		return x.MediaType
+	}
+	return ""
+}
+
+func (x *CreateUploadSlotRequest) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type UploadSlotResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id        string            `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UploadUrl string            `protobuf:"bytes,2,opt,name=upload_url,json=uploadUrl,proto3" json:"upload_url,omitempty"`
+	Method    string            `protobuf:"bytes,3,opt,name=method,proto3" json:"method,omitempty"`
+	Headers   map[string]string `protobuf:"bytes,4,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"` // headers the upload request must send as is
+	ExpiresAt string            `protobuf:"bytes,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+}
+
+func (x *UploadSlotResponse) Reset() {
+	*x = UploadSlotResponse{}
+	mi := &file_meme_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadSlotResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadSlotResponse) ProtoMessage() {}
+
+func (x *UploadSlotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_meme_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadSlotResponse.ProtoReflect.Descriptor instead.
+func (*UploadSlotResponse) Descriptor() ([]byte, []int) {
+	return file_meme_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *UploadSlotResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UploadSlotResponse) GetUploadUrl() string {
+	if x != nil {
+		return x.UploadUrl
+	}
+	return ""
+}
+
+func (x *UploadSlotResponse) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *UploadSlotResponse) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *UploadSlotResponse) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
+type FinalizeUploadRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SlotId string   `protobuf:"bytes,1,opt,name=slot_id,json=slotId,proto3" json:"slot_id,omitempty"`
+	Name   string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Tags   []string `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+}
+
+func (x *FinalizeUploadRequest) Reset() {
+	*x = FinalizeUploadRequest{}
+	mi := &file_meme_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FinalizeUploadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FinalizeUploadRequest) ProtoMessage() {}
+
+func (x *FinalizeUploadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_meme_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FinalizeUploadRequest.ProtoReflect.Descriptor instead.
+func (*FinalizeUploadRequest) Descriptor() ([]byte, []int) {
+	return file_meme_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *FinalizeUploadRequest) GetSlotId() string {
+	if x != nil {
+		return x.SlotId
+	}
+	return ""
+}
+
+func (x *FinalizeUploadRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FinalizeUploadRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
 // Response messages
 type MemeResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id            string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	MediaUrl      string   `protobuf:"bytes,2,opt,name=media_url,json=mediaUrl,proto3" json:"media_url,omitempty"`
-	MediaType     string   `protobuf:"bytes,3,opt,name=media_type,json=mediaType,proto3" json:"media_type,omitempty"`
-	Name          string   `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
-	Tags          []string `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`
-	Dimensions    []int32  `protobuf:"varint,6,rep,packed,name=dimensions,proto3" json:"dimensions,omitempty"`
-	DownloadCount int32    `protobuf:"varint,7,opt,name=download_count,json=downloadCount,proto3" json:"download_count,omitempty"`
-	ShareCount    int32    `protobuf:"varint,8,opt,name=share_count,json=shareCount,proto3" json:"share_count,omitempty"`
+	Id            string      `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	MediaUrl      string      `protobuf:"bytes,2,opt,name=media_url,json=mediaUrl,proto3" json:"media_url,omitempty"`
+	MediaType     string      `protobuf:"bytes,3,opt,name=media_type,json=mediaType,proto3" json:"media_type,omitempty"`
+	Name          string      `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	Tags          []string    `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`
+	Dimensions    []int32     `protobuf:"varint,6,rep,packed,name=dimensions,proto3" json:"dimensions,omitempty"`
+	DownloadCount int32       `protobuf:"varint,7,opt,name=download_count,json=downloadCount,proto3" json:"download_count,omitempty"`
+	ShareCount    int32       `protobuf:"varint,8,opt,name=share_count,json=shareCount,proto3" json:"share_count,omitempty"`
+	DeletedAt     string      `protobuf:"bytes,9,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"` // only set for memes in the trash
+	Source        *MemeSource `protobuf:"bytes,10,opt,name=source,proto3" json:"source,omitempty"`                       // only set by GetMeme for memes with a known source
 }
 
 func (x *MemeResponse) Reset() {
 	*x = MemeResponse{}
-	mi := &file_meme_proto_msgTypes[17]
+	mi := &file_meme_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1038,7 +1830,7 @@ func (x *MemeResponse) String() string {
 func (*MemeResponse) ProtoMessage() {}
 
 func (x *MemeResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_meme_proto_msgTypes[17]
+	mi := &file_meme_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1051,7 +1843,7 @@ func (x *MemeResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MemeResponse.ProtoReflect.Descriptor instead.
 func (*MemeResponse) Descriptor() ([]byte, []int) {
-	return file_meme_proto_rawDescGZIP(), []int{17}
+	return file_meme_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *MemeResponse) GetId() string {
@@ -1110,6 +1902,188 @@ func (x *MemeResponse) GetShareCount() int32 {
 	return 0
 }
 
+func (x *MemeResponse) GetDeletedAt() string {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return ""
+}
+
+func (x *MemeResponse) GetSource() *MemeSource {
+	if x != nil {
+		return x.Source
+	}
+	return nil
+}
+
+// where a meme was found
+type MemeSource struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Platform string `protobuf:"bytes,1,opt,name=platform,proto3" json:"platform,omitempty"`              // one of FB, X, Instagram, LinkedIn, Pinterest, Reddit, Imgur, Other
+	Url      string `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`                        // the URL the image was downloaded from
+	PostUrl  string `protobuf:"bytes,3,opt,name=post_url,json=postUrl,proto3" json:"post_url,omitempty"` // the social media post the image was posted in
+	Author   string `protobuf:"bytes,4,opt,name=author,proto3" json:"author,omitempty"`                  // handle of the account that posted it
+}
+
+func (x *MemeSource) Reset() {
+	*x = MemeSource{}
+	mi := &file_meme_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MemeSource) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MemeSource) ProtoMessage() {}
+
+func (x *MemeSource) ProtoReflect() protoreflect.Message {
+	mi := &file_meme_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MemeSource.ProtoReflect.Descriptor instead.
+func (*MemeSource) Descriptor() ([]byte, []int) {
+	return file_meme_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *MemeSource) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+func (x *MemeSource) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *MemeSource) GetPostUrl() string {
+	if x != nil {
+		return x.PostUrl
+	}
+	return ""
+}
+
+func (x *MemeSource) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+type UpdateMemeSourceRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id     string      `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Source *MemeSource `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"` // the platform is detected from the URLs when empty
+}
+
+func (x *UpdateMemeSourceRequest) Reset() {
+	*x = UpdateMemeSourceRequest{}
+	mi := &file_meme_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateMemeSourceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateMemeSourceRequest) ProtoMessage() {}
+
+func (x *UpdateMemeSourceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_meme_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateMemeSourceRequest.ProtoReflect.Descriptor instead.
+func (*UpdateMemeSourceRequest) Descriptor() ([]byte, []int) {
+	return file_meme_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *UpdateMemeSourceRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateMemeSourceRequest) GetSource() *MemeSource {
+	if x != nil {
+		return x.Source
+	}
+	return nil
+}
+
+type UpdateMemeSourceResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Source *MemeSource `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+}
+
+func (x *UpdateMemeSourceResponse) Reset() {
+	*x = UpdateMemeSourceResponse{}
+	mi := &file_meme_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateMemeSourceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateMemeSourceResponse) ProtoMessage() {}
+
+func (x *UpdateMemeSourceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_meme_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateMemeSourceResponse.ProtoReflect.Descriptor instead.
+func (*UpdateMemeSourceResponse) Descriptor() ([]byte, []int) {
+	return file_meme_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *UpdateMemeSourceResponse) GetSource() *MemeSource {
+	if x != nil {
+		return x.Source
+	}
+	return nil
+}
+
 type DeleteMemeResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -1120,7 +2094,7 @@ type DeleteMemeResponse struct {
 
 func (x *DeleteMemeResponse) Reset() {
 	*x = DeleteMemeResponse{}
-	mi := &file_meme_proto_msgTypes[18]
+	mi := &file_meme_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1132,7 +2106,7 @@ func (x *DeleteMemeResponse) String() string {
 func (*DeleteMemeResponse) ProtoMessage() {}
 
 func (x *DeleteMemeResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_meme_proto_msgTypes[18]
+	mi := &file_meme_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1145,7 +2119,7 @@ func (x *DeleteMemeResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteMemeResponse.ProtoReflect.Descriptor instead.
 func (*DeleteMemeResponse) Descriptor() ([]byte, []int) {
-	return file_meme_proto_rawDescGZIP(), []int{18}
+	return file_meme_proto_rawDescGZIP(), []int{32}
 }
 
 func (x *DeleteMemeResponse) GetSuccess() bool {
@@ -1165,7 +2139,7 @@ type UpdateMemeResponse struct {
 
 func (x *UpdateMemeResponse) Reset() {
 	*x = UpdateMemeResponse{}
-	mi := &file_meme_proto_msgTypes[19]
+	mi := &file_meme_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1177,7 +2151,7 @@ func (x *UpdateMemeResponse) String() string {
 func (*UpdateMemeResponse) ProtoMessage() {}
 
 func (x *UpdateMemeResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_meme_proto_msgTypes[19]
+	mi := &file_meme_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1190,7 +2164,7 @@ func (x *UpdateMemeResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateMemeResponse.ProtoReflect.Descriptor instead.
 func (*UpdateMemeResponse) Descriptor() ([]byte, []int) {
-	return file_meme_proto_rawDescGZIP(), []int{19}
+	return file_meme_proto_rawDescGZIP(), []int{33}
 }
 
 func (x *UpdateMemeResponse) GetSuccess() bool {
@@ -1209,11 +2183,15 @@ type MemesResponse struct {
 	TotalCount int32           `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
 	Page       int32           `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
 	TotalPages int32           `protobuf:"varint,4,opt,name=total_pages,json=totalPages,proto3" json:"total_pages,omitempty"`
+	// only set by SearchMemes when it finds few memes, tag and meme names close to the query
+	Suggestions []string `protobuf:"bytes,5,rep,name=suggestions,proto3" json:"suggestions,omitempty"`
+	// only set by SearchMemes when it finds few memes, popular tags of memes like the query
+	RelatedTags []string `protobuf:"bytes,6,rep,name=related_tags,json=relatedTags,proto3" json:"related_tags,omitempty"`
 }
 
 func (x *MemesResponse) Reset() {
 	*x = MemesResponse{}
-	mi := &file_meme_proto_msgTypes[20]
+	mi := &file_meme_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1225,7 +2203,7 @@ func (x *MemesResponse) String() string {
 func (*MemesResponse) ProtoMessage() {}
 
 func (x *MemesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_meme_proto_msgTypes[20]
+	mi := &file_meme_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1238,7 +2216,7 @@ func (x *MemesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MemesResponse.ProtoReflect.Descriptor instead.
 func (*MemesResponse) Descriptor() ([]byte, []int) {
-	return file_meme_proto_rawDescGZIP(), []int{20}
+	return file_meme_proto_rawDescGZIP(), []int{34}
 }
 
 func (x *MemesResponse) GetMemes() []*MemeResponse {
@@ -1269,11 +2247,25 @@ func (x *MemesResponse) GetTotalPages() int32 {
 	return 0
 }
 
+func (x *MemesResponse) GetSuggestions() []string {
+	if x != nil {
+		return x.Suggestions
+	}
+	return nil
+}
+
+func (x *MemesResponse) GetRelatedTags() []string {
+	if x != nil {
+		return x.RelatedTags
+	}
+	return nil
+}
+
 var File_meme_proto protoreflect.FileDescriptor
 
 var file_meme_proto_rawDesc = []byte{
 	0x0a, 0x0a, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x04, 0x6d, 0x65,
-	0x6d, 0x65, 0x22, 0xba, 0x01, 0x0a, 0x11, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x4d, 0x65, 0x6d,
+	0x6d, 0x65, 0x22, 0xd5, 0x01, 0x0a, 0x11, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x4d, 0x65, 0x6d,
 	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6d, 0x61, 0x67,
 	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x12, 0x1d,
 	0x0a, 0x0a, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01,
@@ -1284,174 +2276,317 @@ var file_meme_proto_rawDesc = []byte{
 	0x6f, 0x6e, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x05, 0x52, 0x0a, 0x64, 0x69, 0x6d, 0x65, 0x6e,
 	0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x28, 0x0a, 0x10, 0x73, 0x6f, 0x63, 0x69, 0x61, 0x6c, 0x5f,
 	0x6d, 0x65, 0x64, 0x69, 0x61, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x0e, 0x73, 0x6f, 0x63, 0x69, 0x61, 0x6c, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x55, 0x72, 0x6c, 0x22,
-	0xca, 0x01, 0x0a, 0x11, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x6d,
-	0x65, 0x64, 0x69, 0x61, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x09, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x54, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61,
-	0x67, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x12,
-	0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61,
-	0x6d, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x64, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73,
-	0x18, 0x06, 0x20, 0x03, 0x28, 0x05, 0x52, 0x0a, 0x64, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f,
-	0x6e, 0x73, 0x12, 0x28, 0x0a, 0x10, 0x73, 0x6f, 0x63, 0x69, 0x61, 0x6c, 0x5f, 0x6d, 0x65, 0x64,
-	0x69, 0x61, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x73, 0x6f,
-	0x63, 0x69, 0x61, 0x6c, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x55, 0x72, 0x6c, 0x22, 0x20, 0x0a, 0x0e,
-	0x47, 0x65, 0x74, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e,
-	0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x23,
-	0x0a, 0x11, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x02, 0x69, 0x64, 0x22, 0x75, 0x0a, 0x12, 0x47, 0x65, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x6c, 0x69,
-	0x6e, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x67,
-	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x70, 0x61, 0x67, 0x65, 0x12, 0x1b, 0x0a,
-	0x09, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05,
-	0x52, 0x08, 0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x2e, 0x0a, 0x0a, 0x73, 0x6f,
-	0x72, 0x74, 0x5f, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0f,
-	0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x53, 0x6f, 0x72, 0x74, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x52,
-	0x09, 0x73, 0x6f, 0x72, 0x74, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x22, 0x5b, 0x0a, 0x12, 0x53, 0x65,
-	0x61, 0x72, 0x63, 0x68, 0x4d, 0x65, 0x6d, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x12, 0x14, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x67, 0x65, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x70, 0x61, 0x67, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61,
-	0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70,
-	0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x22, 0x3f, 0x0a, 0x11, 0x53, 0x65, 0x61, 0x72, 0x63,
-	0x68, 0x54, 0x61, 0x67, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05,
-	0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x71, 0x75, 0x65,
-	0x72, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x05, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0x3d, 0x0a, 0x0e, 0x41, 0x64, 0x64, 0x54,
-	0x61, 0x67, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x6d, 0x65,
-	0x6d, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x65, 0x6d,
-	0x65, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
-	0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x22, 0x2b, 0x0a, 0x0f, 0x41, 0x64, 0x64, 0x54, 0x61,
-	0x67, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75,
-	0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x73, 0x75, 0x63,
-	0x63, 0x65, 0x73, 0x73, 0x22, 0x22, 0x0a, 0x0c, 0x54, 0x61, 0x67, 0x73, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03,
-	0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x22, 0x35, 0x0a, 0x1a, 0x49, 0x6e, 0x63, 0x72,
-	0x65, 0x6d, 0x65, 0x6e, 0x74, 0x45, 0x6e, 0x67, 0x61, 0x67, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x6d, 0x65, 0x6d, 0x65, 0x5f, 0x69,
-	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x65, 0x6d, 0x65, 0x49, 0x64, 0x22,
-	0x4d, 0x0a, 0x1b, 0x49, 0x6e, 0x63, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x45, 0x6e, 0x67, 0x61,
-	0x67, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18,
-	0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52,
-	0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f,
-	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x49,
-	0x0a, 0x16, 0x47, 0x65, 0x74, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x4d, 0x65, 0x6d, 0x65,
-	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x67, 0x65,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x70, 0x61, 0x67, 0x65, 0x12, 0x1b, 0x0a, 0x09,
-	0x70, 0x61, 0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52,
-	0x08, 0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x22, 0x2d, 0x0a, 0x12, 0x41, 0x70, 0x70,
-	0x72, 0x6f, 0x76, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
-	0x17, 0x0a, 0x07, 0x6d, 0x65, 0x6d, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x06, 0x6d, 0x65, 0x6d, 0x65, 0x49, 0x64, 0x22, 0x45, 0x0a, 0x13, 0x41, 0x70, 0x70, 0x72,
-	0x6f, 0x76, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
-	0x18, 0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08,
-	0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72,
-	0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22,
-	0x2f, 0x0a, 0x14, 0x55, 0x6e, 0x61, 0x70, 0x70, 0x72, 0x6f, 0x76, 0x65, 0x4d, 0x65, 0x6d, 0x65,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x6d, 0x65, 0x6d, 0x65, 0x5f,
-	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x65, 0x6d, 0x65, 0x49, 0x64,
-	0x22, 0x47, 0x0a, 0x15, 0x55, 0x6e, 0x61, 0x70, 0x70, 0x72, 0x6f, 0x76, 0x65, 0x4d, 0x65, 0x6d,
-	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63,
-	0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63,
-	0x65, 0x73, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0xea, 0x01, 0x0a, 0x0c, 0x4d, 0x65,
-	0x6d, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x65,
-	0x64, 0x69, 0x61, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6d,
-	0x65, 0x64, 0x69, 0x61, 0x55, 0x72, 0x6c, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x65, 0x64, 0x69, 0x61,
-	0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6d, 0x65, 0x64,
-	0x69, 0x61, 0x54, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x04,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61,
-	0x67, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x1e,
-	0x0a, 0x0a, 0x64, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x06, 0x20, 0x03,
-	0x28, 0x05, 0x52, 0x0a, 0x64, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x25,
-	0x0a, 0x0e, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74,
-	0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64,
-	0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x68, 0x61, 0x72, 0x65, 0x5f, 0x63,
-	0x6f, 0x75, 0x6e, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x73, 0x68, 0x61, 0x72,
-	0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0x2e, 0x0a, 0x12, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
-	0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07,
-	0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73,
-	0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x22, 0x2e, 0x0a, 0x12, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65,
+	0x0e, 0x73, 0x6f, 0x63, 0x69, 0x61, 0x6c, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x55, 0x72, 0x6c, 0x12,
+	0x19, 0x0a, 0x08, 0x70, 0x6f, 0x73, 0x74, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x70, 0x6f, 0x73, 0x74, 0x55, 0x72, 0x6c, 0x22, 0xe5, 0x01, 0x0a, 0x11, 0x55,
+	0x70, 0x64, 0x61, 0x74, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64,
+	0x12, 0x14, 0x0a, 0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x5f,
+	0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6d, 0x65, 0x64, 0x69,
+	0x61, 0x54, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x04, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1e, 0x0a,
+	0x0a, 0x64, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28,
+	0x05, 0x52, 0x0a, 0x64, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x28, 0x0a,
+	0x10, 0x73, 0x6f, 0x63, 0x69, 0x61, 0x6c, 0x5f, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x5f, 0x75, 0x72,
+	0x6c, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x73, 0x6f, 0x63, 0x69, 0x61, 0x6c, 0x4d,
+	0x65, 0x64, 0x69, 0x61, 0x55, 0x72, 0x6c, 0x12, 0x19, 0x0a, 0x08, 0x70, 0x6f, 0x73, 0x74, 0x5f,
+	0x75, 0x72, 0x6c, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x6f, 0x73, 0x74, 0x55,
+	0x72, 0x6c, 0x22, 0x20, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x69, 0x64, 0x22, 0x23, 0x0a, 0x11, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4d, 0x65,
+	0x6d, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0xf8, 0x01, 0x0a, 0x12, 0x47, 0x65,
+	0x74, 0x54, 0x69, 0x6d, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04,
+	0x70, 0x61, 0x67, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a,
+	0x65, 0x12, 0x2e, 0x0a, 0x0a, 0x73, 0x6f, 0x72, 0x74, 0x5f, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0f, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x53, 0x6f, 0x72,
+	0x74, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x52, 0x09, 0x73, 0x6f, 0x72, 0x74, 0x4f, 0x72, 0x64, 0x65,
+	0x72, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65,
+	0x5f, 0x74, 0x61, 0x67, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x65, 0x78, 0x63,
+	0x6c, 0x75, 0x64, 0x65, 0x54, 0x61, 0x67, 0x73, 0x12, 0x2b, 0x0a, 0x09, 0x74, 0x61, 0x67, 0x5f,
+	0x6d, 0x61, 0x74, 0x63, 0x68, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x6d, 0x65,
+	0x6d, 0x65, 0x2e, 0x54, 0x61, 0x67, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x52, 0x08, 0x74, 0x61, 0x67,
+	0x4d, 0x61, 0x74, 0x63, 0x68, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x5f, 0x74,
+	0x79, 0x70, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6d, 0x65, 0x64, 0x69, 0x61,
+	0x54, 0x79, 0x70, 0x65, 0x22, 0x81, 0x01, 0x0a, 0x12, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x4d,
+	0x65, 0x6d, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x71,
+	0x75, 0x65, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72,
+	0x79, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x04, 0x70, 0x61, 0x67, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x73, 0x69,
+	0x7a, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x61, 0x67, 0x65, 0x53, 0x69,
+	0x7a, 0x65, 0x12, 0x24, 0x0a, 0x04, 0x73, 0x6f, 0x72, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x10, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x53, 0x6f,
+	0x72, 0x74, 0x52, 0x04, 0x73, 0x6f, 0x72, 0x74, 0x22, 0x3f, 0x0a, 0x11, 0x53, 0x65, 0x61, 0x72,
+	0x63, 0x68, 0x54, 0x61, 0x67, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a,
+	0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x71, 0x75,
+	0x65, 0x72, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0x3d, 0x0a, 0x0e, 0x41, 0x64, 0x64,
+	0x54, 0x61, 0x67, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x6d,
+	0x65, 0x6d, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x65,
+	0x6d, 0x65, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x22, 0x2f, 0x0a, 0x0f, 0x41, 0x64, 0x64, 0x54,
+	0x61, 0x67, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1c, 0x0a, 0x07, 0x73,
+	0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x42, 0x02, 0x18, 0x01,
+	0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x22, 0x22, 0x0a, 0x0c, 0x54, 0x61, 0x67,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x22, 0x43, 0x0a,
+	0x13, 0x41, 0x75, 0x74, 0x6f, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x14, 0x0a, 0x05,
+	0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6c, 0x69, 0x6d,
+	0x69, 0x74, 0x22, 0x4a, 0x0a, 0x0a, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x78, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x74, 0x65, 0x78, 0x74, 0x12, 0x28, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x14, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65,
+	0x74, 0x69, 0x6f, 0x6e, 0x4b, 0x69, 0x6e, 0x64, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x22, 0x4a,
+	0x0a, 0x14, 0x41, 0x75, 0x74, 0x6f, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x32, 0x0a, 0x0b, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x6d, 0x65,
+	0x6d, 0x65, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x63,
+	0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x35, 0x0a, 0x1a, 0x49, 0x6e,
+	0x63, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x45, 0x6e, 0x67, 0x61, 0x67, 0x65, 0x6d, 0x65, 0x6e,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x6d, 0x65, 0x6d, 0x65,
+	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x65, 0x6d, 0x65, 0x49,
+	0x64, 0x22, 0x4d, 0x0a, 0x1b, 0x49, 0x6e, 0x63, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x45, 0x6e,
+	0x67, 0x61, 0x67, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72,
+	0x22, 0x49, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x4d, 0x65,
+	0x6d, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61,
+	0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x70, 0x61, 0x67, 0x65, 0x12, 0x1b,
+	0x0a, 0x09, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x08, 0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x22, 0x2d, 0x0a, 0x12, 0x41,
+	0x70, 0x70, 0x72, 0x6f, 0x76, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x17, 0x0a, 0x07, 0x6d, 0x65, 0x6d, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x6d, 0x65, 0x6d, 0x65, 0x49, 0x64, 0x22, 0x45, 0x0a, 0x13, 0x41, 0x70,
+	0x70, 0x72, 0x6f, 0x76, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65,
+	0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x22, 0x2f, 0x0a, 0x14, 0x55, 0x6e, 0x61, 0x70, 0x70, 0x72, 0x6f, 0x76, 0x65, 0x4d, 0x65,
+	0x6d, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x6d, 0x65, 0x6d,
+	0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x65, 0x6d, 0x65,
+	0x49, 0x64, 0x22, 0x47, 0x0a, 0x15, 0x55, 0x6e, 0x61, 0x70, 0x70, 0x72, 0x6f, 0x76, 0x65, 0x4d,
+	0x65, 0x6d, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73,
+	0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x75,
+	0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x49, 0x0a, 0x16, 0x47,
+	0x65, 0x74, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x4d, 0x65, 0x6d, 0x65, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x04, 0x70, 0x61, 0x67, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x67,
+	0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x61,
+	0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x22, 0x2d, 0x0a, 0x12, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72,
+	0x65, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07,
+	0x6d, 0x65, 0x6d, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d,
+	0x65, 0x6d, 0x65, 0x49, 0x64, 0x22, 0x45, 0x0a, 0x13, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65,
 	0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07,
 	0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73,
-	0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x22, 0x8f, 0x01, 0x0a, 0x0d, 0x4d, 0x65, 0x6d, 0x65, 0x73,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x28, 0x0a, 0x05, 0x6d, 0x65, 0x6d, 0x65,
-	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x4d,
-	0x65, 0x6d, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x52, 0x05, 0x6d, 0x65, 0x6d,
-	0x65, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x63, 0x6f, 0x75, 0x6e,
-	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x43, 0x6f,
-	0x75, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x05, 0x52, 0x04, 0x70, 0x61, 0x67, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c,
-	0x5f, 0x70, 0x61, 0x67, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x74, 0x6f,
-	0x74, 0x61, 0x6c, 0x50, 0x61, 0x67, 0x65, 0x73, 0x2a, 0x5a, 0x0a, 0x09, 0x53, 0x6f, 0x72, 0x74,
-	0x4f, 0x72, 0x64, 0x65, 0x72, 0x12, 0x0a, 0x0a, 0x06, 0x4e, 0x45, 0x57, 0x45, 0x53, 0x54, 0x10,
-	0x00, 0x12, 0x0a, 0x0a, 0x06, 0x4f, 0x4c, 0x44, 0x45, 0x53, 0x54, 0x10, 0x01, 0x12, 0x0f, 0x0a,
-	0x0b, 0x4d, 0x4f, 0x53, 0x54, 0x5f, 0x54, 0x41, 0x47, 0x47, 0x45, 0x44, 0x10, 0x02, 0x12, 0x13,
-	0x0a, 0x0f, 0x4d, 0x4f, 0x53, 0x54, 0x5f, 0x44, 0x4f, 0x57, 0x4e, 0x4c, 0x4f, 0x41, 0x44, 0x45,
-	0x44, 0x10, 0x03, 0x12, 0x0f, 0x0a, 0x0b, 0x4d, 0x4f, 0x53, 0x54, 0x5f, 0x53, 0x48, 0x41, 0x52,
-	0x45, 0x44, 0x10, 0x04, 0x32, 0xf8, 0x06, 0x0a, 0x0b, 0x4d, 0x65, 0x6d, 0x65, 0x53, 0x65, 0x72,
-	0x76, 0x69, 0x63, 0x65, 0x12, 0x39, 0x0a, 0x0a, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x4d, 0x65,
-	0x6d, 0x65, 0x12, 0x17, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64,
-	0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x6d, 0x65,
-	0x6d, 0x65, 0x2e, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
-	0x3f, 0x0a, 0x0a, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x12, 0x17, 0x2e,
-	0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x55, 0x70,
-	0x64, 0x61, 0x74, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x12, 0x33, 0x0a, 0x07, 0x47, 0x65, 0x74, 0x4d, 0x65, 0x6d, 0x65, 0x12, 0x14, 0x2e, 0x6d, 0x65,
-	0x6d, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x12, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3f, 0x0a, 0x0a, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4d,
-	0x65, 0x6d, 0x65, 0x12, 0x17, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74,
-	0x65, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x6d,
-	0x65, 0x6d, 0x65, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x41, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x54, 0x69, 0x6d,
-	0x65, 0x6c, 0x69, 0x6e, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x73, 0x12, 0x18, 0x2e, 0x6d, 0x65, 0x6d,
-	0x65, 0x2e, 0x47, 0x65, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x4d, 0x65, 0x6d, 0x65,
-	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3c, 0x0a, 0x0b, 0x53, 0x65, 0x61,
-	0x72, 0x63, 0x68, 0x4d, 0x65, 0x6d, 0x65, 0x73, 0x12, 0x18, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e,
-	0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x4d, 0x65, 0x6d, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x1a, 0x13, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x4d, 0x65, 0x6d, 0x65, 0x73, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x39, 0x0a, 0x0a, 0x53, 0x65, 0x61, 0x72, 0x63,
-	0x68, 0x54, 0x61, 0x67, 0x73, 0x12, 0x17, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x53, 0x65, 0x61,
-	0x72, 0x63, 0x68, 0x54, 0x61, 0x67, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12,
-	0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x54, 0x61, 0x67, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x12, 0x36, 0x0a, 0x07, 0x41, 0x64, 0x64, 0x54, 0x61, 0x67, 0x73, 0x12, 0x14, 0x2e,
-	0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x41, 0x64, 0x64, 0x54, 0x61, 0x67, 0x73, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x41, 0x64, 0x64, 0x54, 0x61,
-	0x67, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x58, 0x0a, 0x11, 0x49, 0x6e,
-	0x63, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x12,
-	0x20, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x49, 0x6e, 0x63, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74,
-	0x45, 0x6e, 0x67, 0x61, 0x67, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x21, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x49, 0x6e, 0x63, 0x72, 0x65, 0x6d, 0x65,
-	0x6e, 0x74, 0x45, 0x6e, 0x67, 0x61, 0x67, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x55, 0x0a, 0x0e, 0x49, 0x6e, 0x63, 0x72, 0x65, 0x6d, 0x65, 0x6e,
-	0x74, 0x53, 0x68, 0x61, 0x72, 0x65, 0x12, 0x20, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x49, 0x6e,
-	0x63, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x45, 0x6e, 0x67, 0x61, 0x67, 0x65, 0x6d, 0x65, 0x6e,
-	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e,
+	0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x42, 0x0a, 0x18,
+	0x50, 0x75, 0x72, 0x67, 0x65, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x4d, 0x65, 0x6d, 0x65,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x26, 0x0a, 0x0f, 0x6f, 0x6c, 0x64, 0x65,
+	0x72, 0x5f, 0x74, 0x68, 0x61, 0x6e, 0x5f, 0x64, 0x61, 0x79, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0d, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x54, 0x68, 0x61, 0x6e, 0x44, 0x61, 0x79, 0x73,
+	0x22, 0x33, 0x0a, 0x19, 0x50, 0x75, 0x72, 0x67, 0x65, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64,
+	0x4d, 0x65, 0x6d, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a,
+	0x06, 0x70, 0x75, 0x72, 0x67, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x70,
+	0x75, 0x72, 0x67, 0x65, 0x64, 0x22, 0x4c, 0x0a, 0x17, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x55,
+	0x70, 0x6c, 0x6f, 0x61, 0x64, 0x53, 0x6c, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x54, 0x79, 0x70, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73,
+	0x69, 0x7a, 0x65, 0x22, 0xf7, 0x01, 0x0a, 0x12, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x53, 0x6c,
+	0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x75, 0x70,
+	0x6c, 0x6f, 0x61, 0x64, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x75, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x55, 0x72, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x74,
+	0x68, 0x6f, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f,
+	0x64, 0x12, 0x3f, 0x0a, 0x07, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x18, 0x04, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x25, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64,
+	0x53, 0x6c, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x48, 0x65, 0x61,
+	0x64, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x68, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41,
+	0x74, 0x1a, 0x3a, 0x0a, 0x0c, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x58, 0x0a,
+	0x15, 0x46, 0x69, 0x6e, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x73, 0x6c, 0x6f, 0x74, 0x5f, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x6c, 0x6f, 0x74, 0x49, 0x64, 0x12,
+	0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x22, 0xb3, 0x02, 0x0a, 0x0c, 0x4d, 0x65, 0x6d, 0x65,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x65, 0x64, 0x69,
+	0x61, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6d, 0x65, 0x64,
+	0x69, 0x61, 0x55, 0x72, 0x6c, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x5f, 0x74,
+	0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6d, 0x65, 0x64, 0x69, 0x61,
+	0x54, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73,
+	0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x1e, 0x0a, 0x0a,
+	0x64, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x05,
+	0x52, 0x0a, 0x64, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x25, 0x0a, 0x0e,
+	0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x43, 0x6f,
+	0x75, 0x6e, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x68, 0x61, 0x72, 0x65, 0x5f, 0x63, 0x6f, 0x75,
+	0x6e, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x73, 0x68, 0x61, 0x72, 0x65, 0x43,
+	0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x5f,
+	0x61, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x64, 0x41, 0x74, 0x12, 0x28, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x0a, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x4d, 0x65, 0x6d, 0x65, 0x53,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x52, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x22, 0x6d, 0x0a,
+	0x0a, 0x4d, 0x65, 0x6d, 0x65, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x70,
+	0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70,
+	0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x19, 0x0a, 0x08, 0x70, 0x6f, 0x73,
+	0x74, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x6f, 0x73,
+	0x74, 0x55, 0x72, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x22, 0x53, 0x0a, 0x17,
+	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x28, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x4d,
+	0x65, 0x6d, 0x65, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x52, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x22, 0x44, 0x0a, 0x18, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x53,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x28, 0x0a,
+	0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e,
+	0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x4d, 0x65, 0x6d, 0x65, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x52,
+	0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x22, 0x2e, 0x0a, 0x12, 0x44, 0x65, 0x6c, 0x65, 0x74,
+	0x65, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a,
+	0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07,
+	0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x22, 0x2e, 0x0a, 0x12, 0x55, 0x70, 0x64, 0x61, 0x74,
+	0x65, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a,
+	0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07,
+	0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x22, 0xd4, 0x01, 0x0a, 0x0d, 0x4d, 0x65, 0x6d, 0x65,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x28, 0x0a, 0x05, 0x6d, 0x65, 0x6d,
+	0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e,
+	0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x52, 0x05, 0x6d, 0x65,
+	0x6d, 0x65, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x63, 0x6f, 0x75,
+	0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x43,
+	0x6f, 0x75, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x04, 0x70, 0x61, 0x67, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x6f, 0x74, 0x61,
+	0x6c, 0x5f, 0x70, 0x61, 0x67, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x74,
+	0x6f, 0x74, 0x61, 0x6c, 0x50, 0x61, 0x67, 0x65, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x73, 0x75, 0x67,
+	0x67, 0x65, 0x73, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b,
+	0x73, 0x75, 0x67, 0x67, 0x65, 0x73, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x72,
+	0x65, 0x6c, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x74, 0x61, 0x67, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x0b, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x65, 0x64, 0x54, 0x61, 0x67, 0x73, 0x2a, 0x5a,
+	0x0a, 0x09, 0x53, 0x6f, 0x72, 0x74, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x12, 0x0a, 0x0a, 0x06, 0x4e,
+	0x45, 0x57, 0x45, 0x53, 0x54, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x4f, 0x4c, 0x44, 0x45, 0x53,
+	0x54, 0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b, 0x4d, 0x4f, 0x53, 0x54, 0x5f, 0x54, 0x41, 0x47, 0x47,
+	0x45, 0x44, 0x10, 0x02, 0x12, 0x13, 0x0a, 0x0f, 0x4d, 0x4f, 0x53, 0x54, 0x5f, 0x44, 0x4f, 0x57,
+	0x4e, 0x4c, 0x4f, 0x41, 0x44, 0x45, 0x44, 0x10, 0x03, 0x12, 0x0f, 0x0a, 0x0b, 0x4d, 0x4f, 0x53,
+	0x54, 0x5f, 0x53, 0x48, 0x41, 0x52, 0x45, 0x44, 0x10, 0x04, 0x2a, 0x51, 0x0a, 0x0a, 0x53, 0x65,
+	0x61, 0x72, 0x63, 0x68, 0x53, 0x6f, 0x72, 0x74, 0x12, 0x14, 0x0a, 0x10, 0x53, 0x45, 0x41, 0x52,
+	0x43, 0x48, 0x5f, 0x52, 0x45, 0x4c, 0x45, 0x56, 0x41, 0x4e, 0x43, 0x45, 0x10, 0x00, 0x12, 0x11,
+	0x0a, 0x0d, 0x53, 0x45, 0x41, 0x52, 0x43, 0x48, 0x5f, 0x4e, 0x45, 0x57, 0x45, 0x53, 0x54, 0x10,
+	0x01, 0x12, 0x1a, 0x0a, 0x16, 0x53, 0x45, 0x41, 0x52, 0x43, 0x48, 0x5f, 0x4d, 0x4f, 0x53, 0x54,
+	0x5f, 0x44, 0x4f, 0x57, 0x4e, 0x4c, 0x4f, 0x41, 0x44, 0x45, 0x44, 0x10, 0x02, 0x2a, 0x25, 0x0a,
+	0x08, 0x54, 0x61, 0x67, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x12, 0x0b, 0x0a, 0x07, 0x41, 0x4e, 0x59,
+	0x5f, 0x54, 0x41, 0x47, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x41, 0x4c, 0x4c, 0x5f, 0x54, 0x41,
+	0x47, 0x53, 0x10, 0x01, 0x2a, 0x39, 0x0a, 0x0e, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x69,
+	0x6f, 0x6e, 0x4b, 0x69, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x0e, 0x43, 0x4f, 0x4d, 0x50, 0x4c, 0x45,
+	0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x54, 0x41, 0x47, 0x10, 0x00, 0x12, 0x13, 0x0a, 0x0f, 0x43, 0x4f,
+	0x4d, 0x50, 0x4c, 0x45, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x4d, 0x45, 0x4d, 0x45, 0x10, 0x01, 0x32,
+	0x82, 0x0b, 0x0a, 0x0b, 0x4d, 0x65, 0x6d, 0x65, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12,
+	0x39, 0x0a, 0x0a, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x4d, 0x65, 0x6d, 0x65, 0x12, 0x17, 0x2e,
+	0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x4d, 0x65, 0x6d, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x4d, 0x65,
+	0x6d, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3f, 0x0a, 0x0a, 0x55, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x12, 0x17, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e,
+	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x18, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4d,
+	0x65, 0x6d, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x33, 0x0a, 0x07, 0x47,
+	0x65, 0x74, 0x4d, 0x65, 0x6d, 0x65, 0x12, 0x14, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x47, 0x65,
+	0x74, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x6d,
+	0x65, 0x6d, 0x65, 0x2e, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x3f, 0x0a, 0x0a, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x12, 0x17,
+	0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4d, 0x65, 0x6d, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x44,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x41, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x6c, 0x69, 0x6e, 0x65,
+	0x4d, 0x65, 0x6d, 0x65, 0x73, 0x12, 0x18, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x47, 0x65, 0x74,
+	0x54, 0x69, 0x6d, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x13, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x4d, 0x65, 0x6d, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3c, 0x0a, 0x0b, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x4d, 0x65,
+	0x6d, 0x65, 0x73, 0x12, 0x18, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63,
+	0x68, 0x4d, 0x65, 0x6d, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e,
+	0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x4d, 0x65, 0x6d, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x39, 0x0a, 0x0a, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x54, 0x61, 0x67, 0x73,
+	0x12, 0x17, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x54, 0x61,
+	0x67, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x6d, 0x65, 0x6d, 0x65,
+	0x2e, 0x54, 0x61, 0x67, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x45, 0x0a,
+	0x0c, 0x41, 0x75, 0x74, 0x6f, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x19, 0x2e,
+	0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x41, 0x75, 0x74, 0x6f, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e,
+	0x41, 0x75, 0x74, 0x6f, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x36, 0x0a, 0x07, 0x41, 0x64, 0x64, 0x54, 0x61, 0x67, 0x73, 0x12,
+	0x14, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x41, 0x64, 0x64, 0x54, 0x61, 0x67, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x41, 0x64, 0x64,
+	0x54, 0x61, 0x67, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x58, 0x0a, 0x11,
+	0x49, 0x6e, 0x63, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61,
+	0x64, 0x12, 0x20, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x49, 0x6e, 0x63, 0x72, 0x65, 0x6d, 0x65,
+	0x6e, 0x74, 0x45, 0x6e, 0x67, 0x61, 0x67, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x49, 0x6e, 0x63, 0x72, 0x65,
+	0x6d, 0x65, 0x6e, 0x74, 0x45, 0x6e, 0x67, 0x61, 0x67, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x55, 0x0a, 0x0e, 0x49, 0x6e, 0x63, 0x72, 0x65, 0x6d,
+	0x65, 0x6e, 0x74, 0x53, 0x68, 0x61, 0x72, 0x65, 0x12, 0x20, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e,
 	0x49, 0x6e, 0x63, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x45, 0x6e, 0x67, 0x61, 0x67, 0x65, 0x6d,
-	0x65, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x44, 0x0a, 0x0f, 0x47,
-	0x65, 0x74, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x4d, 0x65, 0x6d, 0x65, 0x73, 0x12, 0x1c,
-	0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67,
-	0x4d, 0x65, 0x6d, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x6d,
-	0x65, 0x6d, 0x65, 0x2e, 0x4d, 0x65, 0x6d, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x12, 0x42, 0x0a, 0x0b, 0x41, 0x70, 0x70, 0x72, 0x6f, 0x76, 0x65, 0x4d, 0x65, 0x6d, 0x65,
-	0x12, 0x18, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x41, 0x70, 0x70, 0x72, 0x6f, 0x76, 0x65, 0x4d,
-	0x65, 0x6d, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x6d, 0x65, 0x6d,
-	0x65, 0x2e, 0x41, 0x70, 0x70, 0x72, 0x6f, 0x76, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x48, 0x0a, 0x0d, 0x55, 0x6e, 0x61, 0x70, 0x70, 0x72, 0x6f,
-	0x76, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x12, 0x1a, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x55, 0x6e,
-	0x61, 0x70, 0x70, 0x72, 0x6f, 0x76, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x55, 0x6e, 0x61, 0x70, 0x70, 0x72,
-	0x6f, 0x76, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42,
-	0x31, 0x5a, 0x2f, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x42, 0x61,
-	0x73, 0x73, 0x65, 0x6d, 0x48, 0x61, 0x6c, 0x69, 0x6d, 0x2f, 0x6d, 0x65, 0x6d, 0x65, 0x44, 0x42,
-	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x6d, 0x65, 0x6d, 0x65, 0x53, 0x65, 0x72, 0x76, 0x69,
-	0x63, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x6d, 0x65, 0x6d,
+	0x65, 0x2e, 0x49, 0x6e, 0x63, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x45, 0x6e, 0x67, 0x61, 0x67,
+	0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x44, 0x0a,
+	0x0f, 0x47, 0x65, 0x74, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x4d, 0x65, 0x6d, 0x65, 0x73,
+	0x12, 0x1c, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x65, 0x6e, 0x64, 0x69,
+	0x6e, 0x67, 0x4d, 0x65, 0x6d, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13,
+	0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x4d, 0x65, 0x6d, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x42, 0x0a, 0x0b, 0x41, 0x70, 0x70, 0x72, 0x6f, 0x76, 0x65, 0x4d, 0x65,
+	0x6d, 0x65, 0x12, 0x18, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x41, 0x70, 0x70, 0x72, 0x6f, 0x76,
+	0x65, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x6d,
+	0x65, 0x6d, 0x65, 0x2e, 0x41, 0x70, 0x70, 0x72, 0x6f, 0x76, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x48, 0x0a, 0x0d, 0x55, 0x6e, 0x61, 0x70, 0x70,
+	0x72, 0x6f, 0x76, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x12, 0x1a, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e,
+	0x55, 0x6e, 0x61, 0x70, 0x70, 0x72, 0x6f, 0x76, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x55, 0x6e, 0x61, 0x70,
+	0x70, 0x72, 0x6f, 0x76, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x44, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x4d,
+	0x65, 0x6d, 0x65, 0x73, 0x12, 0x1c, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x44,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x4d, 0x65, 0x6d, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x13, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x4d, 0x65, 0x6d, 0x65, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x42, 0x0a, 0x0b, 0x52, 0x65, 0x73, 0x74, 0x6f,
+	0x72, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x12, 0x18, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x52, 0x65,
+	0x73, 0x74, 0x6f, 0x72, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x19, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x4d,
+	0x65, 0x6d, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x54, 0x0a, 0x11, 0x50,
+	0x75, 0x72, 0x67, 0x65, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x4d, 0x65, 0x6d, 0x65, 0x73,
+	0x12, 0x1e, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x50, 0x75, 0x72, 0x67, 0x65, 0x44, 0x65, 0x6c,
+	0x65, 0x74, 0x65, 0x64, 0x4d, 0x65, 0x6d, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x1f, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x50, 0x75, 0x72, 0x67, 0x65, 0x44, 0x65, 0x6c,
+	0x65, 0x74, 0x65, 0x64, 0x4d, 0x65, 0x6d, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x4b, 0x0a, 0x10, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x55, 0x70, 0x6c, 0x6f, 0x61,
+	0x64, 0x53, 0x6c, 0x6f, 0x74, 0x12, 0x1d, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x43, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x53, 0x6c, 0x6f, 0x74, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x55, 0x70, 0x6c, 0x6f,
+	0x61, 0x64, 0x53, 0x6c, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x41,
+	0x0a, 0x0e, 0x46, 0x69, 0x6e, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64,
+	0x12, 0x1b, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x46, 0x69, 0x6e, 0x61, 0x6c, 0x69, 0x7a, 0x65,
+	0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e,
+	0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x4d, 0x65, 0x6d, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x51, 0x0a, 0x10, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x53,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x1d, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x55, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x6d, 0x65, 0x6d, 0x65, 0x2e, 0x55, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x4d, 0x65, 0x6d, 0x65, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x42, 0x31, 0x5a, 0x2f, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
+	0x6f, 0x6d, 0x2f, 0x42, 0x61, 0x73, 0x73, 0x65, 0x6d, 0x48, 0x61, 0x6c, 0x69, 0x6d, 0x2f, 0x6d,
+	0x65, 0x6d, 0x65, 0x44, 0x42, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x6d, 0x65, 0x6d, 0x65,
+	0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1466,66 +2601,106 @@ func file_meme_proto_rawDescGZIP() []byte {
 	return file_meme_proto_rawDescData
 }
 
-var file_meme_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_meme_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
+var file_meme_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_meme_proto_msgTypes = make([]protoimpl.MessageInfo, 36)
 var file_meme_proto_goTypes = []any{
 	(SortOrder)(0),                      // 0: meme.SortOrder
-	(*UploadMemeRequest)(nil),           // 1: meme.UploadMemeRequest
-	(*UpdateMemeRequest)(nil),           // 2: meme.UpdateMemeRequest
-	(*GetMemeRequest)(nil),              // 3: meme.GetMemeRequest
-	(*DeleteMemeRequest)(nil),           // 4: meme.DeleteMemeRequest
-	(*GetTimelineRequest)(nil),          // 5: meme.GetTimelineRequest
-	(*SearchMemesRequest)(nil),          // 6: meme.SearchMemesRequest
-	(*SearchTagsRequest)(nil),           // 7: meme.SearchTagsRequest
-	(*AddTagsRequest)(nil),              // 8: meme.AddTagsRequest
-	(*AddTagsResponse)(nil),             // 9: meme.AddTagsResponse
-	(*TagsResponse)(nil),                // 10: meme.TagsResponse
-	(*IncrementEngagementRequest)(nil),  // 11: meme.IncrementEngagementRequest
-	(*IncrementEngagementResponse)(nil), // 12: meme.IncrementEngagementResponse
-	(*GetPendingMemesRequest)(nil),      // 13: meme.GetPendingMemesRequest
-	(*ApproveMemeRequest)(nil),          // 14: meme.ApproveMemeRequest
-	(*ApproveMemeResponse)(nil),         // 15: meme.ApproveMemeResponse
-	(*UnapproveMemeRequest)(nil),        // 16: meme.UnapproveMemeRequest
-	(*UnapproveMemeResponse)(nil),       // 17: meme.UnapproveMemeResponse
-	(*MemeResponse)(nil),                // 18: meme.MemeResponse
-	(*DeleteMemeResponse)(nil),          // 19: meme.DeleteMemeResponse
-	(*UpdateMemeResponse)(nil),          // 20: meme.UpdateMemeResponse
-	(*MemesResponse)(nil),               // 21: meme.MemesResponse
+	(SearchSort)(0),                     // 1: meme.SearchSort
+	(TagMatch)(0),                       // 2: meme.TagMatch
+	(CompletionKind)(0),                 // 3: meme.CompletionKind
+	(*UploadMemeRequest)(nil),           // 4: meme.UploadMemeRequest
+	(*UpdateMemeRequest)(nil),           // 5: meme.UpdateMemeRequest
+	(*GetMemeRequest)(nil),              // 6: meme.GetMemeRequest
+	(*DeleteMemeRequest)(nil),           // 7: meme.DeleteMemeRequest
+	(*GetTimelineRequest)(nil),          // 8: meme.GetTimelineRequest
+	(*SearchMemesRequest)(nil),          // 9: meme.SearchMemesRequest
+	(*SearchTagsRequest)(nil),           // 10: meme.SearchTagsRequest
+	(*AddTagsRequest)(nil),              // 11: meme.AddTagsRequest
+	(*AddTagsResponse)(nil),             // 12: meme.AddTagsResponse
+	(*TagsResponse)(nil),                // 13: meme.TagsResponse
+	(*AutocompleteRequest)(nil),         // 14: meme.AutocompleteRequest
+	(*Completion)(nil),                  // 15: meme.Completion
+	(*AutocompleteResponse)(nil),        // 16: meme.AutocompleteResponse
+	(*IncrementEngagementRequest)(nil),  // 17: meme.IncrementEngagementRequest
+	(*IncrementEngagementResponse)(nil), // 18: meme.IncrementEngagementResponse
+	(*GetPendingMemesRequest)(nil),      // 19: meme.GetPendingMemesRequest
+	(*ApproveMemeRequest)(nil),          // 20: meme.ApproveMemeRequest
+	(*ApproveMemeResponse)(nil),         // 21: meme.ApproveMemeResponse
+	(*UnapproveMemeRequest)(nil),        // 22: meme.UnapproveMemeRequest
+	(*UnapproveMemeResponse)(nil),       // 23: meme.UnapproveMemeResponse
+	(*GetDeletedMemesRequest)(nil),      // 24: meme.GetDeletedMemesRequest
+	(*RestoreMemeRequest)(nil),          // 25: meme.RestoreMemeRequest
+	(*RestoreMemeResponse)(nil),         // 26: meme.RestoreMemeResponse
+	(*PurgeDeletedMemesRequest)(nil),    // 27: meme.PurgeDeletedMemesRequest
+	(*PurgeDeletedMemesResponse)(nil),   // 28: meme.PurgeDeletedMemesResponse
+	(*CreateUploadSlotRequest)(nil),     // 29: meme.CreateUploadSlotRequest
+	(*UploadSlotResponse)(nil),          // 30: meme.UploadSlotResponse
+	(*FinalizeUploadRequest)(nil),       // 31: meme.FinalizeUploadRequest
+	(*MemeResponse)(nil),                // 32: meme.MemeResponse
+	(*MemeSource)(nil),                  // 33: meme.MemeSource
+	(*UpdateMemeSourceRequest)(nil),     // 34: meme.UpdateMemeSourceRequest
+	(*UpdateMemeSourceResponse)(nil),    // 35: meme.UpdateMemeSourceResponse
+	(*DeleteMemeResponse)(nil),          // 36: meme.DeleteMemeResponse
+	(*UpdateMemeResponse)(nil),          // 37: meme.UpdateMemeResponse
+	(*MemesResponse)(nil),               // 38: meme.MemesResponse
+	nil,                                 // 39: meme.UploadSlotResponse.HeadersEntry
 }
 var file_meme_proto_depIdxs = []int32{
 	0,  // 0: meme.GetTimelineRequest.sort_order:type_name -> meme.SortOrder
-	18, // 1: meme.MemesResponse.memes:type_name -> meme.MemeResponse
-	1,  // 2: meme.MemeService.UploadMeme:input_type -> meme.UploadMemeRequest
-	2,  // 3: meme.MemeService.UpdateMeme:input_type -> meme.UpdateMemeRequest
-	3,  // 4: meme.MemeService.GetMeme:input_type -> meme.GetMemeRequest
-	4,  // 5: meme.MemeService.DeleteMeme:input_type -> meme.DeleteMemeRequest
-	5,  // 6: meme.MemeService.GetTimelineMemes:input_type -> meme.GetTimelineRequest
-	6,  // 7: meme.MemeService.SearchMemes:input_type -> meme.SearchMemesRequest
-	7,  // 8: meme.MemeService.SearchTags:input_type -> meme.SearchTagsRequest
-	8,  // 9: meme.MemeService.AddTags:input_type -> meme.AddTagsRequest
-	11, // 10: meme.MemeService.IncrementDownload:input_type -> meme.IncrementEngagementRequest
-	11, // 11: meme.MemeService.IncrementShare:input_type -> meme.IncrementEngagementRequest
-	13, // 12: meme.MemeService.GetPendingMemes:input_type -> meme.GetPendingMemesRequest
-	14, // 13: meme.MemeService.ApproveMeme:input_type -> meme.ApproveMemeRequest
-	16, // 14: meme.MemeService.UnapproveMeme:input_type -> meme.UnapproveMemeRequest
-	18, // 15: meme.MemeService.UploadMeme:output_type -> meme.MemeResponse
-	20, // 16: meme.MemeService.UpdateMeme:output_type -> meme.UpdateMemeResponse
-	18, // 17: meme.MemeService.GetMeme:output_type -> meme.MemeResponse
-	19, // 18: meme.MemeService.DeleteMeme:output_type -> meme.DeleteMemeResponse
-	21, // 19: meme.MemeService.GetTimelineMemes:output_type -> meme.MemesResponse
-	21, // 20: meme.MemeService.SearchMemes:output_type -> meme.MemesResponse
-	10, // 21: meme.MemeService.SearchTags:output_type -> meme.TagsResponse
-	9,  // 22: meme.MemeService.AddTags:output_type -> meme.AddTagsResponse
-	12, // 23: meme.MemeService.IncrementDownload:output_type -> meme.IncrementEngagementResponse
-	12, // 24: meme.MemeService.IncrementShare:output_type -> meme.IncrementEngagementResponse
-	21, // 25: meme.MemeService.GetPendingMemes:output_type -> meme.MemesResponse
-	15, // 26: meme.MemeService.ApproveMeme:output_type -> meme.ApproveMemeResponse
-	17, // 27: meme.MemeService.UnapproveMeme:output_type -> meme.UnapproveMemeResponse
-	15, // [15:28] is the sub-list for method output_type
-	2,  // [2:15] is the sub-list for method input_type
-	2,  // [2:2] is the sub-list for extension type_name
-	2,  // [2:2] is the sub-list for extension extendee
-	0,  // [0:2] is the sub-list for field type_name
+	2,  // 1: meme.GetTimelineRequest.tag_match:type_name -> meme.TagMatch
+	1,  // 2: meme.SearchMemesRequest.sort:type_name -> meme.SearchSort
+	3,  // 3: meme.Completion.kind:type_name -> meme.CompletionKind
+	15, // 4: meme.AutocompleteResponse.completions:type_name -> meme.Completion
+	39, // 5: meme.UploadSlotResponse.headers:type_name -> meme.UploadSlotResponse.HeadersEntry
+	33, // 6: meme.MemeResponse.source:type_name -> meme.MemeSource
+	33, // 7: meme.UpdateMemeSourceRequest.source:type_name -> meme.MemeSource
+	33, // 8: meme.UpdateMemeSourceResponse.source:type_name -> meme.MemeSource
+	32, // 9: meme.MemesResponse.memes:type_name -> meme.MemeResponse
+	4,  // 10: meme.MemeService.UploadMeme:input_type -> meme.UploadMemeRequest
+	5,  // 11: meme.MemeService.UpdateMeme:input_type -> meme.UpdateMemeRequest
+	6,  // 12: meme.MemeService.GetMeme:input_type -> meme.GetMemeRequest
+	7,  // 13: meme.MemeService.DeleteMeme:input_type -> meme.DeleteMemeRequest
+	8,  // 14: meme.MemeService.GetTimelineMemes:input_type -> meme.GetTimelineRequest
+	9,  // 15: meme.MemeService.SearchMemes:input_type -> meme.SearchMemesRequest
+	10, // 16: meme.MemeService.SearchTags:input_type -> meme.SearchTagsRequest
+	14, // 17: meme.MemeService.Autocomplete:input_type -> meme.AutocompleteRequest
+	11, // 18: meme.MemeService.AddTags:input_type -> meme.AddTagsRequest
+	17, // 19: meme.MemeService.IncrementDownload:input_type -> meme.IncrementEngagementRequest
+	17, // 20: meme.MemeService.IncrementShare:input_type -> meme.IncrementEngagementRequest
+	19, // 21: meme.MemeService.GetPendingMemes:input_type -> meme.GetPendingMemesRequest
+	20, // 22: meme.MemeService.ApproveMeme:input_type -> meme.ApproveMemeRequest
+	22, // 23: meme.MemeService.UnapproveMeme:input_type -> meme.UnapproveMemeRequest
+	24, // 24: meme.MemeService.GetDeletedMemes:input_type -> meme.GetDeletedMemesRequest
+	25, // 25: meme.MemeService.RestoreMeme:input_type -> meme.RestoreMemeRequest
+	27, // 26: meme.MemeService.PurgeDeletedMemes:input_type -> meme.PurgeDeletedMemesRequest
+	29, // 27: meme.MemeService.CreateUploadSlot:input_type -> meme.CreateUploadSlotRequest
+	31, // 28: meme.MemeService.FinalizeUpload:input_type -> meme.FinalizeUploadRequest
+	34, // 29: meme.MemeService.UpdateMemeSource:input_type -> meme.UpdateMemeSourceRequest
+	32, // 30: meme.MemeService.UploadMeme:output_type -> meme.MemeResponse
+	37, // 31: meme.MemeService.UpdateMeme:output_type -> meme.UpdateMemeResponse
+	32, // 32: meme.MemeService.GetMeme:output_type -> meme.MemeResponse
+	36, // 33: meme.MemeService.DeleteMeme:output_type -> meme.DeleteMemeResponse
+	38, // 34: meme.MemeService.GetTimelineMemes:output_type -> meme.MemesResponse
+	38, // 35: meme.MemeService.SearchMemes:output_type -> meme.MemesResponse
+	13, // 36: meme.MemeService.SearchTags:output_type -> meme.TagsResponse
+	16, // 37: meme.MemeService.Autocomplete:output_type -> meme.AutocompleteResponse
+	12, // 38: meme.MemeService.AddTags:output_type -> meme.AddTagsResponse
+	18, // 39: meme.MemeService.IncrementDownload:output_type -> meme.IncrementEngagementResponse
+	18, // 40: meme.MemeService.IncrementShare:output_type -> meme.IncrementEngagementResponse
+	38, // 41: meme.MemeService.GetPendingMemes:output_type -> meme.MemesResponse
+	21, // 42: meme.MemeService.ApproveMeme:output_type -> meme.ApproveMemeResponse
+	23, // 43: meme.MemeService.UnapproveMeme:output_type -> meme.UnapproveMemeResponse
+	38, // 44: meme.MemeService.GetDeletedMemes:output_type -> meme.MemesResponse
+	26, // 45: meme.MemeService.RestoreMeme:output_type -> meme.RestoreMemeResponse
+	28, // 46: meme.MemeService.PurgeDeletedMemes:output_type -> meme.PurgeDeletedMemesResponse
+	30, // 47: meme.MemeService.CreateUploadSlot:output_type -> meme.UploadSlotResponse
+	32, // 48: meme.MemeService.FinalizeUpload:output_type -> meme.MemeResponse
+	35, // 49: meme.MemeService.UpdateMemeSource:output_type -> meme.UpdateMemeSourceResponse
+	30, // [30:50] is the sub-list for method output_type
+	10, // [10:30] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_meme_proto_init() }
@@ -1538,8 +2713,8 @@ func file_meme_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_meme_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   21,
+			NumEnums:      4,
+			NumMessages:   36,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
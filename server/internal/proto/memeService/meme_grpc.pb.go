@@ -26,12 +26,19 @@ const (
 	MemeService_GetTimelineMemes_FullMethodName  = "/meme.MemeService/GetTimelineMemes"
 	MemeService_SearchMemes_FullMethodName       = "/meme.MemeService/SearchMemes"
 	MemeService_SearchTags_FullMethodName        = "/meme.MemeService/SearchTags"
+	MemeService_Autocomplete_FullMethodName      = "/meme.MemeService/Autocomplete"
 	MemeService_AddTags_FullMethodName           = "/meme.MemeService/AddTags"
 	MemeService_IncrementDownload_FullMethodName = "/meme.MemeService/IncrementDownload"
 	MemeService_IncrementShare_FullMethodName    = "/meme.MemeService/IncrementShare"
 	MemeService_GetPendingMemes_FullMethodName   = "/meme.MemeService/GetPendingMemes"
 	MemeService_ApproveMeme_FullMethodName       = "/meme.MemeService/ApproveMeme"
 	MemeService_UnapproveMeme_FullMethodName     = "/meme.MemeService/UnapproveMeme"
+	MemeService_GetDeletedMemes_FullMethodName   = "/meme.MemeService/GetDeletedMemes"
+	MemeService_RestoreMeme_FullMethodName       = "/meme.MemeService/RestoreMeme"
+	MemeService_PurgeDeletedMemes_FullMethodName = "/meme.MemeService/PurgeDeletedMemes"
+	MemeService_CreateUploadSlot_FullMethodName  = "/meme.MemeService/CreateUploadSlot"
+	MemeService_FinalizeUpload_FullMethodName    = "/meme.MemeService/FinalizeUpload"
+	MemeService_UpdateMemeSource_FullMethodName  = "/meme.MemeService/UpdateMemeSource"
 )
 
 // MemeServiceClient is the client API for MemeService service.
@@ -45,12 +52,19 @@ type MemeServiceClient interface {
 	GetTimelineMemes(ctx context.Context, in *GetTimelineRequest, opts ...grpc.CallOption) (*MemesResponse, error)
 	SearchMemes(ctx context.Context, in *SearchMemesRequest, opts ...grpc.CallOption) (*MemesResponse, error)
 	SearchTags(ctx context.Context, in *SearchTagsRequest, opts ...grpc.CallOption) (*TagsResponse, error)
+	Autocomplete(ctx context.Context, in *AutocompleteRequest, opts ...grpc.CallOption) (*AutocompleteResponse, error)
 	AddTags(ctx context.Context, in *AddTagsRequest, opts ...grpc.CallOption) (*AddTagsResponse, error)
 	IncrementDownload(ctx context.Context, in *IncrementEngagementRequest, opts ...grpc.CallOption) (*IncrementEngagementResponse, error)
 	IncrementShare(ctx context.Context, in *IncrementEngagementRequest, opts ...grpc.CallOption) (*IncrementEngagementResponse, error)
 	GetPendingMemes(ctx context.Context, in *GetPendingMemesRequest, opts ...grpc.CallOption) (*MemesResponse, error)
 	ApproveMeme(ctx context.Context, in *ApproveMemeRequest, opts ...grpc.CallOption) (*ApproveMemeResponse, error)
 	UnapproveMeme(ctx context.Context, in *UnapproveMemeRequest, opts ...grpc.CallOption) (*UnapproveMemeResponse, error)
+	GetDeletedMemes(ctx context.Context, in *GetDeletedMemesRequest, opts ...grpc.CallOption) (*MemesResponse, error)
+	RestoreMeme(ctx context.Context, in *RestoreMemeRequest, opts ...grpc.CallOption) (*RestoreMemeResponse, error)
+	PurgeDeletedMemes(ctx context.Context, in *PurgeDeletedMemesRequest, opts ...grpc.CallOption) (*PurgeDeletedMemesResponse, error)
+	CreateUploadSlot(ctx context.Context, in *CreateUploadSlotRequest, opts ...grpc.CallOption) (*UploadSlotResponse, error)
+	FinalizeUpload(ctx context.Context, in *FinalizeUploadRequest, opts ...grpc.CallOption) (*MemeResponse, error)
+	UpdateMemeSource(ctx context.Context, in *UpdateMemeSourceRequest, opts ...grpc.CallOption) (*UpdateMemeSourceResponse, error)
 }
 
 type memeServiceClient struct {
@@ -131,6 +145,16 @@ func (c *memeServiceClient) SearchTags(ctx context.Context, in *SearchTagsReques
 	return out, nil
 }
 
+func (c *memeServiceClient) Autocomplete(ctx context.Context, in *AutocompleteRequest, opts ...grpc.CallOption) (*AutocompleteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AutocompleteResponse)
+	err := c.cc.Invoke(ctx, MemeService_Autocomplete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *memeServiceClient) AddTags(ctx context.Context, in *AddTagsRequest, opts ...grpc.CallOption) (*AddTagsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(AddTagsResponse)
@@ -191,6 +215,66 @@ func (c *memeServiceClient) UnapproveMeme(ctx context.Context, in *UnapproveMeme
 	return out, nil
 }
 
+func (c *memeServiceClient) GetDeletedMemes(ctx context.Context, in *GetDeletedMemesRequest, opts ...grpc.CallOption) (*MemesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MemesResponse)
+	err := c.cc.Invoke(ctx, MemeService_GetDeletedMemes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *memeServiceClient) RestoreMeme(ctx context.Context, in *RestoreMemeRequest, opts ...grpc.CallOption) (*RestoreMemeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestoreMemeResponse)
+	err := c.cc.Invoke(ctx, MemeService_RestoreMeme_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *memeServiceClient) PurgeDeletedMemes(ctx context.Context, in *PurgeDeletedMemesRequest, opts ...grpc.CallOption) (*PurgeDeletedMemesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PurgeDeletedMemesResponse)
+	err := c.cc.Invoke(ctx, MemeService_PurgeDeletedMemes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *memeServiceClient) CreateUploadSlot(ctx context.Context, in *CreateUploadSlotRequest, opts ...grpc.CallOption) (*UploadSlotResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UploadSlotResponse)
+	err := c.cc.Invoke(ctx, MemeService_CreateUploadSlot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *memeServiceClient) FinalizeUpload(ctx context.Context, in *FinalizeUploadRequest, opts ...grpc.CallOption) (*MemeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MemeResponse)
+	err := c.cc.Invoke(ctx, MemeService_FinalizeUpload_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *memeServiceClient) UpdateMemeSource(ctx context.Context, in *UpdateMemeSourceRequest, opts ...grpc.CallOption) (*UpdateMemeSourceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateMemeSourceResponse)
+	err := c.cc.Invoke(ctx, MemeService_UpdateMemeSource_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MemeServiceServer is the server API for MemeService service.
 // All implementations must embed UnimplementedMemeServiceServer
 // for forward compatibility.
@@ -202,12 +286,19 @@ type MemeServiceServer interface {
 	GetTimelineMemes(context.Context, *GetTimelineRequest) (*MemesResponse, error)
 	SearchMemes(context.Context, *SearchMemesRequest) (*MemesResponse, error)
 	SearchTags(context.Context, *SearchTagsRequest) (*TagsResponse, error)
+	Autocomplete(context.Context, *AutocompleteRequest) (*AutocompleteResponse, error)
 	AddTags(context.Context, *AddTagsRequest) (*AddTagsResponse, error)
 	IncrementDownload(context.Context, *IncrementEngagementRequest) (*IncrementEngagementResponse, error)
 	IncrementShare(context.Context, *IncrementEngagementRequest) (*IncrementEngagementResponse, error)
 	GetPendingMemes(context.Context, *GetPendingMemesRequest) (*MemesResponse, error)
 	ApproveMeme(context.Context, *ApproveMemeRequest) (*ApproveMemeResponse, error)
 	UnapproveMeme(context.Context, *UnapproveMemeRequest) (*UnapproveMemeResponse, error)
+	GetDeletedMemes(context.Context, *GetDeletedMemesRequest) (*MemesResponse, error)
+	RestoreMeme(context.Context, *RestoreMemeRequest) (*RestoreMemeResponse, error)
+	PurgeDeletedMemes(context.Context, *PurgeDeletedMemesRequest) (*PurgeDeletedMemesResponse, error)
+	CreateUploadSlot(context.Context, *CreateUploadSlotRequest) (*UploadSlotResponse, error)
+	FinalizeUpload(context.Context, *FinalizeUploadRequest) (*MemeResponse, error)
+	UpdateMemeSource(context.Context, *UpdateMemeSourceRequest) (*UpdateMemeSourceResponse, error)
 	mustEmbedUnimplementedMemeServiceServer()
 }
 
@@ -239,6 +330,9 @@ func (UnimplementedMemeServiceServer) SearchMemes(context.Context, *SearchMemesR
 func (UnimplementedMemeServiceServer) SearchTags(context.Context, *SearchTagsRequest) (*TagsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SearchTags not implemented")
 }
+func (UnimplementedMemeServiceServer) Autocomplete(context.Context, *AutocompleteRequest) (*AutocompleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Autocomplete not implemented")
+}
 func (UnimplementedMemeServiceServer) AddTags(context.Context, *AddTagsRequest) (*AddTagsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AddTags not implemented")
 }
@@ -257,6 +351,24 @@ func (UnimplementedMemeServiceServer) ApproveMeme(context.Context, *ApproveMemeR
 func (UnimplementedMemeServiceServer) UnapproveMeme(context.Context, *UnapproveMemeRequest) (*UnapproveMemeResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UnapproveMeme not implemented")
 }
+func (UnimplementedMemeServiceServer) GetDeletedMemes(context.Context, *GetDeletedMemesRequest) (*MemesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDeletedMemes not implemented")
+}
+func (UnimplementedMemeServiceServer) RestoreMeme(context.Context, *RestoreMemeRequest) (*RestoreMemeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreMeme not implemented")
+}
+func (UnimplementedMemeServiceServer) PurgeDeletedMemes(context.Context, *PurgeDeletedMemesRequest) (*PurgeDeletedMemesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeDeletedMemes not implemented")
+}
+func (UnimplementedMemeServiceServer) CreateUploadSlot(context.Context, *CreateUploadSlotRequest) (*UploadSlotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateUploadSlot not implemented")
+}
+func (UnimplementedMemeServiceServer) FinalizeUpload(context.Context, *FinalizeUploadRequest) (*MemeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FinalizeUpload not implemented")
+}
+func (UnimplementedMemeServiceServer) UpdateMemeSource(context.Context, *UpdateMemeSourceRequest) (*UpdateMemeSourceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateMemeSource not implemented")
+}
 func (UnimplementedMemeServiceServer) mustEmbedUnimplementedMemeServiceServer() {}
 func (UnimplementedMemeServiceServer) testEmbeddedByValue()                     {}
 
@@ -404,6 +516,24 @@ func _MemeService_SearchTags_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MemeService_Autocomplete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AutocompleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MemeServiceServer).Autocomplete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MemeService_Autocomplete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MemeServiceServer).Autocomplete(ctx, req.(*AutocompleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _MemeService_AddTags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(AddTagsRequest)
 	if err := dec(in); err != nil {
@@ -512,6 +642,114 @@ func _MemeService_UnapproveMeme_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MemeService_GetDeletedMemes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDeletedMemesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MemeServiceServer).GetDeletedMemes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MemeService_GetDeletedMemes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MemeServiceServer).GetDeletedMemes(ctx, req.(*GetDeletedMemesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MemeService_RestoreMeme_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreMemeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MemeServiceServer).RestoreMeme(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MemeService_RestoreMeme_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MemeServiceServer).RestoreMeme(ctx, req.(*RestoreMemeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MemeService_PurgeDeletedMemes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeDeletedMemesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MemeServiceServer).PurgeDeletedMemes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MemeService_PurgeDeletedMemes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MemeServiceServer).PurgeDeletedMemes(ctx, req.(*PurgeDeletedMemesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MemeService_CreateUploadSlot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateUploadSlotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MemeServiceServer).CreateUploadSlot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MemeService_CreateUploadSlot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MemeServiceServer).CreateUploadSlot(ctx, req.(*CreateUploadSlotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MemeService_FinalizeUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FinalizeUploadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MemeServiceServer).FinalizeUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MemeService_FinalizeUpload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MemeServiceServer).FinalizeUpload(ctx, req.(*FinalizeUploadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MemeService_UpdateMemeSource_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateMemeSourceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MemeServiceServer).UpdateMemeSource(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MemeService_UpdateMemeSource_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MemeServiceServer).UpdateMemeSource(ctx, req.(*UpdateMemeSourceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // MemeService_ServiceDesc is the grpc.ServiceDesc for MemeService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -547,6 +785,10 @@ var MemeService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SearchTags",
 			Handler:    _MemeService_SearchTags_Handler,
 		},
+		{
+			MethodName: "Autocomplete",
+			Handler:    _MemeService_Autocomplete_Handler,
+		},
 		{
 			MethodName: "AddTags",
 			Handler:    _MemeService_AddTags_Handler,
@@ -571,6 +813,30 @@ var MemeService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UnapproveMeme",
 			Handler:    _MemeService_UnapproveMeme_Handler,
 		},
+		{
+			MethodName: "GetDeletedMemes",
+			Handler:    _MemeService_GetDeletedMemes_Handler,
+		},
+		{
+			MethodName: "RestoreMeme",
+			Handler:    _MemeService_RestoreMeme_Handler,
+		},
+		{
+			MethodName: "PurgeDeletedMemes",
+			Handler:    _MemeService_PurgeDeletedMemes_Handler,
+		},
+		{
+			MethodName: "CreateUploadSlot",
+			Handler:    _MemeService_CreateUploadSlot_Handler,
+		},
+		{
+			MethodName: "FinalizeUpload",
+			Handler:    _MemeService_FinalizeUpload_Handler,
+		},
+		{
+			MethodName: "UpdateMemeSource",
+			Handler:    _MemeService_UpdateMemeSource_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "meme.proto",
@@ -3,13 +3,13 @@ package rateLimiter
 import (
 	"fmt"
 	"log/slog"
-	"net"
 	"net/http"
-	"os"
-	"strings"
 	"sync"
 	"time"
 
+	"github.com/BassemHalim/memesHub/internal/apierror"
+	"github.com/BassemHalim/memesHub/internal/metrics"
+	"github.com/BassemHalim/memesHub/internal/utils"
 	"golang.org/x/time/rate"
 )
 
@@ -31,16 +31,14 @@ type RateLimiter struct {
 	log         *slog.Logger
 }
 
-func NewRateLimiter(rate rate.Limit, burst int) *RateLimiter {
-	opts := &slog.HandlerOptions{
-
-		Level: slog.LevelInfo,
-	}
+// NewRateLimiter logs to log, which should be the service's logger so its level follows the
+// config reloads
+func NewRateLimiter(rate rate.Limit, burst int, log *slog.Logger) *RateLimiter {
 	handler := RateLimiter{
 		clients: make(map[string]*ClientLimiter),
 		rate:    rate,
 		burst:   burst,
-		log:     slog.New(slog.NewJSONHandler(os.Stdout, opts)).With("MODULE", "RATE_LIMITER"),
+		log:     log.With("MODULE", "RATE_LIMITER"),
 	}
 
 	go handler.cleanUp()
@@ -48,6 +46,18 @@ func NewRateLimiter(rate rate.Limit, burst int) *RateLimiter {
 
 }
 
+// SetLimits changes the rate and burst of new and already seen clients
+func (l *RateLimiter) SetLimits(r rate.Limit, burst int) {
+	l.clientsLock.Lock()
+	defer l.clientsLock.Unlock()
+	l.rate = r
+	l.burst = burst
+	for _, client := range l.clients {
+		client.limiter.SetLimit(r)
+		client.limiter.SetBurst(burst)
+	}
+}
+
 // Remove old clients to reduce memory
 func (l *RateLimiter) cleanUp() {
 	for {
@@ -84,26 +94,7 @@ func (h *RateLimiter) getClientLimiter(ip string) *rate.Limiter {
 }
 
 func (h *RateLimiter) getIP(r *http.Request) string {
-	// Check common proxy headers
-	realIP := r.Header.Get("X-Real-IP")
-	// h.log.Debug("real ip", "X-Real-IP", realIP)
-	if realIP != "" {
-		return realIP
-	}
-
-	forwardedFor := r.Header.Get("X-Forwarded-For")
-	// h.log.Debug("forwarded ip", "X-Forwarded-For", forwardedFor)
-
-	if forwardedFor != "" {
-		// Take first IP in X-Forwarded-For list
-		if ips := strings.SplitN(forwardedFor, ",", 2); len(ips) > 0 {
-			return strings.TrimSpace(ips[0])
-		}
-	}
-
-	// Fallback to remote address
-	ip, _, _ := net.SplitHostPort(r.RemoteAddr)
-	return ip
+	return utils.ClientIP(r)
 }
 
 func (h *RateLimiter) RateLimit(next http.Handler) http.Handler {
@@ -111,9 +102,10 @@ func (h *RateLimiter) RateLimit(next http.Handler) http.Handler {
 		ip := h.getIP(r)
 
 		limiter := h.getClientLimiter(ip)
-		h.log.Info("Received request", "IP", ip, "tokens_available", fmt.Sprintf("%.3f",limiter.Tokens()))
+		h.log.DebugContext(r.Context(), "Received request", "IP", ip, "tokens_available", fmt.Sprintf("%.3f", limiter.Tokens()))
 		if !limiter.Allow() {
-			http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+			metrics.RateLimited()
+			apierror.Write(w, r, http.StatusTooManyRequests, "Rate limit exceeded", nil)
 			return
 		}
 
@@ -1,12 +1,17 @@
 package rateLimiter
 
 import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
 
 func TestBasic(t *testing.T) {
-	limiter := NewRateLimiter(1, 1)
+	limiter := NewRateLimiter(1, 1, slog.New(slog.DiscardHandler))
 	ip := "123"
 	client_limiter := limiter.getClientLimiter(ip)
 	if !client_limiter.Allow() {
@@ -15,7 +20,7 @@ func TestBasic(t *testing.T) {
 }
 
 func TestRateLimit(t *testing.T) {
-	limiter := NewRateLimiter(1, 1)
+	limiter := NewRateLimiter(1, 1, slog.New(slog.DiscardHandler))
 	ip := "123"
 	client_limiter := limiter.getClientLimiter(ip)
 	client_limiter.Allow()
@@ -27,7 +32,7 @@ func TestRateLimit(t *testing.T) {
 }
 
 func TestLimitThenAccept(t *testing.T) {
-	limiter := NewRateLimiter(1, 1)
+	limiter := NewRateLimiter(1, 1, slog.New(slog.DiscardHandler))
 	ip := "123"
 	client_limiter := limiter.getClientLimiter(ip)
 	client_limiter.Allow()
@@ -43,7 +48,7 @@ func TestLimitThenAccept(t *testing.T) {
 }
 
 func TestMultipleClients(t *testing.T) {
-	limiter := NewRateLimiter(1, 1)
+	limiter := NewRateLimiter(1, 1, slog.New(slog.DiscardHandler))
 	ip1 := "123"
 	ip2 := "245"
 	client_limiter1 := limiter.getClientLimiter(ip1)
@@ -57,3 +62,42 @@ func TestMultipleClients(t *testing.T) {
 	}
 
 }
+
+func TestSetLimits(t *testing.T) {
+	limiter := NewRateLimiter(1, 1, slog.New(slog.DiscardHandler))
+	seen := limiter.getClientLimiter("123")
+	seen.Allow()
+
+	limiter.SetLimits(2, 3)
+	// clients that were already seen get the new limits too
+	if seen.Limit() != 2 || seen.Burst() != 3 {
+		t.Errorf("Expected rate 2 and burst 3, got %v and %d", seen.Limit(), seen.Burst())
+	}
+	fresh := limiter.getClientLimiter("245")
+	for i := 0; i < 3; i++ {
+		if !fresh.Allow() {
+			t.Errorf("Request %d of a new client should have been accepted", i)
+		}
+	}
+	if fresh.Allow() {
+		t.Errorf("This request should have been rejected")
+	}
+}
+
+// Requests are logged at debug, the level of the injected logger can change at runtime
+func TestRequestsLoggedAtDebug(t *testing.T) {
+	var out bytes.Buffer
+	lvl := new(slog.LevelVar)
+	limiter := NewRateLimiter(10, 10, slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: lvl})))
+	handler := limiter.RateLimit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if out.Len() != 0 {
+		t.Errorf("Requests shouldn't be logged at info, got %s", out.String())
+	}
+	lvl.Set(slog.LevelDebug)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if !strings.Contains(out.String(), "Received request") {
+		t.Errorf("Requests should be logged at debug, got %q", out.String())
+	}
+}
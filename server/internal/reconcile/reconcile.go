@@ -0,0 +1,176 @@
+package reconcile
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/BassemHalim/memesHub/internal/storage"
+)
+
+// old versions kept by UpdateMeme are renamed to {filename}_{unix timestamp}
+var archivedImage = regexp.MustCompile(`^.+\.[a-zA-Z0-9]+_\d+$`)
+
+// OrphanImage is an object in storage that no meme references
+type OrphanImage struct {
+	Filename     string
+	LastModified time.Time
+}
+
+// MissingImage is a meme whose media_url points at an object that doesn't exist
+type MissingImage struct {
+	MemeID   string
+	Filename string
+}
+
+// Report is the difference between the meme table and the stored images
+type Report struct {
+	OrphanImages  []OrphanImage
+	MissingImages []MissingImage
+	// images and memes younger than the grace period are skipped since their upload may still be committing
+	Skipped int
+}
+
+type Reconciler struct {
+	db      *sql.DB
+	storage storage.Storage
+	log     *slog.Logger
+	// minimum age of an unreferenced image or of a meme without its image before it is reported
+	gracePeriod time.Duration
+}
+
+func New(db *sql.DB, storage storage.Storage, log *slog.Logger, gracePeriod time.Duration) *Reconciler {
+	return &Reconciler{
+		db:          db,
+		storage:     storage,
+		log:         log,
+		gracePeriod: gracePeriod,
+	}
+}
+
+// Diff compares the images in storage with meme.media_url in both directions. The memes are
+// queried before storage is listed so a meme committed in between is never missing its image,
+// its image is at worst a recent orphan which the grace period skips.
+func (r *Reconciler) Diff(ctx context.Context) (*Report, error) {
+	// every meme row references its image, including the ones in the trash whose
+	// soft delete may still be pending in the storage outbox
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id::text, media_url, deleted_at IS NOT NULL, created_at
+		FROM meme
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query memes: %w", err)
+	}
+	defer rows.Close()
+
+	type meme struct {
+		id, filename string
+		deleted      bool
+		createdAt    time.Time
+	}
+	var memes []meme
+	for rows.Next() {
+		var m meme
+		var mediaURL string
+		if err := rows.Scan(&m.id, &mediaURL, &m.deleted, &m.createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan meme: %w", err)
+		}
+		m.filename = filepath.Base(mediaURL)
+		memes = append(memes, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query memes: %w", err)
+	}
+
+	images, err := r.storage.ListImages(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %w", err)
+	}
+	stored := make(map[string]bool, len(images))
+	for _, image := range images {
+		stored[image.Filename] = true
+	}
+
+	cutoff := time.Now().Add(-r.gracePeriod)
+	report := &Report{}
+	referenced := make(map[string]bool, len(memes))
+	for _, m := range memes {
+		referenced[m.filename] = true
+		if m.deleted || stored[m.filename] {
+			continue
+		}
+		if m.createdAt.After(cutoff) {
+			report.Skipped++
+			continue
+		}
+		report.MissingImages = append(report.MissingImages, MissingImage{MemeID: m.id, Filename: m.filename})
+	}
+
+	for _, image := range images {
+		if referenced[image.Filename] || archivedImage.MatchString(image.Filename) {
+			continue
+		}
+		if image.LastModified.After(cutoff) {
+			report.Skipped++
+			continue
+		}
+		report.OrphanImages = append(report.OrphanImages, OrphanImage{Filename: image.Filename, LastModified: image.LastModified})
+	}
+
+	sort.Slice(report.OrphanImages, func(i, j int) bool { return report.OrphanImages[i].Filename < report.OrphanImages[j].Filename })
+	sort.Slice(report.MissingImages, func(i, j int) bool { return report.MissingImages[i].MemeID < report.MissingImages[j].MemeID })
+	return report, nil
+}
+
+// Fix moves orphan images to the trash and, for memes with a missing image, tries to
+// restore the image from the trash before hiding the meme by soft deleting it.
+// It returns the number of problems it couldn't fix.
+func (r *Reconciler) Fix(ctx context.Context, report *Report) int {
+	failed := 0
+	for _, orphan := range report.OrphanImages {
+		if err := r.storage.SoftDeleteImage(ctx, orphan.Filename); err != nil {
+			r.log.Error("Failed to move orphan image to the trash", "Image", orphan.Filename, "Error", err)
+			failed++
+			continue
+		}
+		r.log.Info("Moved orphan image to the trash", "Image", orphan.Filename)
+	}
+
+	for _, missing := range report.MissingImages {
+		if _, err := r.storage.RestoreImage(ctx, missing.Filename); err == nil {
+			r.log.Info("Restored missing image from the trash", "MemeID", missing.MemeID, "Image", missing.Filename)
+			continue
+		}
+		_, err := r.db.ExecContext(ctx, `
+			UPDATE meme
+			SET deleted_at = NOW()
+			WHERE id = $1 AND deleted_at IS NULL
+		`, missing.MemeID)
+		if err != nil {
+			r.log.Error("Failed to hide meme with a missing image", "MemeID", missing.MemeID, "Error", err)
+			failed++
+			continue
+		}
+		r.log.Info("Moved meme with a missing image to the trash", "MemeID", missing.MemeID, "Image", missing.Filename)
+	}
+	return failed
+}
+
+// Print writes the report as a diff, "-" lines are images the database references but storage
+// doesn't have and "+" lines are images in storage the database doesn't reference
+func (report *Report) Print(w io.Writer) {
+	for _, missing := range report.MissingImages {
+		fmt.Fprintf(w, "- imgs/%s\t(meme %s, missing from storage)\n", missing.Filename, missing.MemeID)
+	}
+	for _, orphan := range report.OrphanImages {
+		fmt.Fprintf(w, "+ imgs/%s\t(orphan, last modified %s)\n", orphan.Filename, orphan.LastModified.UTC().Format(time.RFC3339))
+	}
+	fmt.Fprintf(w, "%d missing, %d orphans, %d skipped (younger than the grace period)\n",
+		len(report.MissingImages), len(report.OrphanImages), report.Skipped)
+}
@@ -0,0 +1,186 @@
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/BassemHalim/memesHub/internal/storage"
+)
+
+// fakeStorage lists a fixed set of images and records the soft deletes and restores
+type fakeStorage struct {
+	images      []storage.ImageInfo
+	trash       map[string]bool
+	softDeleted []string
+	restored    []string
+	// called when the images are listed
+	onList func()
+}
+
+func (f *fakeStorage) SaveImage(ctx context.Context, filename string, image io.Reader) (storage.ImageInfo, error) {
+	return storage.ImageInfo{Filename: filename, URL: f.ImageUrl(filename)}, nil
+}
+
+func (f *fakeStorage) SoftDeleteImage(ctx context.Context, filename string) error {
+	f.softDeleted = append(f.softDeleted, filename)
+	return nil
+}
+
+func (f *fakeStorage) RestoreImage(ctx context.Context, filename string) (string, error) {
+	if !f.trash[filename] {
+		return "", errors.New("not in the trash")
+	}
+	f.restored = append(f.restored, filename)
+	return f.ImageUrl(filename), nil
+}
+
+func (f *fakeStorage) PurgeImage(ctx context.Context, filename string) error {
+	return nil
+}
+
+func (f *fakeStorage) RenameImage(ctx context.Context, oldFilename string, newFilename string) (string, error) {
+	return f.ImageUrl(newFilename), nil
+}
+
+func (f *fakeStorage) ImageUrl(filename string) string {
+	return "https://imgs.example.com/imgs/" + filename
+}
+
+func (f *fakeStorage) ListImages(ctx context.Context) ([]storage.ImageInfo, error) {
+	if f.onList != nil {
+		f.onList()
+	}
+	return f.images, nil
+}
+
+func (f *fakeStorage) OpenImage(ctx context.Context, filename string) (io.ReadCloser, storage.ImageInfo, error) {
+	return nil, storage.ImageInfo{}, errors.New("not implemented")
+}
+
+func (f *fakeStorage) PresignUpload(ctx context.Context, key string, contentType string, size int64, expires time.Duration) (storage.PresignedUpload, error) {
+	return storage.PresignedUpload{}, errors.New("not implemented")
+}
+
+func (f *fakeStorage) PublishUpload(ctx context.Context, key string, filename string) (storage.ImageInfo, error) {
+	return storage.ImageInfo{}, errors.New("not implemented")
+}
+
+func (f *fakeStorage) DeleteUpload(ctx context.Context, key string) error {
+	return errors.New("not implemented")
+}
+
+func (f *fakeStorage) Ping(ctx context.Context) error {
+	return nil
+}
+
+func newTestReconciler(t *testing.T, store *fakeStorage) (*Reconciler, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal("Failed to create sqlmock", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	log := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	return New(db, store, log, time.Hour), mock
+}
+
+func expectMemes(mock sqlmock.Sqlmock) {
+	old := time.Now().Add(-24 * time.Hour)
+	mock.ExpectQuery(`SELECT id::text, media_url, deleted_at IS NOT NULL, created_at\s+FROM meme`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "media_url", "deleted", "created_at"}).
+			AddRow("1", "https://imgs.example.com/imgs/kept.png", false, old).
+			AddRow("2", "https://imgs.example.com/imgs/missing.png", false, old).
+			AddRow("3", "https://imgs.example.com/imgs/trashed.png", true, old).
+			AddRow("4", "https://imgs.example.com/imgs/lost.png", false, old).
+			AddRow("5", "https://imgs.example.com/imgs/committing.png", false, time.Now()))
+}
+
+func testImages() []storage.ImageInfo {
+	old := time.Now().Add(-24 * time.Hour)
+	return []storage.ImageInfo{
+		{Filename: "kept.png", LastModified: old},
+		{Filename: "orphan.png", LastModified: old},
+		{Filename: "trashed.png", LastModified: old},
+		{Filename: "kept.png_1700000000", LastModified: old},
+		{Filename: "uploading.png", LastModified: time.Now()},
+	}
+}
+
+func TestDiff(t *testing.T) {
+	reconciler, mock := newTestReconciler(t, &fakeStorage{images: testImages()})
+	expectMemes(mock)
+
+	report, err := reconciler.Diff(context.Background())
+	if err != nil {
+		t.Fatal("Diff failed", err)
+	}
+	if len(report.OrphanImages) != 1 || report.OrphanImages[0].Filename != "orphan.png" {
+		t.Errorf("Expected orphan.png to be the only orphan, got %+v", report.OrphanImages)
+	}
+	if len(report.MissingImages) != 2 || report.MissingImages[0].MemeID != "2" || report.MissingImages[1].MemeID != "4" {
+		t.Errorf("Expected memes 2 and 4 to be missing their image, got %+v", report.MissingImages)
+	}
+	if report.Skipped != 2 {
+		t.Errorf("Expected the recent upload and meme to be skipped, got %d", report.Skipped)
+	}
+
+	var out bytes.Buffer
+	report.Print(&out)
+	if !strings.Contains(out.String(), "+ imgs/orphan.png") || !strings.Contains(out.String(), "- imgs/missing.png") {
+		t.Errorf("Unexpected diff output:\n%s", out.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDiffQueriesMemesBeforeListingImages(t *testing.T) {
+	store := &fakeStorage{images: testImages()}
+	reconciler, mock := newTestReconciler(t, store)
+	expectMemes(mock)
+	// a meme committed after the images are listed would otherwise be missing its image
+	store.onList = func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error("Images listed before the memes were queried:", err)
+		}
+	}
+
+	if _, err := reconciler.Diff(context.Background()); err != nil {
+		t.Fatal("Diff failed", err)
+	}
+}
+
+func TestFix(t *testing.T) {
+	store := &fakeStorage{images: testImages(), trash: map[string]bool{"missing.png": true}}
+	reconciler, mock := newTestReconciler(t, store)
+	expectMemes(mock)
+	report, err := reconciler.Diff(context.Background())
+	if err != nil {
+		t.Fatal("Diff failed", err)
+	}
+
+	// missing.png is restored from the trash, lost.png is gone so its meme is hidden
+	mock.ExpectExec(`UPDATE meme\s+SET deleted_at = NOW\(\)`).
+		WithArgs("4").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if failed := reconciler.Fix(context.Background(), report); failed != 0 {
+		t.Errorf("Expected every problem to be fixed, %d failed", failed)
+	}
+	if len(store.softDeleted) != 1 || store.softDeleted[0] != "orphan.png" {
+		t.Errorf("Expected only orphan.png to be moved to the trash, got %v", store.softDeleted)
+	}
+	if len(store.restored) != 1 || store.restored[0] != "missing.png" {
+		t.Errorf("Expected missing.png to be restored, got %v", store.restored)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
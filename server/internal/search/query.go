@@ -0,0 +1,301 @@
+// Package search parses the meme search syntax into a Query and compiles it to SQL.
+//
+//	drake "no yes" tag:funny -tag:nsfw source:reddit type:gif date:2024-01..2024-06
+//
+// Words are matched fuzzily against the name and tags of the memes like a plain search, quoted
+// phrases must appear as written. tag:, source: and type: filter on the tags, the social media
+// platform and the image format, date:, after: and before: on the upload date. A - in front of a
+// term excludes the memes matching it. Terms with an unknown field, e.g. "re:zero", are words.
+// The compiled SQL normalizes the Arabic terms like the arabic package so the spelling variants
+// of a word match.
+package search
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ErrEmpty is returned by Parse when the query has no terms
+var ErrEmpty = errors.New("empty search query")
+
+// Query is the AST of a search, the memes must match all of its nodes
+type Query struct {
+	Nodes []Node
+}
+
+// Node is a term of a Query
+type Node interface {
+	node()
+}
+
+// Word is a bare word matched against the name and tags
+type Word struct {
+	Text string
+}
+
+// Phrase is a quoted phrase the name or a tag must contain
+type Phrase struct {
+	Text string
+}
+
+// Tag matches the memes tagged Name, ignoring case and the Arabic spelling variants
+type Tag struct {
+	Name string
+}
+
+// Source matches the memes whose image comes from Platform, lower case
+type Source struct {
+	Platform string
+}
+
+// MediaType matches the memes whose image is of MimeType
+type MediaType struct {
+	MimeType string
+}
+
+// DateRange matches the memes created from From included to To excluded, a zero bound is open
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// Not matches the memes Node doesn't match
+type Not struct {
+	Node Node
+}
+
+func (Word) node()      {}
+func (Phrase) node()    {}
+func (Tag) node()       {}
+func (Source) node()    {}
+func (MediaType) node() {}
+func (DateRange) node() {}
+func (Not) node()       {}
+
+// Terms returns the words, phrases and tag names the memes must match, the terms a misspelling
+// can hide in. Excluded terms aren't returned.
+func (q *Query) Terms() []string {
+	var terms []string
+	for _, n := range q.Nodes {
+		switch n := n.(type) {
+		case Word:
+			terms = append(terms, n.Text)
+		case Phrase:
+			terms = append(terms, n.Text)
+		case Tag:
+			terms = append(terms, n.Name)
+		}
+	}
+	return terms
+}
+
+// mediaTypes maps the values of type: to the mime types stored in meme.media_type
+var mediaTypes = map[string]string{
+	"gif":  "image/gif",
+	"png":  "image/png",
+	"jpg":  "image/jpeg",
+	"jpeg": "image/jpeg",
+}
+
+// ParseMediaType returns the mime type of an image format, e.g. image/gif for gif, mime types are
+// returned as is when supported
+func ParseMediaType(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if mimeType, ok := mediaTypes[name]; ok {
+		return mimeType, nil
+	}
+	for _, mimeType := range mediaTypes {
+		if mimeType == name {
+			return mimeType, nil
+		}
+	}
+	return "", fmt.Errorf("%q isn't a supported image type, use gif, png or jpg", name)
+}
+
+// platformAliases maps the names people use for a platform to the one stored in
+// images.social_media_platform
+var platformAliases = map[string]string{
+	"twitter":  "x",
+	"facebook": "fb",
+}
+
+// dateLayouts are the precisions of a date, from the most precise
+var dateLayouts = []struct {
+	layout string
+	next   func(time.Time) time.Time
+}{
+	{"2006-01-02", func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }},
+	{"2006-01", func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }},
+	{"2006", func(t time.Time) time.Time { return t.AddDate(1, 0, 0) }},
+}
+
+// term is a token of the query before it is turned into a node
+type term struct {
+	negated bool
+	// empty for words and phrases
+	field  string
+	value  string
+	quoted bool
+	// the term as written, kept for unknown fields
+	raw string
+}
+
+// Parse parses a search query, it only fails on empty queries and invalid filter values
+func Parse(query string) (*Query, error) {
+	q := &Query{}
+	for _, t := range tokenize(query) {
+		n, err := t.node()
+		if err != nil {
+			return nil, err
+		}
+		if t.negated {
+			n = Not{Node: n}
+		}
+		q.Nodes = append(q.Nodes, n)
+	}
+	if len(q.Nodes) == 0 {
+		return nil, ErrEmpty
+	}
+	return q, nil
+}
+
+// tokenize splits query on spaces outside of quotes, an unterminated quote runs to the end
+func tokenize(query string) []term {
+	var terms []term
+	rs := []rune(query)
+	for i := 0; i < len(rs); {
+		if unicode.IsSpace(rs[i]) {
+			i++
+			continue
+		}
+		start := i
+		var t term
+		if rs[i] == '-' && i+1 < len(rs) && !unicode.IsSpace(rs[i+1]) {
+			t.negated = true
+			i++
+		}
+		// the field name, up to a colon
+		j := i
+		for j < len(rs) && rs[j] != ':' && rs[j] != '"' && !unicode.IsSpace(rs[j]) {
+			j++
+		}
+		if j < len(rs) && rs[j] == ':' && j > i {
+			t.field = strings.ToLower(string(rs[i:j]))
+			i = j + 1
+		}
+		if i < len(rs) && rs[i] == '"' {
+			end := i + 1
+			for end < len(rs) && rs[end] != '"' {
+				end++
+			}
+			t.value, t.quoted = string(rs[i+1:end]), true
+			i = min(end+1, len(rs))
+		} else {
+			end := i
+			for end < len(rs) && !unicode.IsSpace(rs[end]) {
+				end++
+			}
+			t.value = string(rs[i:end])
+			i = end
+		}
+		t.raw = string(rs[start:i])
+		if t.field == "" && strings.TrimSpace(t.value) == "" {
+			continue
+		}
+		terms = append(terms, t)
+	}
+	return terms
+}
+
+func (t term) node() (Node, error) {
+	value := strings.TrimSpace(t.value)
+	switch t.field {
+	case "":
+		if t.quoted {
+			return Phrase{Text: value}, nil
+		}
+		return Word{Text: value}, nil
+	case "tag", "source", "type", "date", "after", "before":
+		if value == "" {
+			return nil, fmt.Errorf("%s: needs a value", t.field)
+		}
+	default:
+		// not a filter, e.g. "re:zero"
+		return Word{Text: strings.TrimPrefix(t.raw, "-")}, nil
+	}
+
+	switch t.field {
+	case "tag":
+		return Tag{Name: value}, nil
+	case "source":
+		platform := strings.ToLower(value)
+		if alias, ok := platformAliases[platform]; ok {
+			platform = alias
+		}
+		return Source{Platform: platform}, nil
+	case "type":
+		mimeType, err := ParseMediaType(value)
+		if err != nil {
+			return nil, err
+		}
+		return MediaType{MimeType: mimeType}, nil
+	case "after":
+		from, _, err := parseDate(value)
+		if err != nil {
+			return nil, err
+		}
+		return DateRange{From: from}, nil
+	case "before":
+		to, _, err := parseDate(value)
+		if err != nil {
+			return nil, err
+		}
+		return DateRange{To: to}, nil
+	default:
+		return parseDateRange(value)
+	}
+}
+
+// parseDate returns the start and end of the day, month or year written in date
+func parseDate(date string) (time.Time, time.Time, error) {
+	for _, l := range dateLayouts {
+		if start, err := time.Parse(l.layout, date); err == nil {
+			return start, l.next(start), nil
+		}
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("%q isn't a date, use YYYY, YYYY-MM or YYYY-MM-DD", date)
+}
+
+// parseDateRange parses DATE or FROM..TO where either bound can be left out, TO is included
+func parseDateRange(value string) (Node, error) {
+	from, to, isRange := strings.Cut(value, "..")
+	if !isRange {
+		start, end, err := parseDate(value)
+		if err != nil {
+			return nil, err
+		}
+		return DateRange{From: start, To: end}, nil
+	}
+	var r DateRange
+	var err error
+	if from != "" {
+		if r.From, _, err = parseDate(from); err != nil {
+			return nil, err
+		}
+	}
+	if to != "" {
+		if _, r.To, err = parseDate(to); err != nil {
+			return nil, err
+		}
+	}
+	if r.From.IsZero() && r.To.IsZero() {
+		return nil, errors.New("date: needs at least one bound")
+	}
+	if !r.From.IsZero() && !r.To.IsZero() && !r.From.Before(r.To) {
+		return nil, fmt.Errorf("date:%s ends before it starts", value)
+	}
+	return r, nil
+}
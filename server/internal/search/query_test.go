@@ -0,0 +1,85 @@
+package search
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []Node
+	}{
+		{query: "drake meme", want: []Node{Word{"drake"}, Word{"meme"}}},
+		{query: "  ضحك   ", want: []Node{Word{"ضحك"}}},
+		{query: `"no yes" drake`, want: []Node{Phrase{"no yes"}, Word{"drake"}}},
+		{query: `"unterminated phrase`, want: []Node{Phrase{"unterminated phrase"}}},
+		{query: "tag:funny -tag:nsfw", want: []Node{Tag{"funny"}, Not{Tag{"nsfw"}}}},
+		{query: `TAG:"two words"`, want: []Node{Tag{"two words"}}},
+		{query: "source:Reddit source:twitter", want: []Node{Source{"reddit"}, Source{"x"}}},
+		{query: "type:GIF -type:jpg type:image/png", want: []Node{MediaType{"image/gif"}, Not{MediaType{"image/jpeg"}}, MediaType{"image/png"}}},
+		{query: "-cat -", want: []Node{Not{Word{"cat"}}, Word{"-"}}},
+		{query: `-"bad joke"`, want: []Node{Not{Phrase{"bad joke"}}}},
+		// unknown fields are plain words
+		{query: "re:zero https://x.com", want: []Node{Word{"re:zero"}, Word{"https://x.com"}}},
+		{query: "date:2024", want: []Node{DateRange{From: date("2024-01-01"), To: date("2025-01-01")}}},
+		{query: "date:2024-02", want: []Node{DateRange{From: date("2024-02-01"), To: date("2024-03-01")}}},
+		{query: "date:2024-02-29", want: []Node{DateRange{From: date("2024-02-29"), To: date("2024-03-01")}}},
+		{query: "date:2024-01..2024-03", want: []Node{DateRange{From: date("2024-01-01"), To: date("2024-04-01")}}},
+		{query: "date:2024.. date:..2023-12-31", want: []Node{DateRange{From: date("2024-01-01")}, DateRange{To: date("2024-01-01")}}},
+		{query: "after:2024-05 before:2024-06-15", want: []Node{DateRange{From: date("2024-05-01")}, DateRange{To: date("2024-06-15")}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(q.Nodes, tt.want) {
+				t.Errorf("Parse(%q) = %#v, want %#v", tt.query, q.Nodes, tt.want)
+			}
+		})
+	}
+}
+
+func TestTerms(t *testing.T) {
+	q, err := Parse(`drak "no yes" tag:funy -tag:nsfw -cat source:reddit type:gif date:2024`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := q.Terms(), []string{"drak", "no yes", "funy"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Terms() = %q, want %q", got, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, query := range []string{
+		"tag:",
+		"type:bmp",
+		"type:video/mp4",
+		"date:yesterday",
+		"date:..",
+		"date:2024-03..2024-01",
+		"after:2024-13",
+		"funny source:",
+	} {
+		if _, err := Parse(query); err == nil {
+			t.Errorf("Parse(%q) should fail", query)
+		}
+	}
+	for _, query := range []string{"", "   ", `""`} {
+		if _, err := Parse(query); !errors.Is(err, ErrEmpty) {
+			t.Errorf("Parse(%q) = %v, want ErrEmpty", query, err)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package search
+
+import (
+	"database/sql"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// rankedMeme is a meme seeded for the ranking tests, every meme matches "zorblax" equally
+type rankedMeme struct {
+	name      string
+	downloads int
+	age       time.Duration
+}
+
+// seedRankedMemes inserts memes in a transaction rolled back at the end of the test. It needs a
+// database with the migrations applied, like the one of postgres.Dockerfile.
+func seedRankedMemes(t *testing.T, memes []rankedMeme) (*sql.Tx, map[string]string) {
+	url := os.Getenv("MEMEHUB_TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("MEMEHUB_TEST_DATABASE_URL must be set to test the ranking against Postgres")
+	}
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { tx.Rollback() })
+
+	var tagID int
+	err = tx.QueryRow(`INSERT INTO tag (name, normalized_name) VALUES ('zorblax', 'zorblax') ON CONFLICT (normalized_name) DO UPDATE SET name = tag.name RETURNING id`).Scan(&tagID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]string{}
+	for _, m := range memes {
+		var id string
+		err := tx.QueryRow(`
+			INSERT INTO meme (media_url, media_type, name, dimensions, approval_status, download_count, created_at)
+			VALUES ('https://imgs.example.com/imgs/zorblax.png', 'image/png', 'zorblax', '{1,1}', 'approved', $1, NOW() - $2 * INTERVAL '1 second')
+			RETURNING id::text
+		`, m.downloads, m.age.Seconds()).Scan(&id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the trigger of meme_tag indexes the name and tags
+		if _, err := tx.Exec(`INSERT INTO meme_tag (meme_id, tag_id) VALUES ($1, $2)`, id, tagID); err != nil {
+			t.Fatal(err)
+		}
+		names[id] = m.name
+	}
+	return tx, names
+}
+
+func TestRankingOrder(t *testing.T) {
+	day := 24 * time.Hour
+	tx, names := seedRankedMemes(t, []rankedMeme{
+		{name: "old", downloads: 0, age: 730 * day},
+		{name: "popular", downloads: 5000, age: 365 * day},
+		{name: "recent", downloads: 10, age: day},
+	})
+	ranking := Ranking{Text: 1, Downloads: 0.1, Recency: 0.5, RecencyHalfLife: 30 * day}
+
+	tests := []struct {
+		query string
+		opts  Options
+		want  []string
+	}{
+		// the text ranks are equal so engagement and then recency decide
+		{query: "zorblax", opts: Options{Ranking: ranking}, want: []string{"popular", "recent", "old"}},
+		{query: "zorblax", opts: Options{Ranking: Ranking{Text: 1, Recency: 1, RecencyHalfLife: 30 * day}}, want: []string{"recent", "popular", "old"}},
+		{query: "tag:zorblax", opts: Options{Ranking: ranking}, want: []string{"popular", "recent", "old"}},
+		{query: "zorblax", opts: Options{Sort: SortNewest, Ranking: ranking}, want: []string{"recent", "popular", "old"}},
+		{query: "zorblax", opts: Options{Sort: SortMostDownloaded, Ranking: ranking}, want: []string{"popular", "recent", "old"}},
+	}
+	for _, tt := range tests {
+		q, err := Parse(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stmt := Compile(q, tt.opts)
+		rows, err := tx.Query(stmt.SQL, stmt.Args...)
+		if err != nil {
+			t.Fatalf("%s: %v", stmt.SQL, err)
+		}
+		var got []string
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				t.Fatal(err)
+			}
+			// other memes of the database can match too
+			if name, ok := names[id]; ok {
+				got = append(got, name)
+			}
+		}
+		rows.Close()
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%q %+v ordered %v, want %v", tt.query, tt.opts, got, tt.want)
+		}
+	}
+}
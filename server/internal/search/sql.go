@@ -0,0 +1,158 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Statement is a compiled Query, a SELECT of the matching meme IDs as text ordered by relevance
+type Statement struct {
+	SQL  string
+	Args []any
+}
+
+// compiler collects the arguments of the statement, each value gets the next $n
+type compiler struct {
+	args []any
+}
+
+func (c *compiler) arg(v any) string {
+	c.args = append(c.args, v)
+	return fmt.Sprintf("$%d", len(c.args))
+}
+
+// Sort is the order of the search results
+type Sort int
+
+const (
+	// SortRelevance ranks the memes by their Ranking score
+	SortRelevance Sort = iota
+	SortNewest
+	SortMostDownloaded
+)
+
+// Ranking weighs what makes a meme relevant, its score is the sum of its text rank, the
+// logarithms of its download and share counts and its recency, each multiplied by their weight.
+// The recency of a meme starts at 1 and halves every RecencyHalfLife. A zero Ranking orders by
+// text rank.
+type Ranking struct {
+	Text            float64
+	Downloads       float64
+	Shares          float64
+	Recency         float64
+	RecencyHalfLife time.Duration
+}
+
+type Options struct {
+	Sort    Sort
+	Ranking Ranking
+}
+
+// Compile compiles q for the meme schema. The words and phrases are searched with
+// search_memes_fuzzy, the other terms only filter the results. Queries without words have no
+// text rank so they are ranked by engagement and recency.
+func Compile(q *Query, opts Options) Statement {
+	c := &compiler{}
+	var text []string
+	var conditions []string
+	for _, n := range q.Nodes {
+		switch n := n.(type) {
+		case Word:
+			text = append(text, n.Text)
+		case Phrase:
+			// the fuzzy search ranks the phrase higher but doesn't require it
+			text = append(text, `"`+n.Text+`"`)
+			conditions = append(conditions, c.condition(n))
+		default:
+			conditions = append(conditions, c.condition(n))
+		}
+	}
+
+	var sql strings.Builder
+	hasText := len(text) > 0
+	if hasText {
+		fmt.Fprintf(&sql, "SELECT m.id::text FROM search_memes_fuzzy(%s) f JOIN meme m ON m.id = f.id", c.arg(strings.Join(text, " ")))
+	} else {
+		sql.WriteString("SELECT m.id::text FROM meme m")
+		conditions = append([]string{"m.approval_status = 'approved'", "m.deleted_at IS NULL"}, conditions...)
+	}
+	if len(conditions) > 0 {
+		sql.WriteString(" WHERE ")
+		sql.WriteString(strings.Join(conditions, " AND "))
+	}
+	sql.WriteString(" ORDER BY ")
+	sql.WriteString(c.order(opts, hasText))
+	return Statement{SQL: sql.String(), Args: c.args}
+}
+
+// order returns the ORDER BY clause of opts, the ID breaks ties so pages don't overlap
+func (c *compiler) order(opts Options, hasText bool) string {
+	switch opts.Sort {
+	case SortNewest:
+		return "m.created_at DESC, m.id"
+	case SortMostDownloaded:
+		return "m.download_count DESC, m.created_at DESC, m.id"
+	}
+	if score := c.score(opts.Ranking, hasText); score != "" {
+		return score + " DESC, m.id"
+	}
+	if hasText {
+		return "f.rank DESC, m.id"
+	}
+	return "m.created_at DESC, m.id"
+}
+
+// score returns the expression of the Ranking score of m, empty when every weight is 0
+func (c *compiler) score(r Ranking, hasText bool) string {
+	var terms []string
+	if hasText && r.Text > 0 {
+		terms = append(terms, fmt.Sprintf("%s::float8 * f.rank", c.arg(r.Text)))
+	}
+	if r.Downloads > 0 {
+		terms = append(terms, fmt.Sprintf("%s::float8 * ln(1 + m.download_count::float8)", c.arg(r.Downloads)))
+	}
+	if r.Shares > 0 {
+		terms = append(terms, fmt.Sprintf("%s::float8 * ln(1 + m.share_count::float8)", c.arg(r.Shares)))
+	}
+	if r.Recency > 0 && r.RecencyHalfLife > 0 {
+		// a NULL score would sort first
+		terms = append(terms, fmt.Sprintf("%s::float8 * COALESCE(power(0.5, extract(epoch FROM now() - m.created_at)::float8 / %s::float8), 0)",
+			c.arg(r.Recency), c.arg(r.RecencyHalfLife.Seconds())))
+	}
+	if len(terms) == 0 {
+		return ""
+	}
+	return "(" + strings.Join(terms, " + ") + ")"
+}
+
+// condition returns the WHERE condition matching the memes of n
+func (c *compiler) condition(n Node) string {
+	switch n := n.(type) {
+	case Word:
+		p := c.arg(n.Text)
+		return fmt.Sprintf("m.search_vector @@ (plainto_tsquery('english', normalize_arabic(%[1]s)) || plainto_tsquery('arabic', normalize_arabic(%[1]s)))", p)
+	case Phrase:
+		p := c.arg(n.Text)
+		return fmt.Sprintf("m.search_vector @@ (phraseto_tsquery('english', normalize_arabic(%[1]s)) || phraseto_tsquery('arabic', normalize_arabic(%[1]s)))", p)
+	case Tag:
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM meme_tag mt JOIN tag t ON t.id = mt.tag_id WHERE mt.meme_id = m.id AND t.normalized_name = lower(normalize_arabic(%s)))", c.arg(n.Name))
+	case Source:
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM images i WHERE i.meme_id = m.id AND lower(i.social_media_platform) = %s)", c.arg(n.Platform))
+	case MediaType:
+		return fmt.Sprintf("m.media_type = %s", c.arg(n.MimeType))
+	case DateRange:
+		var bounds []string
+		if !n.From.IsZero() {
+			bounds = append(bounds, fmt.Sprintf("m.created_at >= %s", c.arg(n.From)))
+		}
+		if !n.To.IsZero() {
+			bounds = append(bounds, fmt.Sprintf("m.created_at < %s", c.arg(n.To)))
+		}
+		return "(" + strings.Join(bounds, " AND ") + ")"
+	case Not:
+		return "NOT (" + c.condition(n.Node) + ")"
+	default:
+		panic(fmt.Sprintf("search: unknown node %T", n))
+	}
+}
@@ -0,0 +1,101 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		opts     Options
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "plain text is a fuzzy search",
+			query:    "drake  meme",
+			wantSQL:  "SELECT m.id::text FROM search_memes_fuzzy($1) f JOIN meme m ON m.id = f.id ORDER BY f.rank DESC, m.id",
+			wantArgs: []any{"drake meme"},
+		},
+		{
+			name:  "phrases are ranked and required",
+			query: `drake "no yes"`,
+			wantSQL: "SELECT m.id::text FROM search_memes_fuzzy($2) f JOIN meme m ON m.id = f.id" +
+				" WHERE m.search_vector @@ (phraseto_tsquery('english', normalize_arabic($1)) || phraseto_tsquery('arabic', normalize_arabic($1)))" +
+				" ORDER BY f.rank DESC, m.id",
+			wantArgs: []any{"no yes", `drake "no yes"`},
+		},
+		{
+			name:  "filters only list the newest memes",
+			query: "tag:funny -source:reddit type:gif",
+			wantSQL: "SELECT m.id::text FROM meme m WHERE m.approval_status = 'approved' AND m.deleted_at IS NULL" +
+				" AND EXISTS (SELECT 1 FROM meme_tag mt JOIN tag t ON t.id = mt.tag_id WHERE mt.meme_id = m.id AND t.normalized_name = lower(normalize_arabic($1)))" +
+				" AND NOT (EXISTS (SELECT 1 FROM images i WHERE i.meme_id = m.id AND lower(i.social_media_platform) = $2))" +
+				" AND m.media_type = $3" +
+				" ORDER BY m.created_at DESC, m.id",
+			wantArgs: []any{"funny", "reddit", "image/gif"},
+		},
+		{
+			name:  "excluded words and dates",
+			query: "cat -dog date:2024-01..2024-02",
+			wantSQL: "SELECT m.id::text FROM search_memes_fuzzy($4) f JOIN meme m ON m.id = f.id" +
+				" WHERE NOT (m.search_vector @@ (plainto_tsquery('english', normalize_arabic($1)) || plainto_tsquery('arabic', normalize_arabic($1))))" +
+				" AND (m.created_at >= $2 AND m.created_at < $3)" +
+				" ORDER BY f.rank DESC, m.id",
+			wantArgs: []any{"dog", date("2024-01-01"), date("2024-03-01"), "cat"},
+		},
+		{
+			name:     "newest",
+			query:    "cat",
+			opts:     Options{Sort: SortNewest, Ranking: Ranking{Text: 1}},
+			wantSQL:  "SELECT m.id::text FROM search_memes_fuzzy($1) f JOIN meme m ON m.id = f.id ORDER BY m.created_at DESC, m.id",
+			wantArgs: []any{"cat"},
+		},
+		{
+			name:     "most downloaded",
+			query:    "type:gif",
+			opts:     Options{Sort: SortMostDownloaded},
+			wantSQL:  "SELECT m.id::text FROM meme m WHERE m.approval_status = 'approved' AND m.deleted_at IS NULL AND m.media_type = $1 ORDER BY m.download_count DESC, m.created_at DESC, m.id",
+			wantArgs: []any{"image/gif"},
+		},
+		{
+			name:  "relevance blends the text rank with engagement and recency",
+			query: "cat",
+			opts:  Options{Ranking: Ranking{Text: 1, Downloads: 0.1, Shares: 0.2, Recency: 0.5, RecencyHalfLife: 24 * time.Hour}},
+			wantSQL: "SELECT m.id::text FROM search_memes_fuzzy($1) f JOIN meme m ON m.id = f.id ORDER BY (" +
+				"$2::float8 * f.rank" +
+				" + $3::float8 * ln(1 + m.download_count::float8)" +
+				" + $4::float8 * ln(1 + m.share_count::float8)" +
+				" + $5::float8 * COALESCE(power(0.5, extract(epoch FROM now() - m.created_at)::float8 / $6::float8), 0)" +
+				") DESC, m.id",
+			wantArgs: []any{"cat", 1.0, 0.1, 0.2, 0.5, 86400.0},
+		},
+		{
+			name:  "relevance without words has no text rank",
+			query: "tag:funny",
+			opts:  Options{Ranking: Ranking{Text: 1, Downloads: 0.1}},
+			wantSQL: "SELECT m.id::text FROM meme m WHERE m.approval_status = 'approved' AND m.deleted_at IS NULL" +
+				" AND EXISTS (SELECT 1 FROM meme_tag mt JOIN tag t ON t.id = mt.tag_id WHERE mt.meme_id = m.id AND t.normalized_name = lower(normalize_arabic($1)))" +
+				" ORDER BY ($2::float8 * ln(1 + m.download_count::float8)) DESC, m.id",
+			wantArgs: []any{"funny", 0.1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stmt := Compile(q, tt.opts)
+			if stmt.SQL != tt.wantSQL {
+				t.Errorf("SQL =\n%s\nwant\n%s", stmt.SQL, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(stmt.Args, tt.wantArgs) {
+				t.Errorf("Args = %#v, want %#v", stmt.Args, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -5,16 +5,13 @@ import (
 	"net/http"
 	"os"
 	"sync"
+
+	"github.com/BassemHalim/memesHub/internal/apierror"
+	"github.com/BassemHalim/memesHub/pkg/api"
 )
 
 const bannerFile = "banner.json"
 
-type BannerConfig struct {
-	Text    string `json:"text"`
-	BgColor string `json:"bgColor"`
-	FgColor string `json:"fgColor"`
-}
-
 var bannerMu sync.RWMutex
 
 // GET /api/banner
@@ -22,28 +19,29 @@ func (s *Server) GetBanner(w http.ResponseWriter, r *http.Request) {
 	bannerMu.RLock()
 	defer bannerMu.RUnlock()
 
-	data, err := os.ReadFile(bannerFile)
-	if err != nil {
-		// No banner file = empty banner
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(BannerConfig{})
-		return
+	// No banner file = empty banner
+	var banner api.Banner
+	if data, err := os.ReadFile(bannerFile); err == nil {
+		if err := json.Unmarshal(data, &banner); err != nil {
+			s.handleError(w, r, err, "failed to decode banner", http.StatusInternalServerError)
+			return
+		}
 	}
 	w.Header().Set("Content-Type", "application/json")
-	w.Write(data)
+	json.NewEncoder(w).Encode(banner)
 }
 
 // PUT /api/admin/banner
 func (s *Server) UpdateBanner(w http.ResponseWriter, r *http.Request) {
-	var cfg BannerConfig
+	var cfg api.Banner
 	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
-		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		apierror.Write(w, r, http.StatusBadRequest, "invalid JSON", nil)
 		return
 	}
 
 	data, err := json.Marshal(cfg)
 	if err != nil {
-		s.handleError(w, err, "failed to encode banner", http.StatusInternalServerError)
+		s.handleError(w, r, err, "failed to encode banner", http.StatusInternalServerError)
 		return
 	}
 
@@ -51,9 +49,9 @@ func (s *Server) UpdateBanner(w http.ResponseWriter, r *http.Request) {
 	defer bannerMu.Unlock()
 
 	if err := os.WriteFile(bannerFile, data, 0644); err != nil {
-		s.handleError(w, err, "failed to write banner file", http.StatusInternalServerError)
+		s.handleError(w, r, err, "failed to write banner file", http.StatusInternalServerError)
 		return
 	}
-	s.log.Info("Banner updated", "text", cfg.Text)
+	s.log.InfoContext(r.Context(), "Banner updated", "text", cfg.Text)
 	w.WriteHeader(http.StatusOK)
 }
@@ -0,0 +1,380 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"go.yaml.in/yaml/v3"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/BassemHalim/memesHub/internal/auth"
+	"github.com/BassemHalim/memesHub/internal/config"
+	pb "github.com/BassemHalim/memesHub/internal/proto/memeService"
+	rateLimiter "github.com/BassemHalim/memesHub/internal/rate-limiter/IP_ratelimiter"
+	"github.com/BassemHalim/memesHub/pkg/api"
+)
+
+// The contract tests fail when pkg/api/openapi.yaml and the handlers drift apart: every route is
+// documented, every schema matches its api type and the responses of the handlers match the
+// schemas documented for their status code.
+
+const (
+	contractJWTSecret = "contract-secret"
+	contractMemeID    = "7218d21c-ac37-4ebe-b436-c51486d23b95"
+	missingMemeID     = "fc2ee84a-873a-4c18-a558-82f7ca050010"
+)
+
+type openAPIDoc map[string]any
+
+func loadOpenAPI(t *testing.T) openAPIDoc {
+	t.Helper()
+	// unmarshalling into openAPIDoc would decode the nested maps as openAPIDoc too
+	var doc map[string]any
+	if err := yaml.Unmarshal(api.OpenAPI, &doc); err != nil {
+		t.Fatal("The OpenAPI document isn't valid YAML", err)
+	}
+	return doc
+}
+
+// resolve follows a local $ref like "#/components/schemas/Meme"
+func (doc openAPIDoc) resolve(t *testing.T, node map[string]any) map[string]any {
+	t.Helper()
+	ref, ok := node["$ref"].(string)
+	if !ok {
+		return node
+	}
+	var current any = map[string]any(doc)
+	for _, key := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		m, ok := current.(map[string]any)
+		if !ok {
+			t.Fatalf("Unresolvable $ref %s", ref)
+		}
+		current = m[key]
+	}
+	resolved, ok := current.(map[string]any)
+	if !ok {
+		t.Fatalf("Unresolvable $ref %s", ref)
+	}
+	return doc.resolve(t, resolved)
+}
+
+func (doc openAPIDoc) operations() map[string]map[string]any {
+	ops := map[string]map[string]any{}
+	paths, _ := doc["paths"].(map[string]any)
+	for path, item := range paths {
+		for method, op := range item.(map[string]any) {
+			ops[strings.ToUpper(method)+" "+path] = op.(map[string]any)
+		}
+	}
+	return ops
+}
+
+// validate checks value against schema: the required properties are present, no property is
+// undocumented and the JSON types match
+func (doc openAPIDoc) validate(t *testing.T, schema map[string]any, value any, path string) {
+	t.Helper()
+	schema = doc.resolve(t, schema)
+	switch schema["type"] {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			t.Errorf("%s: expected an object, got %v", path, value)
+			return
+		}
+		required, _ := schema["required"].([]any)
+		for _, name := range required {
+			if _, ok := obj[name.(string)]; !ok {
+				t.Errorf("%s: missing the required property %s", path, name)
+			}
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		additional, _ := schema["additionalProperties"].(map[string]any)
+		for name, v := range obj {
+			if prop, ok := properties[name].(map[string]any); ok {
+				doc.validate(t, prop, v, path+"."+name)
+			} else if additional != nil {
+				doc.validate(t, additional, v, path+"."+name)
+			} else if properties != nil {
+				t.Errorf("%s: undocumented property %s", path, name)
+			}
+		}
+	case "array":
+		arr, ok := value.([]any)
+		if !ok {
+			t.Errorf("%s: expected an array, got %v", path, value)
+			return
+		}
+		for i, v := range arr {
+			doc.validate(t, schema["items"].(map[string]any), v, fmt.Sprintf("%s[%d]", path, i))
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			t.Errorf("%s: expected a string, got %v", path, value)
+		}
+	case "integer":
+		if n, ok := value.(float64); !ok || n != float64(int64(n)) {
+			t.Errorf("%s: expected an integer, got %v", path, value)
+		}
+	}
+}
+
+func newContractRouter(t *testing.T) http.Handler {
+	t.Helper()
+	client := &MockMemeService{
+		GetMemeFunc: func(ctx context.Context, in *pb.GetMemeRequest) (*pb.MemeResponse, error) {
+			if in.Id == missingMemeID {
+				return nil, status.Error(codes.NotFound, "Meme not found")
+			}
+			return &pb.MemeResponse{
+				Id: in.Id, Name: "with source", MediaUrl: "https://example.com/a.png", MediaType: "image/png",
+				Tags: []string{"cat"}, Dimensions: []int32{10, 10}, DownloadCount: 3,
+				Source: &pb.MemeSource{Platform: "Reddit", Url: "https://i.redd.it/a.png"},
+			}, nil
+		},
+		GetTimelineMemesFunc: func(ctx context.Context, in *pb.GetTimelineRequest) (*pb.MemesResponse, error) {
+			return &pb.MemesResponse{
+				Memes: []*pb.MemeResponse{
+					{Id: contractMemeID, Name: "zero counts", Tags: []string{"cat"}},
+					{Id: missingMemeID, Name: "no tags", DownloadCount: 1, ShareCount: 2},
+				},
+				TotalCount: 2, Page: in.Page, TotalPages: 1,
+			}, nil
+		},
+		SearchMemesFunc: func(ctx context.Context, in *pb.SearchMemesRequest) (*pb.MemesResponse, error) {
+			return &pb.MemesResponse{Page: in.Page, Suggestions: []string{"cats"}, RelatedTags: []string{"funny"}}, nil
+		},
+		AutocompleteFunc: func(ctx context.Context, in *pb.AutocompleteRequest) (*pb.AutocompleteResponse, error) {
+			return &pb.AutocompleteResponse{Completions: []*pb.Completion{
+				{Text: "cat", Kind: pb.CompletionKind_COMPLETION_TAG},
+				{Text: "cat vibing", Kind: pb.CompletionKind_COMPLETION_MEME},
+			}}, nil
+		},
+		SearchTagsFunc: func(ctx context.Context, in *pb.SearchTagsRequest) (*pb.TagsResponse, error) {
+			return &pb.TagsResponse{}, nil
+		},
+		GetDeletedMemesFunc: func(ctx context.Context, in *pb.GetDeletedMemesRequest) (*pb.MemesResponse, error) {
+			return &pb.MemesResponse{
+				Memes: []*pb.MemeResponse{{Id: contractMemeID, DeletedAt: time.Now().Format(time.RFC3339)}},
+			}, nil
+		},
+		CreateUploadSlotFunc: func(ctx context.Context, in *pb.CreateUploadSlotRequest) (*pb.UploadSlotResponse, error) {
+			return &pb.UploadSlotResponse{Id: contractMemeID, UploadUrl: "https://example.com/upload", Method: http.MethodPut}, nil
+		},
+		FinalizeUploadFunc: func(ctx context.Context, in *pb.FinalizeUploadRequest) (*pb.MemeResponse, error) {
+			return &pb.MemeResponse{Id: in.SlotId, Name: in.Name, Tags: in.Tags, Dimensions: []int32{10, 10}}, nil
+		},
+	}
+	server, err := NewWithMemeService(client, config.NewStore(&config.Config{MaxUploadSize: 2000}), rateLimiter.NewRateLimiter(rate.Inf, 1, GetDebugLogger()), GetDebugLogger(), nil, cache.New(time.Minute, time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	passHash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server.Router(RouterOptions{
+		Admin:     auth.New(contractJWTSecret, "admin", string(passHash), GetDebugLogger()),
+		JWTSecret: contractJWTSecret,
+	})
+}
+
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	doc := loadOpenAPI(t)
+	server, _ := NewWithMemeService(&MockMemeService{}, nil, rateLimiter.NewRateLimiter(1, 1, GetDebugLogger()), GetDebugLogger(), nil, MemCache)
+
+	var routes []string
+	for _, r := range server.routes(RouterOptions{Admin: &auth.Admin{}}) {
+		routes = append(routes, r.method+" "+r.path)
+	}
+	var documented []string
+	for op := range doc.operations() {
+		documented = append(documented, op)
+	}
+	sort.Strings(routes)
+	sort.Strings(documented)
+	for _, r := range routes {
+		if !slices.Contains(documented, r) {
+			t.Errorf("%s isn't documented in openapi.yaml", r)
+		}
+	}
+	for _, op := range documented {
+		if !slices.Contains(routes, op) {
+			t.Errorf("%s is documented in openapi.yaml but isn't routed", op)
+		}
+	}
+}
+
+func TestOpenAPISchemasMatchTypes(t *testing.T) {
+	doc := loadOpenAPI(t)
+	types := map[string]any{
+		"Meme":                  api.Meme{},
+		"Source":                api.Source{},
+		"MemePage":              api.MemePage{},
+		"TagList":               api.TagList{},
+		"Completion":            api.Completion{},
+		"CompletionList":        api.CompletionList{},
+		"UploadSlot":            api.UploadSlot{},
+		"LoginResponse":         api.LoginResponse{},
+		"Banner":                api.Banner{},
+		"Error":                 api.Error{},
+		"UploadRequest":         api.UploadRequest{},
+		"UploadSlotRequest":     api.UploadSlotRequest{},
+		"FinalizeUploadRequest": api.FinalizeUploadRequest{},
+		"SourceRequest":         api.SourceRequest{},
+		"AddTagsRequest":        api.AddTagsRequest{},
+		"PatchRequest":          api.PatchRequest{},
+	}
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	for name := range schemas {
+		if _, ok := types[name]; !ok {
+			t.Errorf("The schema %s has no api type", name)
+		}
+	}
+	for name, v := range types {
+		schema, ok := schemas[name].(map[string]any)
+		if !ok {
+			t.Errorf("The api type %s has no schema", name)
+			continue
+		}
+		var properties, required []string
+		typ := reflect.TypeOf(v)
+		for i := range typ.NumField() {
+			tag, opts, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+			properties = append(properties, tag)
+			if !strings.Contains(opts, "omitempty") {
+				required = append(required, tag)
+			}
+		}
+		var documented, documentedRequired []string
+		for prop := range schema["properties"].(map[string]any) {
+			documented = append(documented, prop)
+		}
+		requiredProps, _ := schema["required"].([]any)
+		for _, prop := range requiredProps {
+			documentedRequired = append(documentedRequired, prop.(string))
+		}
+		for _, s := range [][]string{properties, required, documented, documentedRequired} {
+			sort.Strings(s)
+		}
+		if !slices.Equal(properties, documented) {
+			t.Errorf("%s: the type has the properties %v, the schema %v", name, properties, documented)
+		}
+		if !slices.Equal(required, documentedRequired) {
+			t.Errorf("%s: the type always encodes %v, the schema requires %v", name, required, documentedRequired)
+		}
+	}
+}
+
+func TestResponsesMatchOpenAPI(t *testing.T) {
+	doc := loadOpenAPI(t)
+	ops := doc.operations()
+	router := newContractRouter(t)
+	token, err := auth.New(contractJWTSecret, "admin", "", GetDebugLogger()).GenerateAdminJWT("admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		operation string
+		path      string
+		body      string
+		admin     bool
+		status    int
+	}{
+		{"GET /memes", "/memes?sort=newest", "", false, http.StatusOK},
+		{"GET /memes", "/memes?sort=random", "", false, http.StatusBadRequest},
+		{"GET /memes/search", "/memes/search?query=cat", "", false, http.StatusOK},
+		{"GET /tags/search", "/tags/search?query=cat", "", false, http.StatusOK},
+		{"GET /tags/search", "/tags/search?query=c", "", false, http.StatusBadRequest},
+		{"GET /autocomplete", "/autocomplete?query=c", "", false, http.StatusOK},
+		{"GET /autocomplete", "/autocomplete?query=", "", false, http.StatusBadRequest},
+		{"GET /meme/{id}", "/meme/" + contractMemeID, "", false, http.StatusOK},
+		{"GET /meme/{id}", "/meme/" + missingMemeID, "", false, http.StatusNotFound},
+		{"GET /meme/{id}", "/meme/123", "", false, http.StatusBadRequest},
+		{"POST /meme/upload", "/meme/upload", `{"media_type": "image/png", "size": 10}`, false, http.StatusCreated},
+		{"POST /meme/upload/{id}/finalize", "/meme/upload/" + contractMemeID + "/finalize", `{"name": "cat", "tags": ["cat"]}`, false, http.StatusOK},
+		{"GET /banner", "/banner", "", false, http.StatusOK},
+		{"PUT /admin/meme/{id}/source", "/admin/meme/" + contractMemeID + "/source", `{"platform": "Reddit", "url": "https://i.redd.it/a.png"}`, true, http.StatusOK},
+		{"GET /admin/memes/pending", "/admin/memes/pending", "", true, http.StatusOK},
+		{"GET /admin/memes/pending", "/admin/memes/pending", "", false, http.StatusUnauthorized},
+		{"GET /admin/memes/trash", "/admin/memes/trash", "", true, http.StatusOK},
+		{"DELETE /admin/cache", "/admin/cache", "", true, http.StatusOK},
+		{"POST /login", "/login", "", false, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.operation+" "+strconv.Itoa(tt.status), func(t *testing.T) {
+			method, _, _ := strings.Cut(tt.operation, " ")
+			r := httptest.NewRequest(method, apiPrefix+tt.path, strings.NewReader(tt.body))
+			switch {
+			case tt.admin:
+				r.Header.Set("Authorization", "Bearer "+token)
+			case tt.operation == "POST /login":
+				r.SetBasicAuth("admin", "password")
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+			if w.Code != tt.status {
+				t.Fatalf("Expected %d, got %d: %s", tt.status, w.Code, w.Body)
+			}
+
+			op, ok := ops[tt.operation]
+			if !ok {
+				t.Fatalf("%s isn't documented", tt.operation)
+			}
+			response, ok := op["responses"].(map[string]any)[strconv.Itoa(w.Code)].(map[string]any)
+			if !ok {
+				t.Fatalf("%s doesn't document the status %d", tt.operation, w.Code)
+			}
+			response = doc.resolve(t, response)
+			contents, _ := response["content"].(map[string]any)
+			content, ok := contents["application/json"].(map[string]any)
+			if !ok {
+				if w.Body.Len() != 0 {
+					t.Errorf("The %d response has no documented body, got %s", w.Code, w.Body)
+				}
+				return
+			}
+			var body any
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal("The response isn't JSON", err)
+			}
+			doc.validate(t, content["schema"].(map[string]any), body, "body")
+		})
+	}
+}
+
+func TestDeprecatedAPIAlias(t *testing.T) {
+	router := newContractRouter(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/meme/"+contractMemeID, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("/api should still serve v1, got %d", w.Code)
+	}
+	if w.Header().Get("Deprecation") != "true" {
+		t.Error("/api responses should have a Deprecation header")
+	}
+	if link := w.Header().Get("Link"); link != `</api/v1/meme/`+contractMemeID+`>; rel="successor-version"` {
+		t.Error("/api responses should link to /api/v1, got", link)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, apiPrefix+"/meme/"+contractMemeID, nil))
+	if w.Header().Get("Deprecation") != "" {
+		t.Error("/api/v1 isn't deprecated")
+	}
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	pb "github.com/BassemHalim/memesHub/internal/proto/memeService"
+	"github.com/BassemHalim/memesHub/pkg/api"
+)
+
+// The handlers convert the meme service responses to the api types before encoding them, the
+// conversions never return nil slices so empty lists are encoded as [] instead of null.
+
+func toMeme(m *pb.MemeResponse) api.Meme {
+	meme := api.Meme{
+		ID:            m.GetId(),
+		Name:          m.GetName(),
+		MediaURL:      m.GetMediaUrl(),
+		MediaType:     m.GetMediaType(),
+		Tags:          nonNil(m.GetTags()),
+		Dimensions:    nonNil(m.GetDimensions()),
+		DownloadCount: m.GetDownloadCount(),
+		ShareCount:    m.GetShareCount(),
+		DeletedAt:     m.GetDeletedAt(),
+	}
+	if m.GetSource() != nil {
+		source := toSource(m.GetSource())
+		meme.Source = &source
+	}
+	return meme
+}
+
+func toSource(s *pb.MemeSource) api.Source {
+	return api.Source{
+		Platform: s.GetPlatform(),
+		URL:      s.GetUrl(),
+		PostURL:  s.GetPostUrl(),
+		Author:   s.GetAuthor(),
+	}
+}
+
+func toMemePage(resp *pb.MemesResponse) api.MemePage {
+	memes := make([]api.Meme, 0, len(resp.GetMemes()))
+	for _, m := range resp.GetMemes() {
+		memes = append(memes, toMeme(m))
+	}
+	return api.MemePage{
+		Memes:       memes,
+		TotalCount:  resp.GetTotalCount(),
+		Page:        resp.GetPage(),
+		TotalPages:  resp.GetTotalPages(),
+		Suggestions: resp.GetSuggestions(),
+		RelatedTags: resp.GetRelatedTags(),
+	}
+}
+
+func toTagList(resp *pb.TagsResponse) api.TagList {
+	return api.TagList{Tags: nonNil(resp.GetTags())}
+}
+
+func toCompletionList(resp *pb.AutocompleteResponse) api.CompletionList {
+	completions := make([]api.Completion, 0, len(resp.GetCompletions()))
+	for _, c := range resp.GetCompletions() {
+		kind := api.CompletionTag
+		if c.GetKind() == pb.CompletionKind_COMPLETION_MEME {
+			kind = api.CompletionMeme
+		}
+		completions = append(completions, api.Completion{Text: c.GetText(), Kind: kind})
+	}
+	return api.CompletionList{Completions: completions}
+}
+
+func toUploadSlot(resp *pb.UploadSlotResponse) api.UploadSlot {
+	headers := resp.GetHeaders()
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	return api.UploadSlot{
+		ID:        resp.GetId(),
+		UploadURL: resp.GetUploadUrl(),
+		Method:    resp.GetMethod(),
+		Headers:   headers,
+		ExpiresAt: resp.GetExpiresAt(),
+	}
+}
+
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
@@ -0,0 +1,222 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	"github.com/BassemHalim/memesHub/internal/config"
+	"github.com/BassemHalim/memesHub/internal/db"
+	"github.com/BassemHalim/memesHub/internal/interceptors"
+	pb "github.com/BassemHalim/memesHub/internal/proto/memeService"
+	"github.com/BassemHalim/memesHub/internal/search"
+	"github.com/BassemHalim/memesHub/internal/storage"
+)
+
+// The meme service runs in the gateway process by default. With grpc.meme_service_addr set the
+// gateway calls a standalone meme service (cmd/memeService) over gRPC instead, both use the
+// MemeServiceClient interface.
+
+// NewMemeServiceFromConfig connects to the database and storage of cfg
+func NewMemeServiceFromConfig(cfg *config.Config, log *slog.Logger) (*MemeService, error) {
+	db, err := db.New(cfg.Database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	storage := storage.Instrument(storage.NewR2(storage.R2Options{
+		Bucket:          cfg.Storage.R2Bucket,
+		AccountID:       cfg.Storage.R2AccountID,
+		AccessKeyID:     cfg.Storage.R2AccessKeyID,
+		AccessKeySecret: cfg.Storage.R2AccessKeySecret,
+		BaseURL:         cfg.Storage.BaseURL,
+	}, log))
+	memeService := NewMemeService(db, log, storage)
+	memeService.ranking = search.Ranking{
+		Text:            cfg.Ranking.TextWeight,
+		Downloads:       cfg.Ranking.DownloadWeight,
+		Shares:          cfg.Ranking.ShareWeight,
+		Recency:         cfg.Ranking.RecencyWeight,
+		RecencyHalfLife: time.Duration(cfg.Ranking.RecencyHalfLifeDays * float64(24*time.Hour)),
+	}
+	return memeService, nil
+}
+
+// Ping checks the database and storage the meme service can't work without
+func (s *MemeService) Ping(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("database: %w", err)
+	}
+	if err := s.storage.Ping(ctx); err != nil {
+		return fmt.Errorf("storage: %w", err)
+	}
+	return nil
+}
+
+// NewGRPCServer serves memeService with the standard health service and server reflection.
+// It uses TLS when cfg has a certificate, opts add the interceptors.
+func NewGRPCServer(memeService *MemeService, cfg config.GRPCConfig, opts ...grpc.ServerOption) (*grpc.Server, *health.Server, error) {
+	opts = append(opts, grpc.StatsHandler(otelgrpc.NewServerHandler()))
+	if cfg.TLSCertFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load the TLS certificate: %w", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+	srv := grpc.NewServer(opts...)
+	pb.RegisterMemeServiceServer(srv, memeService)
+
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(srv, healthServer)
+	reflection.Register(srv)
+	return srv, healthServer, nil
+}
+
+// ReportHealth pings the dependencies of the meme service once every interval and publishes the
+// result on healthServer until ctx is cancelled
+func (s *MemeService) ReportHealth(ctx context.Context, healthServer *health.Server, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		pingCtx, cancel := context.WithTimeout(ctx, readinessTimeout)
+		err := s.Ping(pingCtx)
+		cancel()
+		status := healthpb.HealthCheckResponse_SERVING
+		if err != nil {
+			s.log.WarnContext(ctx, "Meme service is not healthy", "ERROR", err)
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		healthServer.SetServingStatus("", status)
+		healthServer.SetServingStatus(pb.MemeService_ServiceDesc.ServiceName, status)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DialMemeService connects to a standalone meme service, over TLS when cfg has a CA. Every call
+// carries the service token and the request ID.
+func DialMemeService(cfg config.GRPCConfig) (*grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+	if cfg.TLSCAFile != "" {
+		var err error
+		creds, err = credentials.NewClientTLSFromFile(cfg.TLSCAFile, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the TLS CA: %w", err)
+		}
+	}
+	return grpc.NewClient(cfg.MemeServiceAddr,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithUnaryInterceptor(interceptors.PropagateRequestID),
+		interceptors.WithServiceToken(cfg.ServiceToken),
+	)
+}
+
+// checkMemeServiceHealth is the readiness check of a remote meme service
+func checkMemeServiceHealth(health healthpb.HealthClient) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		resp, err := health.Check(ctx, &healthpb.HealthCheckRequest{Service: pb.MemeService_ServiceDesc.ServiceName})
+		if err != nil {
+			return err
+		}
+		if resp.Status != healthpb.HealthCheckResponse_SERVING {
+			return errors.New(resp.Status.String())
+		}
+		return nil
+	}
+}
+
+// grpcMemeService is a MemeServiceClient calling a remote meme service
+type grpcMemeService struct {
+	client pb.MemeServiceClient
+}
+
+func (c *grpcMemeService) UploadMeme(ctx context.Context, in *pb.UploadMemeRequest) (*pb.MemeResponse, error) {
+	return c.client.UploadMeme(ctx, in)
+}
+
+func (c *grpcMemeService) GetMeme(ctx context.Context, in *pb.GetMemeRequest) (*pb.MemeResponse, error) {
+	return c.client.GetMeme(ctx, in)
+}
+
+func (c *grpcMemeService) DeleteMeme(ctx context.Context, in *pb.DeleteMemeRequest) (*pb.DeleteMemeResponse, error) {
+	return c.client.DeleteMeme(ctx, in)
+}
+
+func (c *grpcMemeService) GetTimelineMemes(ctx context.Context, in *pb.GetTimelineRequest) (*pb.MemesResponse, error) {
+	return c.client.GetTimelineMemes(ctx, in)
+}
+
+func (c *grpcMemeService) SearchMemes(ctx context.Context, in *pb.SearchMemesRequest) (*pb.MemesResponse, error) {
+	return c.client.SearchMemes(ctx, in)
+}
+
+func (c *grpcMemeService) SearchTags(ctx context.Context, in *pb.SearchTagsRequest) (*pb.TagsResponse, error) {
+	return c.client.SearchTags(ctx, in)
+}
+
+func (c *grpcMemeService) Autocomplete(ctx context.Context, in *pb.AutocompleteRequest) (*pb.AutocompleteResponse, error) {
+	return c.client.Autocomplete(ctx, in)
+}
+
+func (c *grpcMemeService) AddTags(ctx context.Context, in *pb.AddTagsRequest) (*pb.AddTagsResponse, error) {
+	return c.client.AddTags(ctx, in)
+}
+
+func (c *grpcMemeService) UpdateMeme(ctx context.Context, in *pb.UpdateMemeRequest) (*pb.UpdateMemeResponse, error) {
+	return c.client.UpdateMeme(ctx, in)
+}
+
+func (c *grpcMemeService) GetPendingMemes(ctx context.Context, in *pb.GetPendingMemesRequest) (*pb.MemesResponse, error) {
+	return c.client.GetPendingMemes(ctx, in)
+}
+
+func (c *grpcMemeService) ApproveMeme(ctx context.Context, in *pb.ApproveMemeRequest) (*pb.ApproveMemeResponse, error) {
+	return c.client.ApproveMeme(ctx, in)
+}
+
+func (c *grpcMemeService) IncrementDownload(ctx context.Context, in *pb.IncrementEngagementRequest) (*pb.IncrementEngagementResponse, error) {
+	return c.client.IncrementDownload(ctx, in)
+}
+
+func (c *grpcMemeService) IncrementShare(ctx context.Context, in *pb.IncrementEngagementRequest) (*pb.IncrementEngagementResponse, error) {
+	return c.client.IncrementShare(ctx, in)
+}
+
+func (c *grpcMemeService) GetDeletedMemes(ctx context.Context, in *pb.GetDeletedMemesRequest) (*pb.MemesResponse, error) {
+	return c.client.GetDeletedMemes(ctx, in)
+}
+
+func (c *grpcMemeService) RestoreMeme(ctx context.Context, in *pb.RestoreMemeRequest) (*pb.RestoreMemeResponse, error) {
+	return c.client.RestoreMeme(ctx, in)
+}
+
+func (c *grpcMemeService) PurgeDeletedMemes(ctx context.Context, in *pb.PurgeDeletedMemesRequest) (*pb.PurgeDeletedMemesResponse, error) {
+	return c.client.PurgeDeletedMemes(ctx, in)
+}
+
+func (c *grpcMemeService) CreateUploadSlot(ctx context.Context, in *pb.CreateUploadSlotRequest) (*pb.UploadSlotResponse, error) {
+	return c.client.CreateUploadSlot(ctx, in)
+}
+
+func (c *grpcMemeService) FinalizeUpload(ctx context.Context, in *pb.FinalizeUploadRequest) (*pb.MemeResponse, error) {
+	return c.client.FinalizeUpload(ctx, in)
+}
+
+func (c *grpcMemeService) UpdateMemeSource(ctx context.Context, in *pb.UpdateMemeSourceRequest) (*pb.UpdateMemeSourceResponse, error) {
+	return c.client.UpdateMemeSource(ctx, in)
+}
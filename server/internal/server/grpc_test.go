@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/BassemHalim/memesHub/internal/config"
+	pb "github.com/BassemHalim/memesHub/internal/proto/memeService"
+)
+
+// newBufconnMemeService serves a meme service without a database over an in-memory listener
+func newBufconnMemeService(t *testing.T) (*grpc.ClientConn, *grpc.Server, func(healthpb.HealthCheckResponse_ServingStatus)) {
+	t.Helper()
+	memeService := NewMemeService(nil, GetDebugLogger(), &failingStorage{})
+	srv, healthServer, err := NewGRPCServer(memeService, config.GRPCConfig{ListenAddr: "bufconn"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lis := bufconn.Listen(1 << 20)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	setHealth := func(s healthpb.HealthCheckResponse_ServingStatus) {
+		healthServer.SetServingStatus(pb.MemeService_ServiceDesc.ServiceName, s)
+	}
+	return conn, srv, setHealth
+}
+
+func TestGRPCMemeServiceKeepsStatusCodes(t *testing.T) {
+	conn, _, _ := newBufconnMemeService(t)
+	client := &grpcMemeService{client: pb.NewMemeServiceClient(conn)}
+
+	_, err := client.IncrementDownload(context.Background(), &pb.IncrementEngagementRequest{MemeId: "not-a-uuid"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument over gRPC, got %v", err)
+	}
+	if msg := status.Convert(err).Message(); msg != "Invalid meme ID format" {
+		t.Errorf("The message should be kept, got %q", msg)
+	}
+}
+
+func TestGRPCServerRegistersHealthAndReflection(t *testing.T) {
+	conn, srv, setHealth := newBufconnMemeService(t)
+
+	services := srv.GetServiceInfo()
+	for _, name := range []string{pb.MemeService_ServiceDesc.ServiceName, healthpb.Health_ServiceDesc.ServiceName, "grpc.reflection.v1.ServerReflection"} {
+		if _, ok := services[name]; !ok {
+			t.Errorf("%s should be registered", name)
+		}
+	}
+
+	check := checkMemeServiceHealth(healthpb.NewHealthClient(conn))
+	if err := check(context.Background()); err == nil {
+		t.Error("The meme service isn't ready before its first health report")
+	}
+	setHealth(healthpb.HealthCheckResponse_SERVING)
+	if err := check(context.Background()); err != nil {
+		t.Error("The meme service should be ready", err)
+	}
+	setHealth(healthpb.HealthCheckResponse_NOT_SERVING)
+	if err := check(context.Background()); err == nil {
+		t.Error("A NOT_SERVING meme service isn't ready")
+	}
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/BassemHalim/memesHub/internal/version"
+)
+
+// the time every readiness check has to answer
+const readinessTimeout = 2 * time.Second
+
+// readinessCheck is a dependency the gateway can't serve requests without
+type readinessCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+type readinessResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// Drain makes /readyz fail so load balancers stop sending requests before the server shuts down
+func (s *Server) Drain() {
+	s.draining.Store(true)
+}
+
+// GET /healthz
+// The process is up, it doesn't check any dependency so a database outage doesn't restart it
+func (s *Server) Healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(readinessResponse{Status: "ok"})
+}
+
+// GET /readyz
+// Runs every readiness check concurrently and fails if any of them fails or the server is draining,
+// each check is reported as ok or fail
+func (s *Server) Readyz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if s.draining.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(readinessResponse{Status: "shutting down"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+	resp := readinessResponse{Status: "ok", Checks: make(map[string]string, len(s.readiness))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range s.readiness {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := c.check(ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				s.log.WarnContext(r.Context(), "Readiness check failed", "Check", c.name, "ERROR", err)
+				resp.Status = "unavailable"
+				// the endpoint is public, the error is only logged
+				resp.Checks[c.name] = "fail"
+				return
+			}
+			resp.Checks[c.name] = "ok"
+		}()
+	}
+	wg.Wait()
+
+	if resp.Status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// GET /version
+func (s *Server) Version(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(version.Get())
+}
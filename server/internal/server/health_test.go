@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealthz(t *testing.T) {
+	server, _ := NewWithMemeService(&MockMemeService{}, nil, nil, GetDebugLogger(), nil, MemCache)
+	// liveness doesn't depend on the readiness checks
+	server.readiness = []readinessCheck{{name: "database", check: func(ctx context.Context) error { return errors.New("down") }}}
+	w := httptest.NewRecorder()
+	server.Healthz(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected 200, got %d", w.Code)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	ok := func(ctx context.Context) error { return nil }
+	slow := func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Minute):
+			return nil
+		}
+	}
+	tests := []struct {
+		name           string
+		checks         []readinessCheck
+		draining       bool
+		expectedStatus int
+		failedCheck    string
+	}{
+		{name: "ready", checks: []readinessCheck{{"database", ok}, {"storage", ok}}, expectedStatus: http.StatusOK},
+		{name: "database down", checks: []readinessCheck{{"database", func(ctx context.Context) error { return errors.New("connection refused") }}, {"storage", ok}}, expectedStatus: http.StatusServiceUnavailable, failedCheck: "database"},
+		{name: "storage times out", checks: []readinessCheck{{"database", ok}, {"storage", slow}}, expectedStatus: http.StatusServiceUnavailable, failedCheck: "storage"},
+		{name: "draining", checks: []readinessCheck{{"database", ok}}, draining: true, expectedStatus: http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := NewWithMemeService(&MockMemeService{}, nil, nil, GetDebugLogger(), nil, MemCache)
+			server.readiness = tt.checks
+			if tt.draining {
+				server.Drain()
+			}
+			w := httptest.NewRecorder()
+			server.Readyz(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			var resp readinessResponse
+			json.NewDecoder(w.Body).Decode(&resp)
+			if tt.failedCheck != "" && resp.Checks[tt.failedCheck] != "fail" {
+				t.Errorf("The %s check should be reported as failed: %v", tt.failedCheck, resp.Checks)
+			}
+			for name, result := range resp.Checks {
+				if result != "ok" && result != "fail" {
+					t.Errorf("The %s check should only report ok or fail, got %q", name, result)
+				}
+			}
+		})
+	}
+}
@@ -1,18 +1,25 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
-	"net/http"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/BassemHalim/memesHub/internal/arabic"
+	"github.com/BassemHalim/memesHub/internal/metrics"
+	"github.com/BassemHalim/memesHub/internal/search"
+	"github.com/BassemHalim/memesHub/internal/tracing"
 	"github.com/BassemHalim/memesHub/internal/utils"
 	"github.com/BassemHalim/memesHub/internal/storage"
 	pb "github.com/BassemHalim/memesHub/internal/proto/memeService"
@@ -24,6 +31,8 @@ type MemeService struct {
 	db      *sql.DB
 	log     *slog.Logger
 	storage storage.Storage
+	// orders the search results by relevance, the zero value orders them by text rank
+	ranking search.Ranking
 }
 
 func NewMemeService(db *sql.DB, logger *slog.Logger, storage storage.Storage) *MemeService {
@@ -33,25 +42,38 @@ func NewMemeService(db *sql.DB, logger *slog.Logger, storage storage.Storage) *M
 		storage: storage}
 }
 
-func (s *MemeService) handleError(msg string, err error, code codes.Code) error {
-	s.log.Error(msg, "Error", err)
+func (s *MemeService) handleError(ctx context.Context, msg string, err error, code codes.Code) error {
+	s.log.ErrorContext(ctx, msg, "Error", err)
 	return status.Error(code, msg)
 }
+
+// observe starts the span of a MemeService method, the SQL queries of the method are its children.
+// The returned function ends the span and records the method duration.
+func (s *MemeService) observe(ctx context.Context, method string) (context.Context, func()) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "MemeService."+method)
+	return ctx, func() {
+		span.End()
+		metrics.ObserveQuery(method, start)
+	}
+}
 func (s *MemeService) UploadMeme(ctx context.Context, req *pb.UploadMemeRequest) (*pb.MemeResponse, error) {
+	ctx, end := s.observe(ctx, "UploadMeme")
+	defer end()
 
-	s.log.Debug("Uploading Meme")
+	s.log.DebugContext(ctx, "Uploading Meme")
 	if len(req.Dimensions) != 2 {
-		return nil, s.handleError("Invalid image dimensions", nil, codes.InvalidArgument)
+		return nil, s.handleError(ctx, "Invalid image dimensions", nil, codes.InvalidArgument)
 	}
 	ext, err := utils.MimeToExtension(req.MediaType)
 	if err != nil {
-		return nil, s.handleError("Invalid mime type", err, codes.InvalidArgument)
+		return nil, s.handleError(ctx, "Invalid mime type", err, codes.InvalidArgument)
 	}
 	filename := utils.RandomUUID() + ext
 
-	tx, err := s.db.Begin()
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, s.handleError("Error starting transaction", err, codes.Internal)
+		return nil, s.handleError(ctx, "Error starting transaction", err, codes.Internal)
 	}
 	defer tx.Rollback()
 	mediaURL := s.storage.ImageUrl(filename)
@@ -64,7 +86,7 @@ func (s *MemeService) UploadMeme(ctx context.Context, req *pb.UploadMemeRequest)
 		RETURNING id::text
 	`, mediaURL, req.MediaType, req.Name, pq.Array(req.Dimensions)).Scan(&memeID)
 	if err != nil {
-		return nil, s.handleError("Error inserting meme", err, codes.Internal)
+		return nil, s.handleError(ctx, "Error inserting meme", err, codes.Internal)
 	}
 
 	err = saveTags(ctx, memeID, req.Tags, tx)
@@ -74,19 +96,20 @@ func (s *MemeService) UploadMeme(ctx context.Context, req *pb.UploadMemeRequest)
 
 	// save the image source
 	if req.SocialMediaUrl != "" {
-		s.log.Debug("Saving image source", "Source", req.SocialMediaUrl, "ID", memeID)
-		if err := s.storeImageSource(ctx, tx, memeID, req.SocialMediaUrl); err != nil {
-			return nil, s.handleError("Error saving the image source", err, codes.Internal)
+		s.log.DebugContext(ctx, "Saving image source", "Source", req.SocialMediaUrl, "ID", memeID)
+		if err := s.storeImageSource(ctx, tx, memeID, req.SocialMediaUrl, req.PostUrl); err != nil {
+			return nil, s.handleError(ctx, "Error saving the image source", err, codes.Internal)
 		}
 	}
 	// save image
-	_, err = s.storage.SaveImage(filename, req.Image)
+	_, err = s.storage.SaveImage(ctx, filename, bytes.NewReader(req.Image))
 	if err != nil {
-		return nil, s.handleError("Error saving the image", err, codes.Internal)
+		return nil, s.handleError(ctx, "Error saving the image", err, codes.Internal)
 	}
 
 	if err = tx.Commit(); err != nil {
-		return nil, s.handleError("Error committing the transaction", err, codes.Internal)
+		s.discardImage(ctx, filename)
+		return nil, s.handleError(ctx, "Error committing the transaction", err, codes.Internal)
 	}
 
 	// return the meme
@@ -99,38 +122,9 @@ func (s *MemeService) UploadMeme(ctx context.Context, req *pb.UploadMemeRequest)
 	}, nil
 }
 
-func (s *MemeService) storeImageSource(ctx context.Context, txn *sql.Tx, memeID string, source string) error {
-	var socialMedia = "Other"
-	if strings.Contains(source, "twimg.com") {
-		socialMedia = "X"
-	} else if strings.Contains(source, "redd.it") {
-		socialMedia = "Reddit"
-	} else if strings.Contains(source, "cdninstagram.com") {
-		socialMedia = "Instagram"
-	} else if strings.Contains(source, "fbcdn.net") {
-		socialMedia = "FB"
-	} else if strings.Contains(source, "pinterest.com") || strings.Contains(source, "pinimg.com") {
-		socialMedia = "Pinterest"
-	} else if strings.Contains(source, "linkedin.com") {
-		socialMedia = "LinkedIn"
-	} else if strings.Contains(source, "imgur.com") {
-		socialMedia = "Imgur"
-	}
-	query := `INSERT INTO images (url, social_media_platform, meme_id)
-			VALUES ($1, $2, $3);`
-	var err error
-	if txn == nil {
-		_, err = s.db.ExecContext(ctx, query, source, socialMedia, memeID)
-	} else {
-		_, err = txn.ExecContext(ctx, query, source, socialMedia, memeID)
-	}
-	if err != nil {
-		s.handleError("Failed to insert image source URL", err, codes.Internal)
-	}
-	return nil
-}
-
 func (s *MemeService) GetMeme(ctx context.Context, req *pb.GetMemeRequest) (*pb.MemeResponse, error) {
+	ctx, end := s.observe(ctx, "GetMeme")
+	defer end()
 	var resp pb.MemeResponse
 	resp.Id = req.Id
 	// get meme details
@@ -138,12 +132,18 @@ func (s *MemeService) GetMeme(ctx context.Context, req *pb.GetMemeRequest) (*pb.
 	err := s.db.QueryRowContext(ctx, `
 		SELECT media_url, media_type, name, dimensions, download_count, share_count
 		FROM meme
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 		`, req.Id).Scan(&resp.MediaUrl, &resp.MediaType, &resp.Name, &dimensions, &resp.DownloadCount, &resp.ShareCount)
+	if err == sql.ErrNoRows {
+		return nil, status.Error(codes.NotFound, "Meme not found")
+	}
 	if err != nil {
-		return nil, s.handleError("error getting meme", err, codes.Internal)
+		return nil, s.handleError(ctx, "error getting meme", err, codes.Internal)
 	}
 	resp.Dimensions = dimensions
+	if resp.Source, err = s.getImageSource(ctx, req.Id); err != nil {
+		return nil, s.handleError(ctx, "error getting the meme source", err, codes.Internal)
+	}
 	// get tags
 	rows, err := s.db.QueryContext(ctx, `
 	SELECT t.name
@@ -152,14 +152,14 @@ func (s *MemeService) GetMeme(ctx context.Context, req *pb.GetMemeRequest) (*pb.
 	WHERE mt.meme_id = $1
 	`, req.Id)
 	if err != nil {
-		return nil, s.handleError("error getting tags", err, codes.Internal)
+		return nil, s.handleError(ctx, "error getting tags", err, codes.Internal)
 	}
 	defer rows.Close()
 	var tags []string
 	for rows.Next() {
 		var tag string
 		if err = rows.Scan(&tag); err != nil {
-			return nil, s.handleError("error scanning tag", err, codes.Internal)
+			return nil, s.handleError(ctx, "error scanning tag", err, codes.Internal)
 		}
 		tags = append(tags, tag)
 	}
@@ -168,6 +168,8 @@ func (s *MemeService) GetMeme(ctx context.Context, req *pb.GetMemeRequest) (*pb.
 }
 
 func (s *MemeService) GetTimelineMemes(ctx context.Context, req *pb.GetTimelineRequest) (*pb.MemesResponse, error) {
+	ctx, end := s.observe(ctx, "GetTimelineMemes")
+	defer end()
 	// Validate pagination parameters
 	if req.Page < 1 {
 		req.Page = 1
@@ -178,12 +180,12 @@ func (s *MemeService) GetTimelineMemes(ctx context.Context, req *pb.GetTimelineR
 
 	offset := (req.Page - 1) * req.PageSize
 
-	// Base query without any tag filtering
+	filters, args := timelineFilters(req)
 	baseQuery := `
         SELECT m.id, m.media_url, m.media_type, m.name, m.dimensions, m.download_count, m.share_count
         FROM meme m
-        WHERE m.approval_status = 'approved'
-    `
+        WHERE m.approval_status = 'approved' AND m.deleted_at IS NULL
+    ` + filters
 
 	// Add timeline-specific sorting
 	switch req.SortOrder {
@@ -198,20 +200,20 @@ func (s *MemeService) GetTimelineMemes(ctx context.Context, req *pb.GetTimelineR
 	}
 
 	// Add pagination
-	baseQuery += " LIMIT $1 OFFSET $2"
+	baseQuery += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
 
 	// Get total count
 	var totalCount int32
-	countQuery := "SELECT COUNT(*) FROM meme WHERE approval_status = 'approved'"
-	err := s.db.QueryRow(countQuery).Scan(&totalCount)
+	countQuery := "SELECT COUNT(*) FROM meme m WHERE m.approval_status = 'approved' AND m.deleted_at IS NULL" + filters
+	err := s.db.QueryRowContext(ctx, countQuery, args...).Scan(&totalCount)
 	if err != nil {
-		return nil, s.handleError("error counting memes", err, codes.Internal)
+		return nil, s.handleError(ctx, "error counting memes", err, codes.Internal)
 	}
 
 	// Execute main query
-	rows, err := s.db.QueryContext(ctx, baseQuery, req.PageSize, offset)
+	rows, err := s.db.QueryContext(ctx, baseQuery, append(args, req.PageSize, offset)...)
 	if err != nil {
-		return nil, s.handleError("error querying memes", err, codes.Internal)
+		return nil, s.handleError(ctx, "error querying memes", err, codes.Internal)
 	}
 	defer rows.Close()
 
@@ -229,7 +231,7 @@ func (s *MemeService) GetTimelineMemes(ctx context.Context, req *pb.GetTimelineR
 			&meme.DownloadCount,
 			&meme.ShareCount,
 		); err != nil {
-			return nil, s.handleError("error scanning meme", err, codes.Internal)
+			return nil, s.handleError(ctx, "error scanning meme", err, codes.Internal)
 		}
 		meme.Dimensions = dimensions
 
@@ -241,7 +243,7 @@ func (s *MemeService) GetTimelineMemes(ctx context.Context, req *pb.GetTimelineR
             WHERE mt.meme_id = $1
         `, meme.Id)
 		if err != nil {
-			return nil, s.handleError("error querying tags", err, codes.Internal)
+			return nil, s.handleError(ctx, "error querying tags", err, codes.Internal)
 		}
 
 		tags := []string{}
@@ -249,7 +251,7 @@ func (s *MemeService) GetTimelineMemes(ctx context.Context, req *pb.GetTimelineR
 			var tag string
 			if err := tagRows.Scan(&tag); err != nil {
 				tagRows.Close()
-				return nil, s.handleError("error scanning tag", err, codes.Internal)
+				return nil, s.handleError(ctx, "error scanning tag", err, codes.Internal)
 			}
 			tags = append(tags, tag)
 		}
@@ -263,7 +265,8 @@ func (s *MemeService) GetTimelineMemes(ctx context.Context, req *pb.GetTimelineR
 	if totalCount%req.PageSize != 0 {
 		totalPages++
 	}
-	if len(memes) == 0 {
+	// a filter nothing matches is an empty first page, only pages past the end are errors
+	if len(memes) == 0 && req.Page > 1 {
 		return nil, status.Error(codes.OutOfRange, "no memes found")
 	}
 	return &pb.MemesResponse{
@@ -274,10 +277,73 @@ func (s *MemeService) GetTimelineMemes(ctx context.Context, req *pb.GetTimelineR
 	}, nil
 }
 
+// timelineFilters returns the conditions filtering the timeline by tags and media type, to
+// append to a WHERE clause on meme m, and their arguments numbered from $1
+func timelineFilters(req *pb.GetTimelineRequest) (string, []any) {
+	var filters strings.Builder
+	var args []any
+	// the tags of m in the array of normalized names of argument %d
+	const matchingTags = "FROM meme_tag mt JOIN tag t ON t.id = mt.tag_id WHERE mt.meme_id = m.id AND t.normalized_name = ANY($%d)"
+
+	if tags := normalizeTags(req.Tags); len(tags) > 0 {
+		args = append(args, pq.Array(tags))
+		from := fmt.Sprintf(matchingTags, len(args))
+		if req.TagMatch == pb.TagMatch_ALL_TAGS {
+			args = append(args, len(tags))
+			fmt.Fprintf(&filters, " AND (SELECT COUNT(*) %s) = $%d", from, len(args))
+		} else {
+			fmt.Fprintf(&filters, " AND EXISTS (SELECT 1 %s)", from)
+		}
+	}
+	if tags := normalizeTags(req.ExcludeTags); len(tags) > 0 {
+		args = append(args, pq.Array(tags))
+		fmt.Fprintf(&filters, " AND NOT EXISTS (SELECT 1 %s)", fmt.Sprintf(matchingTags, len(args)))
+	}
+	if req.MediaType != "" {
+		args = append(args, req.MediaType)
+		fmt.Fprintf(&filters, " AND m.media_type = $%d", len(args))
+	}
+	return filters.String(), args
+}
+
+// normalizeTag returns the tag.normalized_name of tag, tags with the same normalized name are the
+// same tag whatever their case and Arabic spelling
+func normalizeTag(tag string) string {
+	return strings.ToLower(arabic.Normalize(strings.TrimSpace(tag)))
+}
+
+// normalizeTags returns the distinct non empty normalized names of tags
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag != "" && !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
 func (s *MemeService) SearchMemes(ctx context.Context, req *pb.SearchMemesRequest) (*pb.MemesResponse, error) {
-	query := req.Query
+	ctx, end := s.observe(ctx, "SearchMemes")
+	defer end()
 	var memes []*pb.MemeResponse
 
+	query, err := search.Parse(req.Query)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	opts := search.Options{Ranking: s.ranking}
+	switch req.Sort {
+	case pb.SearchSort_SEARCH_NEWEST:
+		opts.Sort = search.SortNewest
+	case pb.SearchSort_SEARCH_MOST_DOWNLOADED:
+		opts.Sort = search.SortMostDownloaded
+	default:
+		opts.Sort = search.SortRelevance
+	}
+	stmt := search.Compile(query, opts)
+
 	// Validate pagination parameters
 	if req.Page < 1 {
 		req.Page = 1
@@ -289,17 +355,18 @@ func (s *MemeService) SearchMemes(ctx context.Context, req *pb.SearchMemesReques
 	// Calculate offset
 	offset := (req.Page - 1) * req.PageSize
 
-	// Get total count of search results using fuzzy search
+	// Get total count of search results
 	var totalCount int32
-	err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM search_memes_fuzzy($1)", query).Scan(&totalCount)
+	err = s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM ("+stmt.SQL+") results", stmt.Args...).Scan(&totalCount)
 	if err != nil {
-		return nil, s.handleError("error counting memes", err, codes.Internal)
+		return nil, s.handleError(ctx, "error counting memes", err, codes.Internal)
 	}
 
-	// Fetch paginated search results using fuzzy search
-	rows, err := s.db.Query("SELECT id::text FROM search_memes_fuzzy($1) LIMIT $2 OFFSET $3", query, req.PageSize, offset)
+	// Fetch paginated search results
+	n := len(stmt.Args)
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf("%s LIMIT $%d OFFSET $%d", stmt.SQL, n+1, n+2), append(stmt.Args, req.PageSize, offset)...)
 	if err != nil {
-		return nil, s.handleError("search memes error", err, codes.Internal)
+		return nil, s.handleError(ctx, "search memes error", err, codes.Internal)
 	}
 	defer rows.Close()
 
@@ -307,58 +374,77 @@ func (s *MemeService) SearchMemes(ctx context.Context, req *pb.SearchMemesReques
 		var id string
 		err := rows.Scan(&id)
 		if err != nil {
-			return nil, s.handleError("error scanning meme ID", err, codes.Internal)
+			return nil, s.handleError(ctx, "error scanning meme ID", err, codes.Internal)
 		}
 
 		memeResponse, err := s.GetMeme(ctx, &pb.GetMemeRequest{Id: id})
 		if err != nil {
-			return nil, s.handleError("error getting meme", err, codes.Internal)
+			return nil, s.handleError(ctx, "error getting meme", err, codes.Internal)
 		}
 
 		memes = append(memes, memeResponse)
 	}
 
 	totalPages := (totalCount + int32(req.PageSize) - 1) / int32(req.PageSize)
-	return &pb.MemesResponse{
+	resp := &pb.MemesResponse{
 		Memes:      memes,
 		TotalCount: totalCount,
 		Page:       int32(req.Page),
 		TotalPages: totalPages,
-	}, nil
+	}
+	if terms := query.Terms(); totalCount < sparseResults && len(terms) > 0 {
+		// the results are still useful without suggestions
+		resp.Suggestions, resp.RelatedTags, err = s.suggest(ctx, terms)
+		if err != nil {
+			s.log.WarnContext(ctx, "Failed to suggest queries", "Error", err, "Query", req.Query)
+		}
+	}
+	return resp, nil
 }
 
-// deletes the meme and it's meme-tag relations (tags are not deleted) as well as the image
+// soft deletes the meme by setting deleted_at and moving the image to the trash once committed
+// the meme-tag relations are kept so the meme can be restored with RestoreMeme
 func (s *MemeService) DeleteMeme(ctx context.Context, req *pb.DeleteMemeRequest) (*pb.DeleteMemeResponse, error) {
-	txn, err := s.db.Begin()
+	ctx, end := s.observe(ctx, "DeleteMeme")
+	defer end()
+	txn, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return &pb.DeleteMemeResponse{Success: false}, s.handleError("error starting transaction", err, codes.Internal)
+		return &pb.DeleteMemeResponse{Success: false}, s.handleError(ctx, "error starting transaction", err, codes.Internal)
 	}
 	defer txn.Rollback()
-	resp, err := s.GetMeme(ctx, &pb.GetMemeRequest{Id: req.Id})
-	if err != nil {
-		return &pb.DeleteMemeResponse{Success: false}, s.handleError(fmt.Sprintf("error getting meme %s likely bad ID", req.Id), err, codes.InvalidArgument)
+	var mediaURL string
+	err = txn.QueryRowContext(ctx, `
+		UPDATE meme
+		SET deleted_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING media_url
+	`, req.Id).Scan(&mediaURL)
+	if err == sql.ErrNoRows {
+		return &pb.DeleteMemeResponse{Success: false}, status.Error(codes.NotFound, "Meme not found")
 	}
-	// delete the image
-	s.log.Debug("Deleting image", "Image", resp)
-	s.storage.SoftDeleteImage(filepath.Base(resp.MediaUrl))
-	_, err = txn.Exec("DELETE FROM meme_tag WHERE meme_id = $1", req.Id)
 	if err != nil {
-		return &pb.DeleteMemeResponse{Success: false}, s.handleError("error deleting meme_tag", err, codes.Internal)
+		return &pb.DeleteMemeResponse{Success: false}, s.handleError(ctx, fmt.Sprintf("error getting meme %s likely bad ID", req.Id), err, codes.InvalidArgument)
 	}
-
-	_, err = txn.ExecContext(ctx, "DELETE FROM meme WHERE id = $1", req.Id)
+	// move the image to the trash after the commit
+	s.log.DebugContext(ctx, "Deleting image", "Image", mediaURL)
+	opID, err := enqueueStorageOp(ctx, txn, opSoftDelete, filepath.Base(mediaURL), "")
 	if err != nil {
-		return &pb.DeleteMemeResponse{Success: false}, s.handleError("error deleting meme", err, codes.Internal)
+		return &pb.DeleteMemeResponse{Success: false}, s.handleError(ctx, "error scheduling image deletion", err, codes.Internal)
+	}
+	if err := txn.Commit(); err != nil {
+		return &pb.DeleteMemeResponse{Success: false}, s.handleError(ctx, "error committing the transaction", err, codes.Internal)
 	}
-	txn.Commit()
+	s.runStorageOps(ctx, opID)
 	return &pb.DeleteMemeResponse{Success: true}, nil
 }
 
 func (s *MemeService) SearchTags(ctx context.Context, req *pb.SearchTagsRequest) (*pb.TagsResponse, error) {
+	ctx, end := s.observe(ctx, "SearchTags")
+	defer end()
 	query := req.Query
 	limit := req.Limit
 	if len(query) < 3 {
-		return nil, s.handleError("Query must be at least 3 characters long", nil, codes.Internal)
+		return nil, s.handleError(ctx, "Query must be at least 3 characters long", nil, codes.InvalidArgument)
 	}
 	if limit < 1 {
 		limit = 5
@@ -368,7 +454,7 @@ func (s *MemeService) SearchTags(ctx context.Context, req *pb.SearchTagsRequest)
 	// Use fuzzy search function with default similarity threshold of 0.3
 	rows, err := s.db.QueryContext(ctx, "SELECT name, similarity FROM search_tags_fuzzy($1, 0.3, $2)", query, limit)
 	if err != nil {
-		return nil, s.handleError("error searching tags", err, codes.Internal)
+		return nil, s.handleError(ctx, "error searching tags", err, codes.Internal)
 	}
 	defer rows.Close()
 
@@ -376,7 +462,7 @@ func (s *MemeService) SearchTags(ctx context.Context, req *pb.SearchTagsRequest)
 		var tag string
 		var similarity float32 // Ignore similarity score in response for backward compatibility
 		if err := rows.Scan(&tag, &similarity); err != nil {
-			return nil, s.handleError("error scanning tag", err, codes.Internal)
+			return nil, s.handleError(ctx, "error scanning tag", err, codes.Internal)
 		}
 		tags = append(tags, tag)
 	}
@@ -385,15 +471,21 @@ func (s *MemeService) SearchTags(ctx context.Context, req *pb.SearchTagsRequest)
 }
 
 func saveTags(ctx context.Context, memeID string, tags []string, tx *sql.Tx) error {
+	ctx, span := tracing.Start(ctx, "saveTags", attribute.Int("meme.tags", len(tags)))
+	defer span.End()
 	// for each tag check if it already exists if not add it
 	for _, tag := range tags {
-		// add tag if it doesn't exist and get id
+		normalized := normalizeTag(tag)
+		if normalized == "" {
+			continue
+		}
+		// add tag if it doesn't exist and get id, an existing tag keeps the spelling it was created with
 		row, err := tx.QueryContext(ctx, `
-							INSERT INTO tag (name)
-							VALUES ($1)
-							ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+							INSERT INTO tag (name, normalized_name)
+							VALUES ($1, $2)
+							ON CONFLICT (normalized_name) DO UPDATE SET name = tag.name
 							RETURNING id;
-							`, strings.TrimSpace(tag))
+							`, strings.TrimSpace(tag), normalized)
 		if err != nil {
 			return err
 		}
@@ -419,35 +511,61 @@ func saveTags(ctx context.Context, memeID string, tags []string, tx *sql.Tx) err
 }
 
 func (s *MemeService) AddTags(ctx context.Context, req *pb.AddTagsRequest) (*pb.AddTagsResponse, error) {
-	s.log.Debug("Adding tags to meme", "ID", req.MemeId, "Tags", req.Tags)
+	ctx, end := s.observe(ctx, "AddTags")
+	defer end()
+	s.log.DebugContext(ctx, "Adding tags to meme", "ID", req.MemeId, "Tags", req.Tags)
 
-	tx, err := s.db.Begin()
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return &pb.AddTagsResponse{Success: http.StatusInternalServerError}, s.handleError("error starting transaction", err, codes.Internal)
+		return nil, s.handleError(ctx, "error starting transaction", err, codes.Internal)
 	}
 	defer tx.Rollback()
 	if err := saveTags(ctx, req.MemeId, req.Tags, tx); err != nil {
-		return &pb.AddTagsResponse{Success: http.StatusBadRequest}, s.handleError("error saving tags", err, codes.Internal)
+		// meme_tag references the meme so an unknown ID fails the foreign key
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return nil, status.Error(codes.NotFound, "Meme not found")
+		}
+		return nil, s.handleError(ctx, "error saving tags", err, codes.Internal)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, s.handleError(ctx, "error committing the transaction", err, codes.Internal)
 	}
-	tx.Commit()
 
-	return &pb.AddTagsResponse{Success: http.StatusOK}, nil
+	return &pb.AddTagsResponse{}, nil
 }
 
 func (s *MemeService) UpdateMeme(ctx context.Context, r *pb.UpdateMemeRequest) (*pb.UpdateMemeResponse, error) {
-	s.log.Debug("Update Meme", "ID", r.Id, "Name", r.Name, "Tags", r.Tags, "Dimensions", r.Dimensions)
-	txn, err := s.db.Begin()
+	ctx, end := s.observe(ctx, "UpdateMeme")
+	defer end()
+	s.log.DebugContext(ctx, "Update Meme", "ID", r.Id, "Name", r.Name, "Tags", r.Tags, "Dimensions", r.Dimensions)
+	txn, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return &pb.UpdateMemeResponse{Success: false}, s.handleError("error starting transaction", err, codes.Internal)
+		return &pb.UpdateMemeResponse{Success: false}, s.handleError(ctx, "error starting transaction", err, codes.Internal)
 	}
 	defer txn.Rollback()
 
+	// lock the meme row so concurrent updates can't archive the same image twice,
+	// memes in the trash can't be edited
+	var oldMediaURL string
+	err = txn.QueryRowContext(ctx, `
+		SELECT media_url FROM meme
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE
+	`, r.Id).Scan(&oldMediaURL)
+	if err == sql.ErrNoRows {
+		return &pb.UpdateMemeResponse{Success: false}, s.handleError(ctx, "meme not found", err, codes.NotFound)
+	}
+	if err != nil {
+		return &pb.UpdateMemeResponse{Success: false}, s.handleError(ctx, "error getting meme", err, codes.Internal)
+	}
+
 	if r.Name != "" {
 		_, err := txn.ExecContext(ctx, `UPDATE meme
 				SET name = $1
 				WHERE id = $2`, r.Name, r.Id)
 		if err != nil {
-			return &pb.UpdateMemeResponse{Success: false}, s.handleError("error updating meme name", err, codes.Internal)
+			return &pb.UpdateMemeResponse{Success: false}, s.handleError(ctx, "error updating meme name", err, codes.Internal)
 		}
 	}
 
@@ -456,75 +574,89 @@ func (s *MemeService) UpdateMeme(ctx context.Context, r *pb.UpdateMemeRequest) (
 		// Delete all existing meme_tag associations for this meme
 		_, err := txn.ExecContext(ctx, "DELETE FROM meme_tag WHERE meme_id = $1", r.Id)
 		if err != nil {
-			return &pb.UpdateMemeResponse{Success: false}, s.handleError("error removing existing tags", err, codes.Internal)
+			return &pb.UpdateMemeResponse{Success: false}, s.handleError(ctx, "error removing existing tags", err, codes.Internal)
 		}
 
 		// Add the new tags (if any)
 		if len(r.Tags) > 0 {
 			err = saveTags(ctx, r.Id, r.Tags, txn)
 			if err != nil {
-				return &pb.UpdateMemeResponse{Success: false}, s.handleError("error saving tags", err, codes.Internal)
+				return &pb.UpdateMemeResponse{Success: false}, s.handleError(ctx, "error saving tags", err, codes.Internal)
 			}
 		}
 	}
 
+	var opID int64
+	var newFilename string
 	if len(r.Image) > 0 {
 		// will always create a new filename to update cache)
-		// get meme
-		meme, err := s.GetMeme(ctx, &pb.GetMemeRequest{Id: r.Id})
-		if err != nil {
-			return &pb.UpdateMemeResponse{Success: false}, s.handleError("error getting meme bad ID", err, codes.InvalidArgument)
-		}
 		// get the image file name
-		oldFilename := filepath.Base(meme.MediaUrl)
+		oldFilename := filepath.Base(oldMediaURL)
 		newExtension, err := utils.MimeToExtension(r.MediaType)
-		newFilename := utils.RandomUUID() + newExtension
 		if err != nil {
-			return &pb.UpdateMemeResponse{Success: false}, s.handleError("error getting new extension, Bad MediaType", err, codes.InvalidArgument)
+			return &pb.UpdateMemeResponse{Success: false}, s.handleError(ctx, "error getting new extension, Bad MediaType", err, codes.InvalidArgument)
 		}
+		newFilename = utils.RandomUUID() + newExtension
 
-		// rename the image file to keep old versions TODO: should I just soft delete it?
-		_, err = s.storage.RenameImage(oldFilename, fmt.Sprintf("%s_%d", oldFilename, time.Now().Unix()))
+		// the old image is renamed to keep old versions, but only once the new media_url is committed
+		opID, err = enqueueStorageOp(ctx, txn, opRename, oldFilename, fmt.Sprintf("%s_%d", oldFilename, time.Now().Unix()))
 		if err != nil {
-			return &pb.UpdateMemeResponse{Success: false}, s.handleError("error renaming image", err, codes.Internal)
-		}
-		// save the image
-		url, err := s.storage.SaveImage(newFilename, r.Image)
-		if err != nil {
-			return &pb.UpdateMemeResponse{Success: false}, s.handleError("error saving the image", err, codes.Internal)
+			return &pb.UpdateMemeResponse{Success: false}, s.handleError(ctx, "error scheduling image rename", err, codes.Internal)
 		}
 
 		// update the DB
-		// mediaURL := fmt.Sprintf("/imgs/%s", newFilename)
 		if _, err = txn.ExecContext(ctx, `UPDATE meme
 		SET media_url = $1, 
 		media_type = $2,
 		dimensions = $3
-		WHERE id = $4`, url, r.MediaType, pq.Array(r.Dimensions), r.Id); err != nil {
-			return &pb.UpdateMemeResponse{Success: false}, s.handleError("error updating meme", err, codes.Internal)
+		WHERE id = $4`, s.storage.ImageUrl(newFilename), r.MediaType, pq.Array(r.Dimensions), r.Id); err != nil {
+			return &pb.UpdateMemeResponse{Success: false}, s.handleError(ctx, "error updating meme", err, codes.Internal)
 		}
 
 		if r.SocialMediaUrl != "" {
-			s.log.Debug("Saving image source", "Source", r.SocialMediaUrl, "ID", r.Id)
-			if err := s.storeImageSource(ctx, txn, r.Id, r.SocialMediaUrl); err != nil {
-				return &pb.UpdateMemeResponse{Success: false}, s.handleError("error saving the image source", err, codes.Internal)
+			s.log.DebugContext(ctx, "Saving image source", "Source", r.SocialMediaUrl, "ID", r.Id)
+			if err := s.storeImageSource(ctx, txn, r.Id, r.SocialMediaUrl, r.PostUrl); err != nil {
+				return &pb.UpdateMemeResponse{Success: false}, s.handleError(ctx, "error saving the image source", err, codes.Internal)
 			}
 		}
 
+		// save the new image under a fresh key so nothing that is currently referenced is touched
+		if _, err = s.storage.SaveImage(ctx, newFilename, bytes.NewReader(r.Image)); err != nil {
+			return &pb.UpdateMemeResponse{Success: false}, s.handleError(ctx, "error saving the image", err, codes.Internal)
+		}
+	}
+	if err := txn.Commit(); err != nil {
+		if newFilename != "" {
+			s.discardImage(ctx, newFilename)
+		}
+		return &pb.UpdateMemeResponse{Success: false}, s.handleError(ctx, "error committing the transaction", err, codes.Internal)
+	}
+	if opID != 0 {
+		s.runStorageOps(ctx, opID)
 	}
-	txn.Commit()
 	return &pb.UpdateMemeResponse{
 			Success: true,
 		},
 		nil
 }
 
+// discardImage compensates for an image saved by a transaction that failed to commit.
+// The image is unreferenced so failures are only logged. It runs even if ctx already expired
+// since an expired ctx is a common reason for the commit to fail.
+func (s *MemeService) discardImage(ctx context.Context, filename string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := s.storage.SoftDeleteImage(ctx, filename); err != nil {
+		s.log.ErrorContext(ctx, "Failed to discard image of a rolled back transaction", "Error", err, "Image", filename)
+	}
+}
+
 // IncrementDownloadCount atomically increments the download count for a meme
 func (s *MemeService) IncrementDownloadCount(ctx context.Context, memeID string) error {
 	result, err := s.db.ExecContext(ctx, `
 		UPDATE meme 
 		SET download_count = download_count + 1 
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`, memeID)
 	if err != nil {
 		return err
@@ -547,7 +679,7 @@ func (s *MemeService) IncrementShareCount(ctx context.Context, memeID string) er
 	result, err := s.db.ExecContext(ctx, `
 		UPDATE meme 
 		SET share_count = share_count + 1 
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`, memeID)
 	if err != nil {
 		return err
@@ -567,6 +699,8 @@ func (s *MemeService) IncrementShareCount(ctx context.Context, memeID string) er
 
 // IncrementDownload implements the IncrementDownload RPC method
 func (s *MemeService) IncrementDownload(ctx context.Context, req *pb.IncrementEngagementRequest) (*pb.IncrementEngagementResponse, error) {
+	ctx, end := s.observe(ctx, "IncrementDownload")
+	defer end()
 	// Validate meme_id format (UUID)
 	if err := utils.ValidateUUID(req.MemeId); err != nil {
 		return &pb.IncrementEngagementResponse{
@@ -584,7 +718,7 @@ func (s *MemeService) IncrementDownload(ctx context.Context, req *pb.IncrementEn
 				Error:   "Meme not found",
 			}, status.Error(codes.NotFound, "Meme not found")
 		}
-		s.log.Error("Error incrementing download count", "Error", err, "MemeID", req.MemeId)
+		s.log.ErrorContext(ctx, "Error incrementing download count", "Error", err, "MemeID", req.MemeId)
 		return &pb.IncrementEngagementResponse{
 			Success: false,
 			Error:   "Internal server error",
@@ -600,6 +734,8 @@ func (s *MemeService) IncrementDownload(ctx context.Context, req *pb.IncrementEn
 
 // IncrementShare implements the IncrementShare RPC method
 func (s *MemeService) IncrementShare(ctx context.Context, req *pb.IncrementEngagementRequest) (*pb.IncrementEngagementResponse, error) {
+	ctx, end := s.observe(ctx, "IncrementShare")
+	defer end()
 	// Validate meme_id format (UUID)
 	if err := utils.ValidateUUID(req.MemeId); err != nil {
 		return &pb.IncrementEngagementResponse{
@@ -617,7 +753,7 @@ func (s *MemeService) IncrementShare(ctx context.Context, req *pb.IncrementEngag
 				Error:   "Meme not found",
 			}, status.Error(codes.NotFound, "Meme not found")
 		}
-		s.log.Error("Error incrementing share count", "Error", err, "MemeID", req.MemeId)
+		s.log.ErrorContext(ctx, "Error incrementing share count", "Error", err, "MemeID", req.MemeId)
 		return &pb.IncrementEngagementResponse{
 			Success: false,
 			Error:   "Internal server error",
@@ -633,6 +769,8 @@ func (s *MemeService) IncrementShare(ctx context.Context, req *pb.IncrementEngag
 
 // GetPendingMemes retrieves all memes with approval_status = 'pending'
 func (s *MemeService) GetPendingMemes(ctx context.Context, req *pb.GetPendingMemesRequest) (*pb.MemesResponse, error) {
+	ctx, end := s.observe(ctx, "GetPendingMemes")
+	defer end()
 	// Validate pagination parameters
 	if req.Page < 1 {
 		req.Page = 1
@@ -647,22 +785,22 @@ func (s *MemeService) GetPendingMemes(ctx context.Context, req *pb.GetPendingMem
 	query := `
 		SELECT m.id, m.media_url, m.media_type, m.name, m.dimensions, m.download_count, m.share_count
 		FROM meme m
-		WHERE m.approval_status = 'pending'
+		WHERE m.approval_status = 'pending' AND m.deleted_at IS NULL
 		ORDER BY m.created_at DESC
 		LIMIT $1 OFFSET $2
 	`
 
 	rows, err := s.db.QueryContext(ctx, query, req.PageSize, offset)
 	if err != nil {
-		return nil, s.handleError("error querying pending memes", err, codes.Internal)
+		return nil, s.handleError(ctx, "error querying pending memes", err, codes.Internal)
 	}
 	defer rows.Close()
 
 	// Get total count
 	var totalCount int32
-	err = s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM meme WHERE approval_status = 'pending'").Scan(&totalCount)
+	err = s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM meme WHERE approval_status = 'pending' AND deleted_at IS NULL").Scan(&totalCount)
 	if err != nil {
-		return nil, s.handleError("error counting pending memes", err, codes.Internal)
+		return nil, s.handleError(ctx, "error counting pending memes", err, codes.Internal)
 	}
 
 	// Process results
@@ -671,7 +809,7 @@ func (s *MemeService) GetPendingMemes(ctx context.Context, req *pb.GetPendingMem
 		meme := &pb.MemeResponse{}
 		var dimensions pq.Int32Array
 		if err := rows.Scan(&meme.Id, &meme.MediaUrl, &meme.MediaType, &meme.Name, &dimensions, &meme.DownloadCount, &meme.ShareCount); err != nil {
-			return nil, s.handleError("error scanning meme", err, codes.Internal)
+			return nil, s.handleError(ctx, "error scanning meme", err, codes.Internal)
 		}
 		meme.Dimensions = dimensions
 
@@ -682,7 +820,7 @@ func (s *MemeService) GetPendingMemes(ctx context.Context, req *pb.GetPendingMem
 			WHERE mt.meme_id = $1
 		`, meme.Id)
 		if err != nil {
-			return nil, s.handleError("error querying tags", err, codes.Internal)
+			return nil, s.handleError(ctx, "error querying tags", err, codes.Internal)
 		}
 
 		var tags []string
@@ -690,7 +828,7 @@ func (s *MemeService) GetPendingMemes(ctx context.Context, req *pb.GetPendingMem
 			var tag string
 			if err := tagRows.Scan(&tag); err != nil {
 				tagRows.Close()
-				return nil, s.handleError("error scanning tag", err, codes.Internal)
+				return nil, s.handleError(ctx, "error scanning tag", err, codes.Internal)
 			}
 			tags = append(tags, tag)
 		}
@@ -714,9 +852,11 @@ func (s *MemeService) GetPendingMemes(ctx context.Context, req *pb.GetPendingMem
 
 // ApproveMeme approves a pending meme by updating its approval status
 func (s *MemeService) ApproveMeme(ctx context.Context, req *pb.ApproveMemeRequest) (*pb.ApproveMemeResponse, error) {
+	ctx, end := s.observe(ctx, "ApproveMeme")
+	defer end()
 	// Validate meme_id format (UUID)
 	if err := utils.ValidateUUID(req.MemeId); err != nil {
-		s.log.Warn("Invalid meme ID format for approval", "MemeID", req.MemeId)
+		s.log.WarnContext(ctx, "Invalid meme ID format for approval", "MemeID", req.MemeId)
 		return &pb.ApproveMemeResponse{
 			Success: false,
 			Error:   "Invalid meme ID format",
@@ -730,11 +870,11 @@ func (s *MemeService) ApproveMeme(ctx context.Context, req *pb.ApproveMemeReques
 		SET approval_status = 'approved',
 		    approved_at = NOW(),
 		    approved_by = 'admin'
-		WHERE id = $1 AND approval_status = 'pending'
+		WHERE id = $1 AND approval_status = 'pending' AND deleted_at IS NULL
 	`, req.MemeId)
 
 	if err != nil {
-		s.log.Error("Error approving meme", "Error", err, "MemeID", req.MemeId)
+		s.log.ErrorContext(ctx, "Error approving meme", "Error", err, "MemeID", req.MemeId)
 		return &pb.ApproveMemeResponse{
 			Success: false,
 			Error:   "Internal server error",
@@ -744,7 +884,7 @@ func (s *MemeService) ApproveMeme(ctx context.Context, req *pb.ApproveMemeReques
 	// Check rows affected
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		s.log.Error("Error checking rows affected", "Error", err, "MemeID", req.MemeId)
+		s.log.ErrorContext(ctx, "Error checking rows affected", "Error", err, "MemeID", req.MemeId)
 		return &pb.ApproveMemeResponse{
 			Success: false,
 			Error:   "Internal server error",
@@ -752,7 +892,7 @@ func (s *MemeService) ApproveMeme(ctx context.Context, req *pb.ApproveMemeReques
 	}
 
 	if rowsAffected == 0 {
-		s.log.Warn("Meme not found or already approved", "MemeID", req.MemeId)
+		s.log.WarnContext(ctx, "Meme not found or already approved", "MemeID", req.MemeId)
 		return &pb.ApproveMemeResponse{
 			Success: false,
 			Error:   "Meme not found or already approved",
@@ -760,10 +900,209 @@ func (s *MemeService) ApproveMeme(ctx context.Context, req *pb.ApproveMemeReques
 	}
 
 	// Log successful approval
-	s.log.Info("Meme approved successfully", "MemeID", req.MemeId)
+	s.log.InfoContext(ctx, "Meme approved successfully", "MemeID", req.MemeId)
 
 	return &pb.ApproveMemeResponse{
 		Success: true,
 		Error:   "",
 	}, nil
 }
+
+// GetDeletedMemes lists the memes in the trash, most recently deleted first
+func (s *MemeService) GetDeletedMemes(ctx context.Context, req *pb.GetDeletedMemesRequest) (*pb.MemesResponse, error) {
+	ctx, end := s.observe(ctx, "GetDeletedMemes")
+	defer end()
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PageSize < 1 {
+		req.PageSize = 50
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT m.id, m.media_url, m.media_type, m.name, m.dimensions, m.download_count, m.share_count, m.deleted_at
+		FROM meme m
+		WHERE m.deleted_at IS NOT NULL
+		ORDER BY m.deleted_at DESC
+		LIMIT $1 OFFSET $2
+	`, req.PageSize, offset)
+	if err != nil {
+		return nil, s.handleError(ctx, "error querying deleted memes", err, codes.Internal)
+	}
+	defer rows.Close()
+
+	var totalCount int32
+	err = s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM meme WHERE deleted_at IS NOT NULL").Scan(&totalCount)
+	if err != nil {
+		return nil, s.handleError(ctx, "error counting deleted memes", err, codes.Internal)
+	}
+
+	var memes []*pb.MemeResponse
+	for rows.Next() {
+		meme := &pb.MemeResponse{}
+		var dimensions pq.Int32Array
+		var deletedAt time.Time
+		if err := rows.Scan(&meme.Id, &meme.MediaUrl, &meme.MediaType, &meme.Name, &dimensions, &meme.DownloadCount, &meme.ShareCount, &deletedAt); err != nil {
+			return nil, s.handleError(ctx, "error scanning meme", err, codes.Internal)
+		}
+		meme.Dimensions = dimensions
+		meme.DeletedAt = deletedAt.UTC().Format(time.RFC3339)
+		memes = append(memes, meme)
+	}
+
+	totalPages := totalCount / req.PageSize
+	if totalCount%req.PageSize != 0 {
+		totalPages++
+	}
+
+	return &pb.MemesResponse{
+		Memes:      memes,
+		TotalCount: totalCount,
+		Page:       req.Page,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// RestoreMeme moves a meme out of the trash and its image back to the main bucket
+func (s *MemeService) RestoreMeme(ctx context.Context, req *pb.RestoreMemeRequest) (*pb.RestoreMemeResponse, error) {
+	ctx, end := s.observe(ctx, "RestoreMeme")
+	defer end()
+	if err := utils.ValidateUUID(req.MemeId); err != nil {
+		return &pb.RestoreMemeResponse{
+			Success: false,
+			Error:   "Invalid meme ID format",
+		}, status.Error(codes.InvalidArgument, "Invalid meme ID format")
+	}
+
+	txn, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return &pb.RestoreMemeResponse{Success: false}, s.handleError(ctx, "error starting transaction", err, codes.Internal)
+	}
+	defer txn.Rollback()
+
+	var mediaURL string
+	err = txn.QueryRowContext(ctx, `
+		UPDATE meme
+		SET deleted_at = NULL
+		WHERE id = $1 AND deleted_at IS NOT NULL
+		RETURNING media_url
+	`, req.MemeId).Scan(&mediaURL)
+	if err == sql.ErrNoRows {
+		s.log.WarnContext(ctx, "Meme not found in trash", "MemeID", req.MemeId)
+		return &pb.RestoreMemeResponse{
+			Success: false,
+			Error:   "Meme not found in trash",
+		}, status.Error(codes.NotFound, "Meme not found in trash")
+	}
+	if err != nil {
+		return &pb.RestoreMemeResponse{Success: false}, s.handleError(ctx, "error restoring meme", err, codes.Internal)
+	}
+
+	// if the image hasn't been moved to the trash yet, cancelling the move is enough
+	filename := filepath.Base(mediaURL)
+	result, err := txn.ExecContext(ctx, `
+		UPDATE storage_outbox
+		SET processed_at = NOW(), last_error = 'cancelled by restore'
+		WHERE operation = $1 AND filename = $2 AND processed_at IS NULL
+	`, opSoftDelete, filename)
+	if err != nil {
+		return &pb.RestoreMemeResponse{Success: false}, s.handleError(ctx, "error cancelling image deletion", err, codes.Internal)
+	}
+	cancelled, err := result.RowsAffected()
+	if err != nil {
+		return &pb.RestoreMemeResponse{Success: false}, s.handleError(ctx, "error cancelling image deletion", err, codes.Internal)
+	}
+
+	// the image must be back in place before the meme becomes visible again
+	if cancelled == 0 {
+		if _, err := s.storage.RestoreImage(ctx, filename); err != nil {
+			return &pb.RestoreMemeResponse{Success: false}, s.handleError(ctx, "error restoring image", err, codes.Internal)
+		}
+	}
+	if err := txn.Commit(); err != nil {
+		if cancelled == 0 {
+			s.discardImage(ctx, filename)
+		}
+		return &pb.RestoreMemeResponse{Success: false}, s.handleError(ctx, "error committing the transaction", err, codes.Internal)
+	}
+
+	s.log.InfoContext(ctx, "Meme restored from trash", "MemeID", req.MemeId)
+	return &pb.RestoreMemeResponse{Success: true}, nil
+}
+
+// PurgeDeletedMemes permanently deletes memes that have been in the trash for more than OlderThanDays.
+// The images are purged from the trash once the rows are gone; failed purges are retried by the reconciler.
+func (s *MemeService) PurgeDeletedMemes(ctx context.Context, req *pb.PurgeDeletedMemesRequest) (*pb.PurgeDeletedMemesResponse, error) {
+	ctx, end := s.observe(ctx, "PurgeDeletedMemes")
+	defer end()
+	if req.OlderThanDays < 0 {
+		return nil, status.Error(codes.InvalidArgument, "older_than_days must not be negative")
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id::text, media_url
+		FROM meme
+		WHERE deleted_at IS NOT NULL AND deleted_at < NOW() - make_interval(days => $1)
+	`, req.OlderThanDays)
+	if err != nil {
+		return nil, s.handleError(ctx, "error querying memes to purge", err, codes.Internal)
+	}
+	type trashedMeme struct {
+		id       string
+		mediaURL string
+	}
+	var trashed []trashedMeme
+	for rows.Next() {
+		var m trashedMeme
+		if err := rows.Scan(&m.id, &m.mediaURL); err != nil {
+			rows.Close()
+			return nil, s.handleError(ctx, "error scanning meme", err, codes.Internal)
+		}
+		trashed = append(trashed, m)
+	}
+	rows.Close()
+
+	var purged int32
+	for _, m := range trashed {
+		opID, err := s.purgeMeme(ctx, m.id, filepath.Base(m.mediaURL))
+		if err != nil {
+			s.log.ErrorContext(ctx, "Failed to purge meme", "Error", err, "MemeID", m.id)
+			continue
+		}
+		if opID == 0 {
+			s.log.InfoContext(ctx, "Meme left the trash before it was purged", "MemeID", m.id)
+			continue
+		}
+		s.runStorageOps(ctx, opID)
+		purged++
+	}
+	s.log.InfoContext(ctx, "Purged deleted memes", "Purged", purged, "Candidates", len(trashed))
+	return &pb.PurgeDeletedMemesResponse{Purged: purged}, nil
+}
+
+// purgeMeme removes the meme row and its meme-tag relations (image sources cascade)
+// and schedules the image for removal from the trash. It returns 0 and changes nothing when
+// the meme was restored since it was listed.
+func (s *MemeService) purgeMeme(ctx context.Context, memeID string, filename string) (int64, error) {
+	txn, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer txn.Rollback()
+	if _, err := txn.ExecContext(ctx, "DELETE FROM meme_tag WHERE meme_id = $1", memeID); err != nil {
+		return 0, err
+	}
+	result, err := txn.ExecContext(ctx, "DELETE FROM meme WHERE id = $1 AND deleted_at IS NOT NULL", memeID)
+	if err != nil {
+		return 0, err
+	}
+	if deleted, err := result.RowsAffected(); err != nil || deleted == 0 {
+		return 0, err
+	}
+	opID, err := enqueueStorageOp(ctx, txn, opPurge, filename, "")
+	if err != nil {
+		return 0, err
+	}
+	return opID, txn.Commit()
+}
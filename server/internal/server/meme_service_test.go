@@ -0,0 +1,539 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/BassemHalim/memesHub/internal/proto/memeService"
+	"github.com/BassemHalim/memesHub/internal/search"
+	"github.com/BassemHalim/memesHub/internal/storage"
+)
+
+// failingStorage is a storage.Storage that records every call and fails the ones listed in fail.
+// OpenImage serves the content of images.
+type failingStorage struct {
+	fail   map[string]bool
+	calls  []string
+	images map[string][]byte
+	// staged direct uploads by key
+	uploads map[string][]byte
+}
+
+var errStorage = errors.New("storage unavailable")
+
+func (f *failingStorage) call(name string) error {
+	f.calls = append(f.calls, name)
+	if f.fail[name] {
+		return errStorage
+	}
+	return nil
+}
+
+func (f *failingStorage) SaveImage(ctx context.Context, filename string, image io.Reader) (storage.ImageInfo, error) {
+	return storage.ImageInfo{Filename: filename, URL: f.ImageUrl(filename)}, f.call("SaveImage")
+}
+
+func (f *failingStorage) SoftDeleteImage(ctx context.Context, filename string) error {
+	return f.call("SoftDeleteImage")
+}
+
+func (f *failingStorage) RestoreImage(ctx context.Context, filename string) (string, error) {
+	return f.ImageUrl(filename), f.call("RestoreImage")
+}
+
+func (f *failingStorage) PurgeImage(ctx context.Context, filename string) error {
+	return f.call("PurgeImage")
+}
+
+func (f *failingStorage) RenameImage(ctx context.Context, oldFilename string, newFilename string) (string, error) {
+	return f.ImageUrl(newFilename), f.call("RenameImage")
+}
+
+func (f *failingStorage) ImageUrl(filename string) string {
+	return "https://imgs.example.com/imgs/" + filename
+}
+
+func (f *failingStorage) ListImages(ctx context.Context) ([]storage.ImageInfo, error) {
+	return nil, f.call("ListImages")
+}
+
+func (f *failingStorage) OpenImage(ctx context.Context, filename string) (io.ReadCloser, storage.ImageInfo, error) {
+	if err := f.call("OpenImage"); err != nil {
+		return nil, storage.ImageInfo{}, err
+	}
+	image, ok := f.images[filename]
+	if !ok {
+		return nil, storage.ImageInfo{}, errors.New("image not found")
+	}
+	return io.NopCloser(bytes.NewReader(image)), storage.ImageInfo{Filename: filename, Size: int64(len(image))}, nil
+}
+
+func (f *failingStorage) PresignUpload(ctx context.Context, key string, contentType string, size int64, expires time.Duration) (storage.PresignedUpload, error) {
+	return storage.PresignedUpload{
+		URL:       "https://bucket.example.com/uploads/" + key + "?signature=test",
+		Method:    http.MethodPut,
+		Headers:   map[string]string{"Content-Type": contentType},
+		ExpiresAt: time.Now().Add(expires),
+	}, f.call("PresignUpload")
+}
+
+func (f *failingStorage) PublishUpload(ctx context.Context, key string, filename string) (storage.ImageInfo, error) {
+	if err := f.call("PublishUpload"); err != nil {
+		return storage.ImageInfo{}, err
+	}
+	upload, ok := f.uploads[key]
+	if !ok {
+		return storage.ImageInfo{}, errors.New("upload not found")
+	}
+	if f.images == nil {
+		f.images = map[string][]byte{}
+	}
+	f.images[filename] = upload
+	delete(f.uploads, key)
+	return storage.ImageInfo{Filename: filename, URL: f.ImageUrl(filename)}, nil
+}
+
+func (f *failingStorage) DeleteUpload(ctx context.Context, key string) error {
+	delete(f.uploads, key)
+	return f.call("DeleteUpload")
+}
+
+func (f *failingStorage) Ping(ctx context.Context) error {
+	return f.call("Ping")
+}
+
+func (f *failingStorage) called(name string) bool {
+	for _, c := range f.calls {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+const testMemeID = "7218d21c-ac37-4ebe-b436-c51486d23b95"
+
+func newTestMemeService(t *testing.T, store *failingStorage) (*MemeService, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal("Failed to create sqlmock", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewMemeService(db, GetDebugLogger(), store), mock
+}
+
+// expectStorageOp expects the outbox entry id to be claimed and its outcome recorded
+func expectStorageOp(mock sqlmock.Sqlmock, id int64, operation string, filename string, target string, succeeds bool) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, operation, filename, COALESCE\(target, ''\), attempts\s+FROM storage_outbox`).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "operation", "filename", "target", "attempts"}).AddRow(id, operation, filename, target, 0))
+	if succeeds {
+		mock.ExpectExec(`UPDATE storage_outbox\s+SET attempts = attempts \+ 1, last_error = NULL, processed_at = NOW\(\)`).
+			WithArgs(id).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	} else {
+		mock.ExpectExec(`UPDATE storage_outbox\s+SET attempts = attempts \+ 1, last_error = \$2`).
+			WithArgs(id, errStorage.Error()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+}
+
+func TestDeleteMemeStorageFailureIsRetriedLater(t *testing.T) {
+	store := &failingStorage{fail: map[string]bool{"SoftDeleteImage": true}}
+	service, mock := newTestMemeService(t, store)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE meme\s+SET deleted_at = NOW\(\)`).
+		WithArgs(testMemeID).
+		WillReturnRows(sqlmock.NewRows([]string{"media_url"}).AddRow("https://imgs.example.com/imgs/meme.png"))
+	mock.ExpectQuery(`INSERT INTO storage_outbox`).
+		WithArgs(opSoftDelete, "meme.png", "").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+	expectStorageOp(mock, 1, opSoftDelete, "meme.png", "", false)
+
+	resp, err := service.DeleteMeme(context.Background(), &pb.DeleteMemeRequest{Id: testMemeID})
+	if err != nil || !resp.Success {
+		t.Fatal("Delete should succeed once the database commits even if the image move fails", err)
+	}
+	if !store.called("SoftDeleteImage") {
+		t.Error("The image move should be attempted after commit")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDeleteMemeCommitFailureLeavesImage(t *testing.T) {
+	store := &failingStorage{}
+	service, mock := newTestMemeService(t, store)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE meme\s+SET deleted_at = NOW\(\)`).
+		WithArgs(testMemeID).
+		WillReturnRows(sqlmock.NewRows([]string{"media_url"}).AddRow("https://imgs.example.com/imgs/meme.png"))
+	mock.ExpectQuery(`INSERT INTO storage_outbox`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit().WillReturnError(errors.New("connection reset"))
+
+	if _, err := service.DeleteMeme(context.Background(), &pb.DeleteMemeRequest{Id: testMemeID}); err == nil {
+		t.Fatal("Delete should fail when the commit fails")
+	}
+	if len(store.calls) != 0 {
+		t.Errorf("Storage should not be touched when the commit fails, got %v", store.calls)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUpdateMemeSaveFailureKeepsOldImage(t *testing.T) {
+	store := &failingStorage{fail: map[string]bool{"SaveImage": true}}
+	service, mock := newTestMemeService(t, store)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT media_url FROM meme`).
+		WithArgs(testMemeID).
+		WillReturnRows(sqlmock.NewRows([]string{"media_url"}).AddRow("https://imgs.example.com/imgs/old.png"))
+	mock.ExpectQuery(`INSERT INTO storage_outbox`).
+		WithArgs(opRename, "old.png", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec(`UPDATE meme\s+SET media_url = \$1`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+
+	_, err := service.UpdateMeme(context.Background(), &pb.UpdateMemeRequest{
+		Id:         testMemeID,
+		Image:      []byte("image"),
+		MediaType:  "image/png",
+		Dimensions: []int32{10, 10},
+	})
+	if err == nil {
+		t.Fatal("Update should fail when the new image can't be saved")
+	}
+	if store.called("RenameImage") {
+		t.Error("The old image should not be renamed when the update is rolled back")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUpdateMemeCommitFailureDiscardsNewImage(t *testing.T) {
+	store := &failingStorage{}
+	service, mock := newTestMemeService(t, store)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT media_url FROM meme`).
+		WithArgs(testMemeID).
+		WillReturnRows(sqlmock.NewRows([]string{"media_url"}).AddRow("https://imgs.example.com/imgs/old.png"))
+	mock.ExpectQuery(`INSERT INTO storage_outbox`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec(`UPDATE meme\s+SET media_url = \$1`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit().WillReturnError(errors.New("connection reset"))
+
+	_, err := service.UpdateMeme(context.Background(), &pb.UpdateMemeRequest{
+		Id:         testMemeID,
+		Image:      []byte("image"),
+		MediaType:  "image/png",
+		Dimensions: []int32{10, 10},
+	})
+	if err == nil {
+		t.Fatal("Update should fail when the commit fails")
+	}
+	if store.called("RenameImage") {
+		t.Error("The old image should not be renamed when the commit fails")
+	}
+	if !store.called("SoftDeleteImage") {
+		t.Error("The new image should be discarded when the commit fails")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUpdateMemeRenamesOldImageAfterCommit(t *testing.T) {
+	store := &failingStorage{}
+	service, mock := newTestMemeService(t, store)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT media_url FROM meme`).
+		WithArgs(testMemeID).
+		WillReturnRows(sqlmock.NewRows([]string{"media_url"}).AddRow("https://imgs.example.com/imgs/old.png"))
+	mock.ExpectQuery(`INSERT INTO storage_outbox`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectExec(`UPDATE meme\s+SET media_url = \$1`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectStorageOp(mock, 7, opRename, "old.png", "old.png_1", true)
+
+	resp, err := service.UpdateMeme(context.Background(), &pb.UpdateMemeRequest{
+		Id:         testMemeID,
+		Image:      []byte("image"),
+		MediaType:  "image/png",
+		Dimensions: []int32{10, 10},
+	})
+	if err != nil || !resp.Success {
+		t.Fatal("Update should succeed", err)
+	}
+	if len(store.calls) != 2 || store.calls[0] != "SaveImage" || store.calls[1] != "RenameImage" {
+		t.Errorf("Expected the new image to be saved before the old one is renamed, got %v", store.calls)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUpdateMemeInTrashNotFound(t *testing.T) {
+	service, mock := newTestMemeService(t, &failingStorage{})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT media_url FROM meme\s+WHERE id = \$1 AND deleted_at IS NULL`).
+		WithArgs(testMemeID).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	_, err := service.UpdateMeme(context.Background(), &pb.UpdateMemeRequest{Id: testMemeID, Name: "renamed", Tags: []string{"funny"}})
+	if status.Code(err) != codes.NotFound {
+		t.Fatal("Expected NotFound for a meme in the trash, got", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPurgeDeletedMemesSkipsRestoredMemes(t *testing.T) {
+	store := &failingStorage{}
+	service, mock := newTestMemeService(t, store)
+
+	mock.ExpectQuery(`SELECT id::text, media_url\s+FROM meme`).
+		WithArgs(int32(30)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "media_url"}).AddRow(testMemeID, "https://imgs.example.com/imgs/restored.png"))
+	// the meme was restored after it was listed
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM meme_tag WHERE meme_id = \$1`).
+		WithArgs(testMemeID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`DELETE FROM meme WHERE id = \$1 AND deleted_at IS NOT NULL`).
+		WithArgs(testMemeID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	resp, err := service.PurgeDeletedMemes(context.Background(), &pb.PurgeDeletedMemesRequest{OlderThanDays: 30})
+	if err != nil {
+		t.Fatal("Purge should succeed", err)
+	}
+	if resp.Purged != 0 {
+		t.Errorf("The restored meme shouldn't be counted, purged %d", resp.Purged)
+	}
+	if len(store.calls) != 0 {
+		t.Errorf("The image of the restored meme shouldn't be purged, got %v", store.calls)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRestoreMemeCancelsPendingSoftDelete(t *testing.T) {
+	store := &failingStorage{}
+	service, mock := newTestMemeService(t, store)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE meme\s+SET deleted_at = NULL`).
+		WithArgs(testMemeID).
+		WillReturnRows(sqlmock.NewRows([]string{"media_url"}).AddRow("https://imgs.example.com/imgs/meme.png"))
+	mock.ExpectExec(`UPDATE storage_outbox\s+SET processed_at = NOW\(\), last_error = 'cancelled by restore'`).
+		WithArgs(opSoftDelete, "meme.png").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	resp, err := service.RestoreMeme(context.Background(), &pb.RestoreMemeRequest{MemeId: testMemeID})
+	if err != nil || !resp.Success {
+		t.Fatal("Restore should succeed", err)
+	}
+	if len(store.calls) != 0 {
+		t.Errorf("An image that was never moved to the trash should not be restored, got %v", store.calls)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRestoreMemeStorageFailureKeepsMemeInTrash(t *testing.T) {
+	store := &failingStorage{fail: map[string]bool{"RestoreImage": true}}
+	service, mock := newTestMemeService(t, store)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE meme\s+SET deleted_at = NULL`).
+		WithArgs(testMemeID).
+		WillReturnRows(sqlmock.NewRows([]string{"media_url"}).AddRow("https://imgs.example.com/imgs/meme.png"))
+	mock.ExpectExec(`UPDATE storage_outbox`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	if _, err := service.RestoreMeme(context.Background(), &pb.RestoreMemeRequest{MemeId: testMemeID}); err == nil {
+		t.Fatal("Restore should fail when the image can't be restored")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReconcileStorageOpsRetriesPendingEntries(t *testing.T) {
+	store := &failingStorage{fail: map[string]bool{"PurgeImage": true}}
+	service, mock := newTestMemeService(t, store)
+
+	mock.ExpectQuery(`SELECT id\s+FROM storage_outbox`).
+		WithArgs(maxStorageOpAttempts).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	expectStorageOp(mock, 1, opSoftDelete, "a.png", "", true)
+	expectStorageOp(mock, 2, opPurge, "b.png", "", false)
+
+	pending, err := service.ReconcileStorageOps(context.Background())
+	if err != nil {
+		t.Fatal("Reconcile should not fail", err)
+	}
+	if pending != 1 {
+		t.Errorf("Expected 1 pending entry, got %d", pending)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSearchMemesCompilesFilters(t *testing.T) {
+	service, mock := newTestMemeService(t, &failingStorage{})
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM \(SELECT m.id::text FROM search_memes_fuzzy\(\$2\) f JOIN meme m ON m.id = f.id WHERE EXISTS .*\) results`).
+		WithArgs("funny", "drake").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`SELECT m.id::text FROM search_memes_fuzzy\(\$2\) .* LIMIT \$3 OFFSET \$4`).
+		WithArgs("funny", "drake", 10, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	resp, err := service.SearchMemes(context.Background(), &pb.SearchMemesRequest{Query: "drake tag:funny", Page: 2, PageSize: 10})
+	if err != nil {
+		t.Fatal("Search should succeed", err)
+	}
+	if resp.TotalCount != 0 || len(resp.Memes) != 0 {
+		t.Errorf("Expected no memes, got %d", len(resp.Memes))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSearchMemesSortsByRanking(t *testing.T) {
+	service, mock := newTestMemeService(t, &failingStorage{})
+	service.ranking = search.Ranking{Text: 1, Downloads: 0.5}
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM \(.* ORDER BY \(\$2::float8 \* f.rank \+ \$3::float8 \* ln\(1 \+ m.download_count::float8\)\) DESC, m.id\) results`).
+		WithArgs("cat", 1.0, 0.5).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`ORDER BY \(.*\) DESC, m.id LIMIT \$4 OFFSET \$5`).
+		WithArgs("cat", 1.0, 0.5, 40, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM \(.* ORDER BY m.created_at DESC, m.id\) results`).
+		WithArgs("cat").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`ORDER BY m.created_at DESC, m.id LIMIT \$2 OFFSET \$3`).
+		WithArgs("cat", 40, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	for _, sort := range []pb.SearchSort{pb.SearchSort_SEARCH_RELEVANCE, pb.SearchSort_SEARCH_NEWEST} {
+		if _, err := service.SearchMemes(context.Background(), &pb.SearchMemesRequest{Query: "cat", Sort: sort}); err != nil {
+			t.Fatal("Search should succeed", err)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSearchMemesRejectsInvalidQueries(t *testing.T) {
+	service, mock := newTestMemeService(t, &failingStorage{})
+
+	for _, query := range []string{"", "type:bmp", "date:yesterday"} {
+		_, err := service.SearchMemes(context.Background(), &pb.SearchMemesRequest{Query: query})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Search %q should be an invalid argument, got %v", query, err)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGetTimelineMemesFilters(t *testing.T) {
+	service, mock := newTestMemeService(t, &failingStorage{})
+
+	filters := `AND \(SELECT COUNT\(\*\) FROM meme_tag mt JOIN tag t ON t.id = mt.tag_id WHERE mt.meme_id = m.id AND t.normalized_name = ANY\(\$1\)\) = \$2` +
+		` AND NOT EXISTS \(SELECT 1 FROM meme_tag mt JOIN tag t ON t.id = mt.tag_id WHERE mt.meme_id = m.id AND t.normalized_name = ANY\(\$3\)\)` +
+		` AND m.media_type = \$4`
+	args := []driver.Value{`{"football","ucl"}`, int64(2), `{"nsfw"}`, "image/gif"}
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM meme m WHERE m.approval_status = 'approved' AND m.deleted_at IS NULL ` + filters + `$`).
+		WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(filters + `\s+ORDER BY m.created_at DESC LIMIT \$5 OFFSET \$6`).
+		WithArgs(append(args, int64(10), int64(0))...).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "media_url", "media_type", "name", "dimensions", "download_count", "share_count"}).
+			AddRow(testMemeID, "https://imgs.example.com/imgs/meme.gif", "image/gif", "goal", "{480,270}", 0, 0))
+	mock.ExpectQuery(`SELECT t.name\s+FROM tag t`).
+		WithArgs(testMemeID).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("football").AddRow("ucl"))
+
+	resp, err := service.GetTimelineMemes(context.Background(), &pb.GetTimelineRequest{
+		PageSize:    10,
+		Tags:        []string{"Football", " ucl", "football"},
+		ExcludeTags: []string{"nsfw"},
+		TagMatch:    pb.TagMatch_ALL_TAGS,
+		MediaType:   "image/gif",
+	})
+	if err != nil {
+		t.Fatal("Timeline should succeed", err)
+	}
+	if len(resp.Memes) != 1 || resp.TotalCount != 1 {
+		t.Errorf("Expected 1 meme, got %d", len(resp.Memes))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAddTagsNormalizesArabicSpellings(t *testing.T) {
+	service, mock := newTestMemeService(t, &failingStorage{})
+
+	mock.ExpectBegin()
+	// both spellings of Ahmed are the same tag, the first one names it
+	for _, tag := range [][2]string{{"أحمد", "احمد"}, {"احمد", "احمد"}, {"Funny", "funny"}} {
+		mock.ExpectQuery(`INSERT INTO tag \(name, normalized_name\)\s+VALUES \(\$1, \$2\)\s+ON CONFLICT \(normalized_name\) DO UPDATE SET name = tag.name`).
+			WithArgs(tag[0], tag[1]).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+		mock.ExpectExec(`INSERT INTO meme_tag`).
+			WithArgs(testMemeID, 7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+
+	_, err := service.AddTags(context.Background(), &pb.AddTagsRequest{MemeId: testMemeID, Tags: []string{"أحمد", " احمد", "ـــ", "Funny"}})
+	if err != nil {
+		t.Fatal("Adding the tags should succeed", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/BassemHalim/memesHub/internal/auth"
+	"github.com/BassemHalim/memesHub/internal/metrics"
+	"github.com/BassemHalim/memesHub/internal/middleware"
+	"github.com/BassemHalim/memesHub/pkg/api"
+)
+
+// The REST API is served under /api/v1. /api is the deprecated unversioned alias of v1 the web
+// client still uses, it will be removed once the client moves to /api/v1.
+const (
+	apiPrefix           = "/api/v1"
+	deprecatedAPIPrefix = "/api"
+)
+
+type RouterOptions struct {
+	Admin              *auth.Admin
+	JWTSecret          string
+	ApplicationDomains []string
+	// serves /imgs/, nil when the media are served by the storage
+	Media http.Handler
+	// serves the presigned PUT /uploads/ of the local storage, nil when clients upload to the bucket
+	Uploads http.Handler
+}
+
+// route is an endpoint of the API, path is relative to /api/v1 and documented in api.OpenAPI
+type route struct {
+	method  string
+	path    string
+	handler http.Handler
+}
+
+func (s *Server) routes(opts RouterOptions) []route {
+	limit := s.RateLimiter.RateLimit
+	requireAdmin := middleware.Auth(opts.JWTSecret, s.log)
+	browserOnly := middleware.ValidateBrowserRequest(opts.ApplicationDomains)
+
+	return []route{
+		{"POST", "/login", http.HandlerFunc(opts.Admin.Login)},
+		{"GET", "/memes", middleware.GzipMiddleware(middleware.Cache(limit(http.HandlerFunc(s.GetTimeline)), 60))},
+		{"GET", "/memes/search", middleware.GzipMiddleware(middleware.Cache(limit(http.HandlerFunc(s.SearchMemes)), 2*60))},
+		{"GET", "/tags/search", middleware.GzipMiddleware(middleware.Cache(limit(http.HandlerFunc(s.SearchTags)), 2*60))},
+		{"GET", "/autocomplete", middleware.GzipMiddleware(middleware.Cache(limit(http.HandlerFunc(s.Autocomplete)), 2*60))},
+		{"GET", "/meme/{id}", middleware.Cache(limit(http.HandlerFunc(s.GetMeme)), 24*60)},
+		{"POST", "/meme", limit(http.HandlerFunc(s.UploadMeme))},
+		{"POST", "/meme/upload", limit(http.HandlerFunc(s.CreateUploadSlot))},
+		{"POST", "/meme/upload/{id}/finalize", limit(http.HandlerFunc(s.FinalizeUpload))},
+		{"POST", "/memes/{id}/download", browserOnly(limit(http.HandlerFunc(s.TrackDownload)))},
+		{"POST", "/memes/{id}/share", browserOnly(limit(http.HandlerFunc(s.TrackShare)))},
+		{"GET", "/banner", http.HandlerFunc(s.GetBanner)},
+		{"GET", "/openapi.yaml", http.HandlerFunc(serveOpenAPI)},
+
+		{"DELETE", "/admin/meme/{id}", limit(requireAdmin(http.HandlerFunc(s.DeleteMeme)))},
+		{"PATCH", "/admin/meme/{id}/tags", limit(requireAdmin(http.HandlerFunc(s.UpdateTags)))},
+		{"PATCH", "/admin/meme/{id}", limit(requireAdmin(http.HandlerFunc(s.PatchMeme)))},
+		{"PUT", "/admin/meme/{id}/source", limit(requireAdmin(http.HandlerFunc(s.UpdateMemeSource)))},
+		// same as /memes but without caching or rate limiting
+		{"GET", "/admin/memes", middleware.GzipMiddleware(requireAdmin(http.HandlerFunc(s.AdminGetTimeline)))},
+		{"DELETE", "/admin/cache", requireAdmin(http.HandlerFunc(s.FlushCache))},
+		{"GET", "/admin/memes/pending", requireAdmin(http.HandlerFunc(s.GetPendingMemes))},
+		{"PATCH", "/admin/meme/{id}/approve", requireAdmin(http.HandlerFunc(s.ApproveMeme))},
+		{"PUT", "/admin/banner", requireAdmin(http.HandlerFunc(s.UpdateBanner))},
+		{"GET", "/admin/memes/trash", requireAdmin(http.HandlerFunc(s.GetTrash))},
+		{"PATCH", "/admin/meme/{id}/restore", requireAdmin(http.HandlerFunc(s.RestoreMeme))},
+	}
+}
+
+// Router serves the API under /api/v1 and /api, the media and the operational endpoints
+func (s *Server) Router(opts RouterOptions) *http.ServeMux {
+	apiRouter := http.NewServeMux()
+	for _, route := range s.routes(opts) {
+		apiRouter.Handle(route.method+" "+route.path, route.handler)
+	}
+
+	mainRouter := http.NewServeMux()
+	mainRouter.Handle(apiPrefix+"/", http.StripPrefix(apiPrefix, middleware.Route(apiPrefix, apiRouter)))
+	mainRouter.Handle(deprecatedAPIPrefix+"/", deprecated(http.StripPrefix(deprecatedAPIPrefix, middleware.Route(deprecatedAPIPrefix, apiRouter))))
+	if opts.Media != nil {
+		mainRouter.Handle("/imgs/", s.RateLimiter.RateLimit(opts.Media))
+	}
+	if opts.Uploads != nil {
+		mainRouter.Handle("PUT /uploads/", s.RateLimiter.RateLimit(opts.Uploads))
+	}
+	mainRouter.HandleFunc("GET /healthz", s.Healthz)
+	mainRouter.HandleFunc("GET /readyz", s.Readyz)
+	mainRouter.HandleFunc("GET /version", s.Version)
+	mainRouter.Handle("GET /metrics", metrics.Handler())
+	return mainRouter
+}
+
+// deprecated points the clients of the unversioned API to the same path under /api/v1 (RFC 9745)
+func deprecated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		successor := apiPrefix + r.URL.Path[len(deprecatedAPIPrefix):]
+		w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// GET /api/v1/openapi.yaml
+func serveOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(api.OpenAPI)
+}
@@ -4,28 +4,39 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"image"
 	_ "image/gif"
 	_ "image/jpeg"
 	_ "image/png"
-	"os"
 
 	// _ "code.google.com/p/vp8-go/webp" using a webp image isn't great outside of browsers so I will not accept webp for now (will convert to jpeg later)
 
 	"log/slog"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/patrickmn/go-cache"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 
+	"github.com/BassemHalim/memesHub/internal/apierror"
 	"github.com/BassemHalim/memesHub/internal/config"
-	"github.com/BassemHalim/memesHub/internal/db"
-	"github.com/BassemHalim/memesHub/internal/meme"
-	"github.com/BassemHalim/memesHub/internal/storage"
+	"github.com/BassemHalim/memesHub/internal/fetcher"
+	"github.com/BassemHalim/memesHub/internal/metrics"
+	"github.com/BassemHalim/memesHub/internal/search"
+	"github.com/BassemHalim/memesHub/internal/tracing"
+	"github.com/BassemHalim/memesHub/pkg/api"
+	"go.opentelemetry.io/otel/attribute"
 
 	pb "github.com/BassemHalim/memesHub/internal/proto/memeService"
 	rateLimiter "github.com/BassemHalim/memesHub/internal/rate-limiter/IP_ratelimiter"
@@ -39,55 +50,122 @@ type MemeServiceClient interface {
 	GetTimelineMemes(ctx context.Context, in *pb.GetTimelineRequest) (*pb.MemesResponse, error)
 	SearchMemes(ctx context.Context, in *pb.SearchMemesRequest) (*pb.MemesResponse, error)
 	SearchTags(ctx context.Context, in *pb.SearchTagsRequest) (*pb.TagsResponse, error)
+	Autocomplete(ctx context.Context, in *pb.AutocompleteRequest) (*pb.AutocompleteResponse, error)
 	AddTags(ctx context.Context, in *pb.AddTagsRequest) (*pb.AddTagsResponse, error)
 	UpdateMeme(ctx context.Context, in *pb.UpdateMemeRequest) (*pb.UpdateMemeResponse, error)
 	GetPendingMemes(ctx context.Context, in *pb.GetPendingMemesRequest) (*pb.MemesResponse, error)
 	ApproveMeme(ctx context.Context, in *pb.ApproveMemeRequest) (*pb.ApproveMemeResponse, error)
 	IncrementDownload(ctx context.Context, in *pb.IncrementEngagementRequest) (*pb.IncrementEngagementResponse, error)
 	IncrementShare(ctx context.Context, in *pb.IncrementEngagementRequest) (*pb.IncrementEngagementResponse, error)
+	GetDeletedMemes(ctx context.Context, in *pb.GetDeletedMemesRequest) (*pb.MemesResponse, error)
+	RestoreMeme(ctx context.Context, in *pb.RestoreMemeRequest) (*pb.RestoreMemeResponse, error)
+	PurgeDeletedMemes(ctx context.Context, in *pb.PurgeDeletedMemesRequest) (*pb.PurgeDeletedMemesResponse, error)
+	CreateUploadSlot(ctx context.Context, in *pb.CreateUploadSlotRequest) (*pb.UploadSlotResponse, error)
+	FinalizeUpload(ctx context.Context, in *pb.FinalizeUploadRequest) (*pb.MemeResponse, error)
+	UpdateMemeSource(ctx context.Context, in *pb.UpdateMemeSourceRequest) (*pb.UpdateMemeSourceResponse, error)
 }
 
 type Server struct {
 	memeService     MemeServiceClient
-	config          *config.Config
+	config          *config.Store
 	RateLimiter     *rateLimiter.RateLimiter
 	structValidator *validator.Validate
 	log             *slog.Logger
-	client          *http.Client
+	fetcher         *fetcher.Fetcher
 	cache           *cache.Cache
+	readiness       []readinessCheck
+	// connection to the remote meme service, nil when it runs in-process
+	conn *grpc.ClientConn
+	// set once shutdown begins so /readyz fails
+	draining atomic.Bool
 }
 
-func New(config *config.Config, rateLimiter *rateLimiter.RateLimiter, log *slog.Logger, client *http.Client, cache *cache.Cache) (*Server, error) {
-	db, err := db.New()
+func New(config *config.Store, rateLimiter *rateLimiter.RateLimiter, log *slog.Logger, fetcher *fetcher.Fetcher, cache *cache.Cache) (*Server, error) {
+	cfg := config.Current()
+	if cfg.GRPC.MemeServiceAddr != "" {
+		conn, err := DialMemeService(cfg.GRPC)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to the meme service: %w", err)
+		}
+		server, err := NewWithMemeService(&grpcMemeService{client: pb.NewMemeServiceClient(conn)}, config, rateLimiter, log, fetcher, cache)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		server.conn = conn
+		server.readiness = []readinessCheck{
+			{name: "meme_service", check: checkMemeServiceHealth(healthpb.NewHealthClient(conn))},
+		}
+		log.Info("Using the remote meme service", "Address", cfg.GRPC.MemeServiceAddr)
+		return server, nil
+	}
+
+	memeService, err := NewMemeServiceFromConfig(cfg, log)
+	if err != nil {
+		return nil, err
+	}
+	server, err := NewWithMemeService(memeService, config, rateLimiter, log, fetcher, cache)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
+		return nil, err
 	}
-	storage := storage.NewR2(os.Getenv("R2_BUCKET_NAME"), log)
-	memeService := NewMemeService(db, log, storage)
-	return newWithMemeService(memeService, config, rateLimiter, log, client, cache)
+	server.readiness = []readinessCheck{
+		{name: "database", check: memeService.db.PingContext},
+		{name: "storage", check: memeService.storage.Ping},
+	}
+	return server, nil
 }
 
-// newWithMemeService is used in tests to inject a mock MemeServiceClient.
-func newWithMemeService(memeService MemeServiceClient, config *config.Config, rateLimiter *rateLimiter.RateLimiter, log *slog.Logger, client *http.Client, cache *cache.Cache) (*Server, error) {
+// Close closes the connection to the remote meme service
+func (s *Server) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.Close()
+}
+
+// NewWithMemeService serves memeService instead of connecting to the meme service. New passes
+// the in-process meme service, tests pass a fake.
+func NewWithMemeService(memeService MemeServiceClient, config *config.Store, rateLimiter *rateLimiter.RateLimiter, log *slog.Logger, fetcher *fetcher.Fetcher, cache *cache.Cache) (*Server, error) {
 	return &Server{
 		memeService:     memeService,
 		config:          config,
 		RateLimiter:     rateLimiter,
 		structValidator: validator.New(validator.WithRequiredStructEnabled()),
 		log:             log,
-		client:          client,
+		fetcher:         fetcher,
 		cache:           cache,
 	}, nil
 }
 
-func (s *Server) handleError(w http.ResponseWriter, err error, message string, statusCode int) {
-	s.log.Error(message, "ERROR", err)
-	http.Error(w, message, statusCode)
+func (s *Server) handleError(w http.ResponseWriter, r *http.Request, err error, message string, statusCode int) {
+	s.log.ErrorContext(r.Context(), message, "ERROR", err)
+	apierror.Write(w, r, statusCode, message, nil)
+}
+
+// handleServiceError responds with the HTTP status matching the gRPC status returned by the meme
+// service, message is only shown for server errors
+func (s *Server) handleServiceError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	if status.Code(err) == codes.NotFound {
+		s.log.WarnContext(r.Context(), message, "ERROR", err)
+	} else {
+		s.log.ErrorContext(r.Context(), message, "ERROR", err)
+	}
+	apierror.FromStatus(w, r, err, message)
 }
 
-// GET /api/memes
-// Endpoint to get memes for timeline and provide sort order
+// GET /api/memes?page=num&pageSize=num&sort=order&tags=a,b&excludeTags=c&tagMatch=any|all&mediaType=gif
+// Endpoint to get memes for timeline and provide sort order, the responses are cached
 func (s *Server) GetTimeline(w http.ResponseWriter, r *http.Request) {
+	s.timeline(w, r, true)
+}
+
+// GET /api/admin/memes
+// same as GetTimeline without the cache so admins see their changes right away
+func (s *Server) AdminGetTimeline(w http.ResponseWriter, r *http.Request) {
+	s.timeline(w, r, false)
+}
+
+func (s *Server) timeline(w http.ResponseWriter, r *http.Request, cached bool) {
 
 	// parse query parameters
 	queryParams := r.URL.Query()
@@ -120,21 +198,46 @@ func (s *Server) GetTimeline(w http.ResponseWriter, r *http.Request) {
 			sortOrder = pb.SortOrder_MOST_SHARED
 		default:
 			// Invalid sort parameter - return HTTP 400
-			s.log.Warn("Invalid sort parameter provided", "sort", order, "IP", r.RemoteAddr)
-			http.Error(w, "Invalid sort parameter. Valid options: newest, oldest, most_tagged, most_downloaded, most_shared", http.StatusBadRequest)
+			s.log.WarnContext(r.Context(), "Invalid sort parameter provided", "sort", order, "IP", r.RemoteAddr)
+			apierror.Write(w, r, http.StatusBadRequest, "Invalid sort parameter. Valid options: newest, oldest, most_tagged, most_downloaded, most_shared", nil)
 			return
 		}
 	}
+	req := &pb.GetTimelineRequest{
+		Page:        int32(page),
+		PageSize:    int32(pageSize),
+		SortOrder:   sortOrder,
+		Tags:        tagsParam(queryParams, "tags"),
+		ExcludeTags: tagsParam(queryParams, "excludeTags"),
+	}
+	switch strings.ToLower(queryParams.Get("tagMatch")) {
+	case "", "any":
+		req.TagMatch = pb.TagMatch_ANY_TAG
+	case "all":
+		req.TagMatch = pb.TagMatch_ALL_TAGS
+	default:
+		apierror.Write(w, r, http.StatusBadRequest, "Invalid tagMatch parameter. Valid options: any, all", nil)
+		return
+	}
+	if mediaType := queryParams.Get("mediaType"); mediaType != "" {
+		mimeType, err := search.ParseMediaType(mediaType)
+		if err != nil {
+			apierror.Write(w, r, http.StatusBadRequest, "Invalid mediaType parameter. Valid options: gif, png, jpg", nil)
+			return
+		}
+		req.MediaType = mimeType
+	}
 
 	// Log sort parameter usage for monitoring
-	s.log.Debug("Timeline request", "sort", sortOrder.String(), "page", page, "pageSize", pageSize, "IP", r.RemoteAddr)
+	s.log.DebugContext(r.Context(), "Timeline request", "sort", sortOrder.String(), "page", page, "pageSize", pageSize, "tags", req.Tags, "excludeTags", req.ExcludeTags, "mediaType", req.MediaType, "IP", r.RemoteAddr)
 
-	timelineCacheKey := fmt.Sprintf("timeline_%d_%d_%d", page, pageSize, sortOrder) // TODO: fixme different page sizes will create duplicate entries in the cache
-	cachedTimeline, found := s.cache.Get(timelineCacheKey)
-	if strings.HasPrefix(r.Pattern, "/api/memes") { // Don't cache the admin endpoint
+	cacheKey := timelineCacheKey(req)
+	if cached {
 		// check if in cache
+		cachedTimeline, found := s.cache.Get(cacheKey)
+		metrics.CacheLookup("timeline", found)
 		if found {
-			s.log.Debug("Cache hit for timeline")
+			s.log.DebugContext(r.Context(), "Cache hit for timeline")
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusOK)
 			json.NewEncoder(w).Encode(cachedTimeline)
@@ -144,109 +247,113 @@ func (s *Server) GetTimeline(w http.ResponseWriter, r *http.Request) {
 	// get timeline memes
 	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
 	defer cancel()
-	resp, err := s.memeService.GetTimelineMemes(ctx, &pb.GetTimelineRequest{
-		Page:      int32(page),
-		PageSize:  int32(pageSize),
-		SortOrder: sortOrder,
-	})
+	resp, err := s.memeService.GetTimelineMemes(ctx, req)
 	if err != nil {
-		s.handleError(w, err, "Failed to fetch memes", http.StatusInternalServerError)
+		s.handleServiceError(w, r, err, "Failed to fetch memes")
 		return
 	}
+	timeline := toMemePage(resp)
 	// store in cache
-	if !found {
-		s.cache.Set(timelineCacheKey, resp, cache.DefaultExpiration)
+	if cached {
+		s.cache.Set(cacheKey, timeline, cache.DefaultExpiration)
 	}
 	// return all the sampleMemes as JSON
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(resp)
+	json.NewEncoder(w).Encode(timeline)
+
+}
 
+// tagsParam returns the normalized tags of a repeated or comma separated query parameter, without
+// duplicates and sorted so the same filter always has the same cache key
+func tagsParam(queryParams url.Values, name string) []string {
+	var tags []string
+	for _, value := range queryParams[name] {
+		tags = append(tags, strings.Split(value, ",")...)
+	}
+	tags = normalizeTags(tags)
+	slices.Sort(tags)
+	return tags
+}
+
+// timelineCacheKey identifies a timeline page, every filter is part of it
+func timelineCacheKey(req *pb.GetTimelineRequest) string {
+	// TODO: fixme different page sizes will create duplicate entries in the cache
+	return fmt.Sprintf("timeline_%d_%d_%d_%d_%q_%q_%q", req.Page, req.PageSize, req.SortOrder, req.TagMatch, req.Tags, req.ExcludeTags, req.MediaType)
 }
 
 // POST /api/meme
 func (s *Server) UploadMeme(w http.ResponseWriter, r *http.Request) {
+	// the same limit applies to the whole request even if the config is reloaded meanwhile
+	maxUploadSize := s.config.Current().MaxUploadSize
 	// Multipart form data
-	r.ParseMultipartForm(s.config.MaxUploadSize) // limit your max input length to 2MB
+	if !s.parseUploadForm(w, r, maxUploadSize) {
+		return
+	}
 
 	// get the json metadata
 	jsonData := r.FormValue("meme")
-	var meme meme.UploadRequest
+	var meme api.UploadRequest
 	if err := json.Unmarshal([]byte(jsonData), &meme); err != nil {
-		s.log.Debug("Error parsing the json", "JSON", jsonData, "ERROR", err)
-		s.handleError(w, err, "Error parsing the meme data", http.StatusBadRequest)
+		s.log.DebugContext(r.Context(), "Error parsing the json", "JSON", jsonData, "ERROR", err)
+		s.handleError(w, r, err, "Error parsing the meme data", http.StatusBadRequest)
 		return
 	}
-	s.log.Debug("Uploaded meme metadata", "JSON", jsonData)
+	s.log.DebugContext(r.Context(), "Uploaded meme metadata", "JSON", jsonData)
 
 	err := s.structValidator.Struct(meme)
 	if err != nil {
-		s.handleError(w, err, "The meme data is invalid or missing required fields", http.StatusBadRequest)
+		s.handleError(w, r, err, "The meme data is invalid or missing required fields", http.StatusBadRequest)
 		return
 	}
-	s.log.Debug("Parsed Meme", "Meme", meme)
+	s.log.DebugContext(r.Context(), "Parsed Meme", "Meme", meme)
 
 	var imgBuf bytes.Buffer
+	// where a URL upload was downloaded from and the post it was resolved from
+	var source, postURL string
 
 	// if no MediaURL is provided, then it's a file upload
 	if meme.MediaURL == "" {
-		s.log.Info("File upload")
-		file, _, err := r.FormFile("image")
-		if err != nil {
-			s.handleError(w, err, "Couldn't find image in the multipart request", http.StatusBadRequest)
+		s.log.InfoContext(r.Context(), "File upload")
+		data, err := readUploadedImage(r, maxUploadSize)
+		if errors.Is(err, errUploadTooLarge) {
+			s.handleError(w, r, err, fmt.Sprintf("Uploaded file is too big it must be <= %d", maxUploadSize), http.StatusRequestEntityTooLarge)
 			return
 		}
-		defer file.Close()
-		if _, err := imgBuf.ReadFrom(file); err != nil {
-			s.handleError(w, err, "Failed to read the image / invalid image", http.StatusBadRequest)
+		if err != nil {
+			s.handleError(w, r, err, "Couldn't read the image in the multipart request", http.StatusBadRequest)
 			return
 		}
+		imgBuf.Write(data)
 	} else {
 		// if MediaURL is provided, then it's a URL upload
 		// download the image from the URL
-
-		memeURL := meme.MediaURL
-		if !s.ValidateUploadURL(memeURL) {
-			s.log.Error("Invalid meme url or too big", "URL", memeURL, "IP", r.RemoteAddr)
-			http.Error(w, "Invalid media URL or file is too big files should be <2MB", http.StatusBadRequest)
-			return
-		}
-
-		req, err := http.NewRequest("GET", meme.MediaURL, nil)
-		if err != nil {
-			s.handleError(w, err, "Error creating the request to download the image", http.StatusBadRequest)
-			return
-		}
-		req.Header.Set("Accept", "image/*")
-
-		resp, err := s.client.Do(req)
-		if err != nil {
-			s.handleError(w, err, "Error downloading the image from the provided URL", http.StatusBadRequest)
-			return
-		}
-		defer resp.Body.Close()
-		if _, err := imgBuf.ReadFrom(http.MaxBytesReader(w, resp.Body, s.config.MaxUploadSize)); err != nil {
-			s.handleError(w, err, "Error reading the downloaded image", http.StatusInternalServerError)
+		fetched := s.fetchImage(w, r, meme.MediaURL)
+		if fetched == nil {
 			return
 		}
+		imgBuf.Write(fetched.Data)
+		source, postURL = fetched.URL, fetched.PostURL
 	}
 	// get the image dimensions from imgBuf
 	imgBytes := imgBuf.Bytes()
-	if len(imgBytes) > int(s.config.MaxUploadSize) {
-		s.handleError(w, nil, fmt.Sprintf("Uploaded file is too big it must be <= %d", s.config.MaxUploadSize), http.StatusRequestEntityTooLarge)
+	if len(imgBytes) > int(maxUploadSize) {
+		s.handleError(w, r, nil, fmt.Sprintf("Uploaded file is too big it must be <= %d", maxUploadSize), http.StatusRequestEntityTooLarge)
 		return
 	}
 
 	// detect the real MIME type from the bytes, ignoring client and image extension
 	detectedMimeType := http.DetectContentType(imgBytes)
 	if strings.Split(detectedMimeType, "/")[0] != "image" {
-		http.Error(w, "Invalid media type: uploaded file is not an image", http.StatusBadRequest)
+		apierror.Write(w, r, http.StatusBadRequest, "Invalid media type: uploaded file is not an image", nil)
 		return
 	}
 	imgReader := bytes.NewReader(imgBytes)
+	_, span := tracing.Start(r.Context(), "decodeImage", attribute.String("image.media_type", detectedMimeType))
 	imgConfig, _, err := image.DecodeConfig(imgReader)
+	tracing.End(span, err)
 	if err != nil {
-		s.handleError(w, err, "Unsupported image format. Supported formats are: JPEG, PNG, GIF", http.StatusBadRequest)
+		s.handleError(w, r, err, "Unsupported image format. Supported formats are: JPEG, PNG, GIF", http.StatusBadRequest)
 		return
 	}
 	// call the memeService to upload the meme
@@ -256,7 +363,8 @@ func (s *Server) UploadMeme(w http.ResponseWriter, r *http.Request) {
 		Tags:           meme.Tags,
 		Name:           meme.Name,
 		Dimensions:     []int32{int32(imgConfig.Width), int32(imgConfig.Height)},
-		SocialMediaUrl: meme.MediaURL,
+		SocialMediaUrl: source,
+		PostUrl:        postURL,
 	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
@@ -264,49 +372,62 @@ func (s *Server) UploadMeme(w http.ResponseWriter, r *http.Request) {
 	resp, err := s.memeService.UploadMeme(ctx, memeUpload)
 	// TODO: resize it
 	if err != nil {
-		s.handleError(w, err, "Error uploading the meme", http.StatusInternalServerError)
+		s.handleServiceError(w, r, err, "Error uploading the meme")
 		return
 	}
 
 	// return the meme ID
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(resp)
+	json.NewEncoder(w).Encode(toMeme(resp))
 
 }
 
-// api/memes/search?query=query&page=num&pageSize=num
+// api/memes/search?query=query&page=num&pageSize=num&sort=relevance|newest|most_downloaded
+// the query syntax with tag:, source:, type: and date filters is described in the search package
 func (s *Server) SearchMemes(w http.ResponseWriter, r *http.Request) {
 	// parse query parameters
 	queryParams := r.URL.Query()
-	// tags := queryParams["tags"]
-	query := queryParams["query"]
+	query := queryParams.Get("query")
+	var sort pb.SearchSort
+	switch strings.ToLower(queryParams.Get("sort")) {
+	case "", "relevance":
+		sort = pb.SearchSort_SEARCH_RELEVANCE
+	case "newest":
+		sort = pb.SearchSort_SEARCH_NEWEST
+	case "most_downloaded":
+		sort = pb.SearchSort_SEARCH_MOST_DOWNLOADED
+	default:
+		apierror.Write(w, r, http.StatusBadRequest, "Invalid sort parameter. Valid options: relevance, newest, most_downloaded", nil)
+		return
+	}
 	page, err := strconv.Atoi(queryParams.Get("page"))
 	if err != nil {
-		s.log.Debug("Failed to parse page query param")
+		s.log.DebugContext(r.Context(), "Failed to parse page query param")
 		page = 1
 	}
 	pageSize, err := strconv.Atoi(queryParams.Get("pageSize"))
 	if err != nil {
-		s.log.Debug("Failed to parse pageSize query param")
+		s.log.DebugContext(r.Context(), "Failed to parse pageSize query param")
 		pageSize = 10
 	}
-	s.log.Debug("Search Query", "Query", query, "page", page, "pageSize", pageSize)
+	s.log.DebugContext(r.Context(), "Search Query", "Query", query, "page", page, "pageSize", pageSize, "sort", sort.String())
 	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
 	defer cancel()
 	resp, err := s.memeService.SearchMemes(ctx, &pb.SearchMemesRequest{
-		Query:    query[0],
+		Query:    query,
 		Page:     int32(page),
 		PageSize: int32(pageSize),
+		Sort:     sort,
 	})
 	if err != nil {
-		s.handleError(w, err, "Failed to fetch memes", http.StatusInternalServerError)
+		s.handleServiceError(w, r, err, "Failed to fetch memes")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(resp)
+	json.NewEncoder(w).Encode(toMemePage(resp))
 
 }
 
@@ -318,7 +439,7 @@ func (s *Server) SearchTags(w http.ResponseWriter, r *http.Request) {
 	query := queryParams.Get("query")
 	limit := queryParams.Get("limit")
 	if len(query) < 3 {
-		http.Error(w, "Invalid query parameters", http.StatusBadRequest)
+		apierror.Write(w, r, http.StatusBadRequest, "Invalid query parameters", nil)
 		return
 	}
 	if len(limit) == 0 {
@@ -326,20 +447,22 @@ func (s *Server) SearchTags(w http.ResponseWriter, r *http.Request) {
 	}
 	limitVal, err := strconv.Atoi(limit)
 	if err != nil {
-		s.handleError(w, err, "Invalid limit", http.StatusBadRequest)
+		s.handleError(w, r, err, "Invalid limit", http.StatusBadRequest)
 		return
 	}
 
 	searchTagsCacheKey := fmt.Sprintf("tags_%s_%d", query, limitVal)
 	// check if in cache
-	if cachedTags, found := s.cache.Get(searchTagsCacheKey); found {
-		s.log.Debug("Cache hit for tags", "Query", query, "Limit", limit)
+	cachedTags, found := s.cache.Get(searchTagsCacheKey)
+	metrics.CacheLookup("tags", found)
+	if found {
+		s.log.DebugContext(r.Context(), "Cache hit for tags", "Query", query, "Limit", limit)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(cachedTags)
 		return
 	}
-	s.log.Debug("Search Tags", "Query", query, "Limit", limit)
+	s.log.DebugContext(r.Context(), "Search Tags", "Query", query, "Limit", limit)
 	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
 	defer cancel()
 	resp, err := s.memeService.SearchTags(ctx, &pb.SearchTagsRequest{
@@ -347,15 +470,16 @@ func (s *Server) SearchTags(w http.ResponseWriter, r *http.Request) {
 		Limit: int32(limitVal),
 	})
 	if err != nil {
-		s.handleError(w, err, "Failed to fetch tags", http.StatusInternalServerError)
+		s.handleServiceError(w, r, err, "Failed to fetch tags")
 		return
 	}
+	tags := toTagList(resp)
 	// store in cache
-	s.cache.Set(searchTagsCacheKey, resp, cache.DefaultExpiration)
+	s.cache.Set(searchTagsCacheKey, tags, cache.DefaultExpiration)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(resp)
+	json.NewEncoder(w).Encode(tags)
 }
 
 // DELETE /api/meme/{id}
@@ -364,18 +488,18 @@ func (s *Server) DeleteMeme(w http.ResponseWriter, r *http.Request) {
 	// parse query parameters
 	idString := r.PathValue("id")
 	if err := uuid.Validate(idString); err != nil {
-		s.handleError(w, err, "Bad ID", http.StatusBadRequest)
+		s.handleError(w, r, err, "Bad ID", http.StatusBadRequest)
 		return
 	}
 
-	s.log.Info("Delete Meme", "ID", idString)
+	s.log.InfoContext(r.Context(), "Delete Meme", "ID", idString)
 	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
 	defer cancel()
 	_, err := s.memeService.DeleteMeme(ctx, &pb.DeleteMemeRequest{
 		Id: idString,
 	})
 	if err != nil {
-		s.handleError(w, err, "Failed to delete meme", http.StatusInternalServerError)
+		s.handleServiceError(w, r, err, "Failed to delete meme")
 		return
 	}
 	w.WriteHeader(http.StatusOK)
@@ -387,34 +511,37 @@ func (s *Server) GetMeme(w http.ResponseWriter, r *http.Request) {
 	// parse query parameters
 	idString := r.PathValue("id")
 	if err := uuid.Validate(idString); err != nil {
-		s.handleError(w, err, "Bad ID", http.StatusBadRequest)
+		s.handleError(w, r, err, "Bad ID", http.StatusBadRequest)
 		return
 	}
 	memeCacheKey := fmt.Sprintf("meme_%s", idString)
-	if cachedMeme, found := s.cache.Get(memeCacheKey); found {
-		s.log.Debug("Cache hit for meme", "ID", idString)
+	cachedMeme, found := s.cache.Get(memeCacheKey)
+	metrics.CacheLookup("meme", found)
+	if found {
+		s.log.DebugContext(r.Context(), "Cache hit for meme", "ID", idString)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(cachedMeme)
 		return
 	}
 
-	s.log.Info("Get Meme", "ID", idString)
+	s.log.InfoContext(r.Context(), "Get Meme", "ID", idString)
 	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
 	defer cancel()
 	resp, err := s.memeService.GetMeme(ctx, &pb.GetMemeRequest{
 		Id: idString,
 	})
 	if err != nil {
-		s.handleError(w, err, "Failed to fetch meme", http.StatusInternalServerError)
+		s.handleServiceError(w, r, err, "Failed to fetch meme")
 		return
 	}
+	meme := toMeme(resp)
 	// store in cache
-	s.cache.Set(memeCacheKey, resp, cache.DefaultExpiration)
+	s.cache.Set(memeCacheKey, meme, cache.DefaultExpiration)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(resp)
+	json.NewEncoder(w).Encode(meme)
 }
 
 // /api/admin/meme/{id}/tags
@@ -422,34 +549,30 @@ func (s *Server) UpdateTags(w http.ResponseWriter, r *http.Request) {
 
 	id := r.PathValue("id")
 	if err := uuid.Validate(id); err != nil {
-		s.handleError(w, err, "Bad ID", http.StatusBadRequest)
+		s.handleError(w, r, err, "Bad ID", http.StatusBadRequest)
 		return
 	}
 	dec := json.NewDecoder(r.Body)
-	var tagsRequest = meme.AddTagsRequest{}
+	var tagsRequest = api.AddTagsRequest{}
 	err := dec.Decode(&tagsRequest)
 	if err != nil {
-		s.handleError(w, err, "Error parsing the json", http.StatusBadRequest)
+		s.handleError(w, r, err, "Error parsing the json", http.StatusBadRequest)
 		return
 	}
 	err = s.structValidator.Struct(tagsRequest)
 	if err != nil {
-		s.handleError(w, err, "The meme data is invalid or missing required fields", http.StatusBadRequest)
+		s.handleError(w, r, err, "The meme data is invalid or missing required fields", http.StatusBadRequest)
 		return
 	}
 	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
 	defer cancel()
-	s.log.Info("Adding tags to meme", "ID", id, "Tags", tagsRequest.Tags)
-	resp, err := s.memeService.AddTags(ctx, &pb.AddTagsRequest{
+	s.log.InfoContext(r.Context(), "Adding tags to meme", "ID", id, "Tags", tagsRequest.Tags)
+	_, err = s.memeService.AddTags(ctx, &pb.AddTagsRequest{
 		MemeId: id,
 		Tags:   tagsRequest.Tags,
 	})
 	if err != nil {
-		s.handleError(w, err, "Failed to add tags", http.StatusInternalServerError)
-		return
-	}
-	if resp.Success != int32(200) {
-		w.WriteHeader(int(resp.Success))
+		s.handleServiceError(w, r, err, "Failed to add tags")
 		return
 	}
 
@@ -460,19 +583,22 @@ func (s *Server) UpdateTags(w http.ResponseWriter, r *http.Request) {
 // PATCH /api/admin/meme/{id}
 func (s *Server) PatchMeme(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
+	maxUploadSize := s.config.Current().MaxUploadSize
 	// Multipart form data
-	r.ParseMultipartForm(s.config.MaxUploadSize) // limit your max input length to 2MB
+	if !s.parseUploadForm(w, r, maxUploadSize) {
+		return
+	}
 
 	// get the json metadata
 	jsonData := r.FormValue("meme")
-	var meme meme.PatchRequest
+	var meme api.PatchRequest
 	if err := json.Unmarshal([]byte(jsonData), &meme); err != nil {
-		s.handleError(w, err, "Error parsing the json", http.StatusBadRequest)
+		s.handleError(w, r, err, "Error parsing the json", http.StatusBadRequest)
 		return
 	}
 	err := s.structValidator.Struct(meme)
 	if err != nil {
-		s.handleError(w, err, "The meme data is invalid or missing required fields", http.StatusBadRequest)
+		s.handleError(w, r, err, "The meme data is invalid or missing required fields", http.StatusBadRequest)
 		return
 	}
 
@@ -480,19 +606,19 @@ func (s *Server) PatchMeme(w http.ResponseWriter, r *http.Request) {
 	for _, tag := range meme.Tags {
 		trimmed := strings.TrimSpace(tag)
 		if trimmed == "" {
-			http.Error(w, "Tag name cannot be empty", http.StatusBadRequest)
+			apierror.Write(w, r, http.StatusBadRequest, "Tag name cannot be empty", nil)
 			return
 		}
 		if len(trimmed) > 100 {
-			http.Error(w, "Tag name exceeds 100 characters", http.StatusBadRequest)
+			apierror.Write(w, r, http.StatusBadRequest, "Tag name exceeds 100 characters", nil)
 			return
 		}
 	}
 
 	// verify if mime type is for an image
 	if strings.Split(meme.MimeType, "/")[0] != "image" {
-		s.log.Debug("Invalid media type", "MimeType", meme.MimeType)
-		http.Error(w, "Invalid media type", http.StatusBadRequest)
+		s.log.DebugContext(r.Context(), "Invalid media type", "MimeType", meme.MimeType)
+		apierror.Write(w, r, http.StatusBadRequest, "Invalid media type", nil)
 		return
 	}
 
@@ -501,55 +627,44 @@ func (s *Server) PatchMeme(w http.ResponseWriter, r *http.Request) {
 	if meme.MediaURL != "" || fileExists {
 		// Update image
 		var imgBuf bytes.Buffer
+		var source, postURL string
 
 		// if no MediaURL is provided, then it's a file upload
 		if meme.MediaURL == "" {
-			s.log.Info("File upload")
-			file, _, err := r.FormFile("image")
-			if err != nil {
-				s.handleError(w, err, "Couldn't find image in the multipart request", http.StatusBadRequest)
+			s.log.InfoContext(r.Context(), "File upload")
+			data, err := readUploadedImage(r, maxUploadSize)
+			if errors.Is(err, errUploadTooLarge) {
+				s.log.ErrorContext(r.Context(), "Uploaded file is too big", "ERROR", err)
+				apierror.Write(w, r, http.StatusRequestEntityTooLarge, "Uploaded image is too big", nil)
 				return
 			}
-			defer file.Close()
-			if _, err := imgBuf.ReadFrom(file); err != nil {
-				s.log.Error("Error reading the image into butter", "ERROR", err)
-				http.Error(w, "Error reading the image", http.StatusBadRequest)
+			if err != nil {
+				s.log.ErrorContext(r.Context(), "Error reading the image", "ERROR", err)
+				apierror.Write(w, r, http.StatusBadRequest, "Error reading the image", nil)
 				return
 			}
+			imgBuf.Write(data)
 		} else {
 			// if MediaURL is provided, then it's a URL upload
 			// download the image from the URL
-
-			memeURL := meme.MediaURL
-			if !s.ValidateUploadURL(memeURL) {
-				s.log.Error("Invalid meme url or too big", "URL", memeURL, "IP", r.RemoteAddr)
-				http.Error(w, "Invalid media URL or file is too big files should be <2MB", http.StatusBadRequest)
-				return
-			}
-
-			resp, err := http.Get(meme.MediaURL)
-			if err != nil {
-				s.handleError(w, err, "Error downloading the image from the provided URL", http.StatusBadRequest)
-				return
-			}
-			defer resp.Body.Close()
-			if _, err := imgBuf.ReadFrom(http.MaxBytesReader(w, resp.Body, s.config.MaxUploadSize)); err != nil {
-				s.log.Error("Error reading the image", "Error", err)
-				http.Error(w, "Error reading the image", http.StatusBadRequest)
+			fetched := s.fetchImage(w, r, meme.MediaURL)
+			if fetched == nil {
 				return
 			}
+			imgBuf.Write(fetched.Data)
+			source, postURL = fetched.URL, fetched.PostURL
 		}
 		// // get the image dimensions from imgBuf
 		imgBytes := imgBuf.Bytes()
-		if len(imgBytes) > int(s.config.MaxUploadSize) {
-			s.log.Error("Uploaded file is too big", "Size", len(imgBytes))
-			http.Error(w, "Uploaded image is too big", http.StatusRequestEntityTooLarge)
+		if len(imgBytes) > int(maxUploadSize) {
+			s.log.ErrorContext(r.Context(), "Uploaded file is too big", "Size", len(imgBytes))
+			apierror.Write(w, r, http.StatusRequestEntityTooLarge, "Uploaded image is too big", nil)
 			return
 		}
 		imgReader := bytes.NewReader(imgBytes)
 		imgConfig, _, err := image.DecodeConfig(imgReader)
 		if err != nil {
-			s.handleError(w, err, "Failed to decode image config Likely not an image", http.StatusBadRequest)
+			s.handleError(w, r, err, "Failed to decode image config Likely not an image", http.StatusBadRequest)
 			return
 		}
 
@@ -561,7 +676,8 @@ func (s *Server) PatchMeme(w http.ResponseWriter, r *http.Request) {
 			Tags:           meme.Tags,
 			Name:           meme.Name,
 			Dimensions:     []int32{int32(imgConfig.Width), int32(imgConfig.Height)},
-			SocialMediaUrl: meme.MediaURL,
+			SocialMediaUrl: source,
+			PostUrl:        postURL,
 		}
 	} else {
 		updateRequest = &pb.UpdateMemeRequest{
@@ -575,10 +691,10 @@ func (s *Server) PatchMeme(w http.ResponseWriter, r *http.Request) {
 	defer cancel()
 	resp, err := s.memeService.UpdateMeme(ctx, updateRequest)
 	if err != nil {
-		s.handleError(w, err, "Failed to update meme", http.StatusInternalServerError)
+		s.handleServiceError(w, r, err, "Failed to update meme")
 		return
 	}
-	s.log.Debug(resp.String())
+	s.log.DebugContext(r.Context(), resp.String())
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -603,37 +719,33 @@ func (s *Server) GetPendingMemes(w http.ResponseWriter, r *http.Request) {
 		PageSize: int32(pageSize),
 	})
 	if err != nil {
-		s.handleError(w, err, "Failed to fetch pending memes", http.StatusInternalServerError)
+		s.handleServiceError(w, r, err, "Failed to fetch pending memes")
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp)
+	json.NewEncoder(w).Encode(toMemePage(resp))
 }
 
 // PATCH /api/admin/meme/{id}/approve
 func (s *Server) ApproveMeme(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 	if err := uuid.Validate(id); err != nil {
-		s.handleError(w, err, "Bad ID", http.StatusBadRequest)
+		s.handleError(w, r, err, "Bad ID", http.StatusBadRequest)
 		return
 	}
 	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
 	defer cancel()
-	resp, err := s.memeService.ApproveMeme(ctx, &pb.ApproveMemeRequest{MemeId: id})
+	_, err := s.memeService.ApproveMeme(ctx, &pb.ApproveMemeRequest{MemeId: id})
 	if err != nil {
-		s.handleError(w, err, "Failed to approve meme", http.StatusInternalServerError)
-		return
-	}
-	if !resp.Success {
-		http.Error(w, resp.Error, http.StatusBadRequest)
+		s.handleServiceError(w, r, err, "Failed to approve meme")
 		return
 	}
-	s.log.Info("Meme approved", "ID", id)
+	s.log.InfoContext(r.Context(), "Meme approved", "ID", id)
 	w.WriteHeader(http.StatusOK)
 }
 
 func (s *Server) FlushCache(w http.ResponseWriter, r *http.Request) {
-	s.log.Info("Clearing cache")
+	s.log.InfoContext(r.Context(), "Clearing cache")
 	s.cache.Flush()
 	w.WriteHeader(http.StatusOK)
 }
@@ -643,36 +755,23 @@ func (s *Server) TrackDownload(w http.ResponseWriter, r *http.Request) {
 	// Parse and validate meme ID
 	idString := r.PathValue("id")
 	if err := uuid.Validate(idString); err != nil {
-		s.handleError(w, err, "Invalid meme ID format", http.StatusBadRequest)
+		s.handleError(w, r, err, "Invalid meme ID format", http.StatusBadRequest)
 		return
 	}
 
-	s.log.Info("Track Download", "ID", idString)
+	s.log.InfoContext(r.Context(), "Track Download", "ID", idString)
 
 	// Call MemeService.IncrementDownload via gRPC
 	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
 	defer cancel()
-	resp, err := s.memeService.IncrementDownload(ctx, &pb.IncrementEngagementRequest{
+	_, err := s.memeService.IncrementDownload(ctx, &pb.IncrementEngagementRequest{
 		MemeId: idString,
 	})
-
 	if err != nil {
-		// Check if it's a not found error
-		if strings.Contains(err.Error(), "not found") {
-			s.handleError(w, err, "Meme not found", http.StatusNotFound)
-			return
-		}
-		s.handleError(w, err, "Failed to track download", http.StatusInternalServerError)
+		s.handleServiceError(w, r, err, "Failed to track download")
 		return
 	}
 
-	if !resp.Success {
-		if resp.Error != "" {
-			s.handleError(w, fmt.Errorf("%s", resp.Error), "Failed to track download", http.StatusInternalServerError)
-			return
-		}
-	}
-
 	// Return success
 	w.WriteHeader(http.StatusOK)
 }
@@ -682,36 +781,23 @@ func (s *Server) TrackShare(w http.ResponseWriter, r *http.Request) {
 	// Parse and validate meme ID
 	idString := r.PathValue("id")
 	if err := uuid.Validate(idString); err != nil {
-		s.handleError(w, err, "Invalid meme ID format", http.StatusBadRequest)
+		s.handleError(w, r, err, "Invalid meme ID format", http.StatusBadRequest)
 		return
 	}
 
-	s.log.Info("Track Share", "ID", idString)
+	s.log.InfoContext(r.Context(), "Track Share", "ID", idString)
 
 	// Call MemeService.IncrementShare via gRPC
 	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
 	defer cancel()
-	resp, err := s.memeService.IncrementShare(ctx, &pb.IncrementEngagementRequest{
+	_, err := s.memeService.IncrementShare(ctx, &pb.IncrementEngagementRequest{
 		MemeId: idString,
 	})
-
 	if err != nil {
-		// Check if it's a not found error
-		if strings.Contains(err.Error(), "not found") {
-			s.handleError(w, err, "Meme not found", http.StatusNotFound)
-			return
-		}
-		s.handleError(w, err, "Failed to track share", http.StatusInternalServerError)
+		s.handleServiceError(w, r, err, "Failed to track share")
 		return
 	}
 
-	if !resp.Success {
-		if resp.Error != "" {
-			s.handleError(w, fmt.Errorf("%s", resp.Error), "Failed to track share", http.StatusInternalServerError)
-			return
-		}
-	}
-
 	// Return success
 	w.WriteHeader(http.StatusOK)
 }
@@ -12,8 +12,15 @@ import (
 	"testing"
 	"time"
 
-	pb "github.com/BassemHalim/memesHub/internal/proto/memeService"
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/patrickmn/go-cache"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/BassemHalim/memesHub/internal/apierror"
+	"github.com/BassemHalim/memesHub/internal/logging"
+	pb "github.com/BassemHalim/memesHub/internal/proto/memeService"
 )
 
 func GetDebugLogger() *slog.Logger {
@@ -24,7 +31,7 @@ var MemCache *cache.Cache = cache.New(2*time.Minute, 2*time.Minute)
 
 func TestBadGetMeme(t *testing.T) {
 
-	server, err := newWithMemeService(nil, nil, nil, GetDebugLogger(), nil, MemCache)
+	server, err := NewWithMemeService(nil, nil, nil, GetDebugLogger(), nil, MemCache)
 	if err != nil {
 		t.Fatal("Failed to create server")
 	}
@@ -54,12 +61,19 @@ type MockMemeService struct {
 
 	SearchMemesFunc       func(ctx context.Context, in *pb.SearchMemesRequest) (*pb.MemesResponse, error)
 	SearchTagsFunc        func(ctx context.Context, in *pb.SearchTagsRequest) (*pb.TagsResponse, error)
+	AutocompleteFunc      func(ctx context.Context, in *pb.AutocompleteRequest) (*pb.AutocompleteResponse, error)
 	AddTagsFunc           func(ctx context.Context, in *pb.AddTagsRequest) (*pb.AddTagsResponse, error)
 	UpdateMemeFunc        func(ctx context.Context, in *pb.UpdateMemeRequest) (*pb.UpdateMemeResponse, error)
 	IncrementDownloadFunc func(ctx context.Context, in *pb.IncrementEngagementRequest) (*pb.IncrementEngagementResponse, error)
 	IncrementShareFunc    func(ctx context.Context, in *pb.IncrementEngagementRequest) (*pb.IncrementEngagementResponse, error)
 	GetPendingMemesFunc   func(ctx context.Context, in *pb.GetPendingMemesRequest) (*pb.MemesResponse, error)
 	ApproveMemeFunc       func(ctx context.Context, in *pb.ApproveMemeRequest) (*pb.ApproveMemeResponse, error)
+	GetDeletedMemesFunc   func(ctx context.Context, in *pb.GetDeletedMemesRequest) (*pb.MemesResponse, error)
+	RestoreMemeFunc       func(ctx context.Context, in *pb.RestoreMemeRequest) (*pb.RestoreMemeResponse, error)
+	PurgeDeletedMemesFunc func(ctx context.Context, in *pb.PurgeDeletedMemesRequest) (*pb.PurgeDeletedMemesResponse, error)
+	CreateUploadSlotFunc  func(ctx context.Context, in *pb.CreateUploadSlotRequest) (*pb.UploadSlotResponse, error)
+	FinalizeUploadFunc    func(ctx context.Context, in *pb.FinalizeUploadRequest) (*pb.MemeResponse, error)
+	UpdateMemeSourceFunc  func(ctx context.Context, in *pb.UpdateMemeSourceRequest) (*pb.UpdateMemeSourceResponse, error)
 }
 
 func (c *MockMemeService) GetMeme(ctx context.Context, in *pb.GetMemeRequest) (*pb.MemeResponse, error) {
@@ -96,6 +110,10 @@ func (m *MockMemeService) SearchMemes(ctx context.Context, in *pb.SearchMemesReq
 func (m *MockMemeService) SearchTags(ctx context.Context, in *pb.SearchTagsRequest) (*pb.TagsResponse, error) {
 	return m.SearchTagsFunc(ctx, in)
 }
+
+func (m *MockMemeService) Autocomplete(ctx context.Context, in *pb.AutocompleteRequest) (*pb.AutocompleteResponse, error) {
+	return m.AutocompleteFunc(ctx, in)
+}
 func (m *MockMemeService) AddTags(ctx context.Context, in *pb.AddTagsRequest) (*pb.AddTagsResponse, error) {
 	return m.AddTagsFunc(ctx, in)
 }
@@ -131,10 +149,53 @@ func (m *MockMemeService) ApproveMeme(ctx context.Context, in *pb.ApproveMemeReq
 	}
 	return &pb.ApproveMemeResponse{Success: true}, nil
 }
+
+func (m *MockMemeService) GetDeletedMemes(ctx context.Context, in *pb.GetDeletedMemesRequest) (*pb.MemesResponse, error) {
+	if m.GetDeletedMemesFunc != nil {
+		return m.GetDeletedMemesFunc(ctx, in)
+	}
+	return &pb.MemesResponse{}, nil
+}
+
+func (m *MockMemeService) RestoreMeme(ctx context.Context, in *pb.RestoreMemeRequest) (*pb.RestoreMemeResponse, error) {
+	if m.RestoreMemeFunc != nil {
+		return m.RestoreMemeFunc(ctx, in)
+	}
+	return &pb.RestoreMemeResponse{Success: true}, nil
+}
+
+func (m *MockMemeService) PurgeDeletedMemes(ctx context.Context, in *pb.PurgeDeletedMemesRequest) (*pb.PurgeDeletedMemesResponse, error) {
+	if m.PurgeDeletedMemesFunc != nil {
+		return m.PurgeDeletedMemesFunc(ctx, in)
+	}
+	return &pb.PurgeDeletedMemesResponse{}, nil
+}
+
+func (m *MockMemeService) CreateUploadSlot(ctx context.Context, in *pb.CreateUploadSlotRequest) (*pb.UploadSlotResponse, error) {
+	if m.CreateUploadSlotFunc != nil {
+		return m.CreateUploadSlotFunc(ctx, in)
+	}
+	return &pb.UploadSlotResponse{}, nil
+}
+
+func (m *MockMemeService) FinalizeUpload(ctx context.Context, in *pb.FinalizeUploadRequest) (*pb.MemeResponse, error) {
+	if m.FinalizeUploadFunc != nil {
+		return m.FinalizeUploadFunc(ctx, in)
+	}
+	return &pb.MemeResponse{}, nil
+}
+
+func (m *MockMemeService) UpdateMemeSource(ctx context.Context, in *pb.UpdateMemeSourceRequest) (*pb.UpdateMemeSourceResponse, error) {
+	if m.UpdateMemeSourceFunc != nil {
+		return m.UpdateMemeSourceFunc(ctx, in)
+	}
+	return &pb.UpdateMemeSourceResponse{Source: in.Source}, nil
+}
+
 func TestGetMeme(t *testing.T) {
 	client := MockMemeService{}
 
-	server, err := newWithMemeService(&client, nil, nil, GetDebugLogger(), &http.Client{}, MemCache)
+	server, err := NewWithMemeService(&client, nil, nil, GetDebugLogger(), nil, MemCache)
 	if err != nil {
 		t.Fatal("Failed to create server")
 	}
@@ -178,6 +239,41 @@ func TestGetMeme(t *testing.T) {
 	}
 }
 
+func TestGetMemeNotFound(t *testing.T) {
+	client := MockMemeService{
+		GetMemeFunc: func(ctx context.Context, in *pb.GetMemeRequest) (*pb.MemeResponse, error) {
+			return nil, status.Error(codes.NotFound, "Meme not found")
+		},
+	}
+	server, err := NewWithMemeService(&client, nil, nil, GetDebugLogger(), nil, MemCache)
+	if err != nil {
+		t.Fatal("Failed to create server")
+	}
+
+	id := "0d6f8f4e-3c1b-4f0e-9a59-2d5b8c1e7a10"
+	request := httptest.NewRequest(http.MethodGet, "/api/meme/"+id, nil)
+	request = request.WithContext(logging.WithRequestID(request.Context(), "req-123"))
+	request.SetPathValue("id", id)
+	w := httptest.NewRecorder()
+	server.GetMeme(w, request)
+
+	res := w.Result()
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected status 404, got %d", res.StatusCode)
+	}
+	if ct := res.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected a JSON error, got Content-Type %q", ct)
+	}
+	var body apierror.Response
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode the error: %v", err)
+	}
+	want := apierror.Response{Code: "NOT_FOUND", Message: "Meme not found", RequestID: "req-123"}
+	if body.Code != want.Code || body.Message != want.Message || body.RequestID != want.RequestID {
+		t.Errorf("Expected %+v, got %+v", want, body)
+	}
+}
+
 func TestTrackDownload(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -203,7 +299,7 @@ func TestTrackDownload(t *testing.T) {
 			name:   "Meme not found",
 			memeID: "7218d21c-ac37-4ebe-b436-c51486d23b95",
 			mockFunc: func(ctx context.Context, in *pb.IncrementEngagementRequest) (*pb.IncrementEngagementResponse, error) {
-				return nil, fmt.Errorf("meme not found")
+				return nil, status.Error(codes.NotFound, "Meme not found")
 			},
 			expectedStatus: http.StatusNotFound,
 		},
@@ -223,7 +319,7 @@ func TestTrackDownload(t *testing.T) {
 				IncrementDownloadFunc: tt.mockFunc,
 			}
 
-			server, err := newWithMemeService(client, nil, nil, GetDebugLogger(), &http.Client{}, MemCache)
+			server, err := NewWithMemeService(client, nil, nil, GetDebugLogger(), nil, MemCache)
 			if err != nil {
 				t.Fatal("Failed to create server")
 			}
@@ -268,7 +364,7 @@ func TestTrackShare(t *testing.T) {
 			name:   "Meme not found",
 			memeID: "7218d21c-ac37-4ebe-b436-c51486d23b95",
 			mockFunc: func(ctx context.Context, in *pb.IncrementEngagementRequest) (*pb.IncrementEngagementResponse, error) {
-				return nil, fmt.Errorf("meme not found")
+				return nil, status.Error(codes.NotFound, "Meme not found")
 			},
 			expectedStatus: http.StatusNotFound,
 		},
@@ -288,7 +384,7 @@ func TestTrackShare(t *testing.T) {
 				IncrementShareFunc: tt.mockFunc,
 			}
 
-			server, err := newWithMemeService(client, nil, nil, GetDebugLogger(), &http.Client{}, MemCache)
+			server, err := NewWithMemeService(client, nil, nil, GetDebugLogger(), nil, MemCache)
 			if err != nil {
 				t.Fatal("Failed to create server")
 			}
@@ -341,7 +437,7 @@ func TestGetTimelineIncludesEngagementFields(t *testing.T) {
 		},
 	}
 
-	server, err := newWithMemeService(client, nil, nil, GetDebugLogger(), &http.Client{}, MemCache)
+	server, err := NewWithMemeService(client, nil, nil, GetDebugLogger(), nil, MemCache)
 	if err != nil {
 		t.Fatal("Failed to create server")
 	}
@@ -410,6 +506,112 @@ func TestGetTimelineIncludesEngagementFields(t *testing.T) {
 	}
 }
 
+func TestGetTimelineFilters(t *testing.T) {
+	var requests []*pb.GetTimelineRequest
+	client := &MockMemeService{
+		GetTimelineMemesFunc: func(ctx context.Context, in *pb.GetTimelineRequest) (*pb.MemesResponse, error) {
+			requests = append(requests, in)
+			return &pb.MemesResponse{Memes: []*pb.MemeResponse{{Id: testMemeID}}, TotalCount: 1, Page: 1, TotalPages: 1}, nil
+		},
+	}
+	server, err := NewWithMemeService(client, nil, nil, GetDebugLogger(), nil, cache.New(time.Minute, time.Minute))
+	if err != nil {
+		t.Fatal("Failed to create server")
+	}
+	get := func(handler http.HandlerFunc, target string) int {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w.Code
+	}
+
+	if code := get(server.GetTimeline, "/api/memes?sort=newest&tags=Football,ucl&tags=football&excludeTags=nsfw&tagMatch=all&mediaType=GIF"); code != http.StatusOK {
+		t.Fatal("Request should succeed", code)
+	}
+	want := &pb.GetTimelineRequest{
+		Page:        1,
+		PageSize:    10,
+		SortOrder:   pb.SortOrder_NEWEST,
+		Tags:        []string{"football", "ucl"},
+		ExcludeTags: []string{"nsfw"},
+		TagMatch:    pb.TagMatch_ALL_TAGS,
+		MediaType:   "image/gif",
+	}
+	if len(requests) != 1 || !proto.Equal(requests[0], want) {
+		t.Fatalf("Expected %v, got %v", want, requests)
+	}
+
+	// the same filter written differently is served from the cache
+	get(server.GetTimeline, "/api/memes?sort=newest&tags=ucl&tags=FOOTBALL&excludeTags=nsfw&tagMatch=ALL&mediaType=image/gif")
+	if len(requests) != 1 {
+		t.Error("The same filter should be served from the cache")
+	}
+	// every filter is part of the cache key
+	for _, target := range []string{
+		"/api/memes?sort=newest&tags=football,ucl&excludeTags=nsfw&mediaType=gif",
+		"/api/memes?sort=newest&tags=football,ucl&tagMatch=all&mediaType=gif",
+		"/api/memes?sort=newest&tags=football&excludeTags=nsfw&tagMatch=all&mediaType=gif",
+		"/api/memes?sort=newest&tags=football,ucl&excludeTags=nsfw&tagMatch=all&mediaType=png",
+	} {
+		before := len(requests)
+		get(server.GetTimeline, target)
+		if len(requests) != before+1 {
+			t.Errorf("%s should not be served from the cache of another filter", target)
+		}
+	}
+	// the admin timeline is never cached
+	before := len(requests)
+	get(server.AdminGetTimeline, "/api/admin/memes?tags=cats")
+	get(server.AdminGetTimeline, "/api/admin/memes?tags=cats")
+	if len(requests) != before+2 {
+		t.Error("The admin timeline should not be cached")
+	}
+
+	for _, target := range []string{"/api/memes?tagMatch=some", "/api/memes?mediaType=video"} {
+		if code := get(server.GetTimeline, target); code != http.StatusBadRequest {
+			t.Errorf("%s should be a bad request, got %d", target, code)
+		}
+	}
+}
+
+// A filter no meme matches is an empty page, not an error
+func TestGetTimelineEmptyFilter(t *testing.T) {
+	service, mock := newTestMemeService(t, &failingStorage{})
+	server, err := NewWithMemeService(service, nil, nil, GetDebugLogger(), nil, cache.New(time.Minute, time.Minute))
+	if err != nil {
+		t.Fatal("Failed to create server")
+	}
+	columns := []string{"id", "media_url", "media_type", "name", "dimensions", "download_count", "share_count"}
+	for _, page := range []int64{1, 2} {
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM meme m`).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery(`ORDER BY m.created_at DESC LIMIT \$2 OFFSET \$3`).
+			WithArgs(sqlmock.AnyArg(), int64(10), (page-1)*10).
+			WillReturnRows(sqlmock.NewRows(columns))
+	}
+
+	w := httptest.NewRecorder()
+	server.AdminGetTimeline(w, httptest.NewRequest(http.MethodGet, "/api/admin/memes?sort=newest&tags=nothing", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var page map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+		t.Fatal("Failed to decode response", err)
+	}
+	if memes, ok := page["memes"].([]interface{}); !ok || len(memes) != 0 || page["total_count"] != float64(0) {
+		t.Errorf("Expected an empty page, got %v", page)
+	}
+
+	w = httptest.NewRecorder()
+	server.AdminGetTimeline(w, httptest.NewRequest(http.MethodGet, "/api/admin/memes?sort=newest&tags=nothing&page=2", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("A page past the end should be a bad request, got %d", w.Code)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestSearchMemesIncludesEngagementFields(t *testing.T) {
 	client := &MockMemeService{
 		SearchMemesFunc: func(ctx context.Context, in *pb.SearchMemesRequest) (*pb.MemesResponse, error) {
@@ -433,7 +635,7 @@ func TestSearchMemesIncludesEngagementFields(t *testing.T) {
 		},
 	}
 
-	server, err := newWithMemeService(client, nil, nil, GetDebugLogger(), &http.Client{}, MemCache)
+	server, err := NewWithMemeService(client, nil, nil, GetDebugLogger(), nil, MemCache)
 	if err != nil {
 		t.Fatal("Failed to create server")
 	}
@@ -481,3 +683,40 @@ func TestSearchMemesIncludesEngagementFields(t *testing.T) {
 		}
 	}
 }
+
+func TestSearchMemesSort(t *testing.T) {
+	var got pb.SearchSort
+	client := &MockMemeService{
+		SearchMemesFunc: func(ctx context.Context, in *pb.SearchMemesRequest) (*pb.MemesResponse, error) {
+			got = in.Sort
+			return &pb.MemesResponse{}, nil
+		},
+	}
+	server, err := NewWithMemeService(client, nil, nil, GetDebugLogger(), nil, MemCache)
+	if err != nil {
+		t.Fatal("Failed to create server")
+	}
+
+	tests := []struct {
+		sort       string
+		want       pb.SearchSort
+		wantStatus int
+	}{
+		{sort: "", want: pb.SearchSort_SEARCH_RELEVANCE, wantStatus: http.StatusOK},
+		{sort: "relevance", want: pb.SearchSort_SEARCH_RELEVANCE, wantStatus: http.StatusOK},
+		{sort: "newest", want: pb.SearchSort_SEARCH_NEWEST, wantStatus: http.StatusOK},
+		{sort: "most_downloaded", want: pb.SearchSort_SEARCH_MOST_DOWNLOADED, wantStatus: http.StatusOK},
+		{sort: "oldest", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		got = -1
+		w := httptest.NewRecorder()
+		server.SearchMemes(w, httptest.NewRequest(http.MethodGet, "/api/memes/search?query=cat&sort="+tt.sort, nil))
+		if w.Code != tt.wantStatus {
+			t.Errorf("sort=%s: expected status %d, got %d", tt.sort, tt.wantStatus, w.Code)
+		}
+		if tt.wantStatus == http.StatusOK && got != tt.want {
+			t.Errorf("sort=%s: expected %v, got %v", tt.sort, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/BassemHalim/memesHub/internal/fetcher"
+	pb "github.com/BassemHalim/memesHub/internal/proto/memeService"
+	"github.com/BassemHalim/memesHub/pkg/api"
+	"github.com/google/uuid"
+)
+
+// the platform of images that don't come from a known social media site
+const otherPlatform = "Other"
+
+type sourcePlatform struct {
+	platform string
+	// the site and the CDNs serving its images
+	domains []string
+}
+
+// sourcePlatforms maps the domains of every social media site to the platform stored in
+// images.social_media_platform
+var sourcePlatforms = []sourcePlatform{
+	{platform: "X", domains: []string{"x.com", "twitter.com", "twimg.com"}},
+	{platform: "Reddit", domains: []string{"reddit.com", "redd.it", "redditmedia.com"}},
+	{platform: "Instagram", domains: []string{"instagram.com", "cdninstagram.com"}},
+	{platform: "FB", domains: []string{"facebook.com", "fb.com", "fbcdn.net"}},
+	{platform: "Pinterest", domains: []string{"pinterest.com", "pinimg.com"}},
+	{platform: "LinkedIn", domains: []string{"linkedin.com", "licdn.com"}},
+	{platform: "Imgur", domains: []string{"imgur.com"}},
+}
+
+// isSourcePlatform reports whether platform is allowed by the valid_platform constraint
+func isSourcePlatform(platform string) bool {
+	if platform == otherPlatform {
+		return true
+	}
+	return slices.ContainsFunc(sourcePlatforms, func(p sourcePlatform) bool {
+		return p.platform == platform
+	})
+}
+
+// classifyPlatform returns the platform of the first URL on a known social media domain.
+// The post URL should come first since platforms share CDNs, Instagram images are served from
+// fbcdn.net for example.
+func classifyPlatform(urls ...string) string {
+	for _, rawURL := range urls {
+		parsed, err := url.Parse(rawURL)
+		if err != nil || parsed.Hostname() == "" {
+			continue
+		}
+		for _, p := range sourcePlatforms {
+			if fetcher.IsAllowedHost(parsed.Hostname(), p.domains) {
+				return p.platform
+			}
+		}
+	}
+	return otherPlatform
+}
+
+// storeImageSource records the URL the image was downloaded from and, when it was resolved from a
+// social media post, the post URL
+func (s *MemeService) storeImageSource(ctx context.Context, txn *sql.Tx, memeID string, source string, postURL string) error {
+	_, err := txn.ExecContext(ctx, `
+		INSERT INTO images (url, post_url, social_media_platform, meme_id)
+		VALUES ($1, NULLIF($2, ''), $3, $4)
+	`, source, postURL, classifyPlatform(postURL, source), memeID)
+	if err != nil {
+		return fmt.Errorf("failed to insert image source URL: %w", err)
+	}
+	return nil
+}
+
+// getImageSource returns the latest source recorded for a meme, or nil when it has none
+func (s *MemeService) getImageSource(ctx context.Context, memeID string) (*pb.MemeSource, error) {
+	var source pb.MemeSource
+	err := s.db.QueryRowContext(ctx, `
+		SELECT social_media_platform, url, COALESCE(post_url, ''), COALESCE(author, '')
+		FROM images
+		WHERE meme_id = $1
+		ORDER BY id DESC
+		LIMIT 1
+	`, memeID).Scan(&source.Platform, &source.Url, &source.PostUrl, &source.Author)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &source, nil
+}
+
+// UpdateMemeSource replaces the latest source of a meme, or records one if it has none
+func (s *MemeService) UpdateMemeSource(ctx context.Context, req *pb.UpdateMemeSourceRequest) (*pb.UpdateMemeSourceResponse, error) {
+	ctx, end := s.observe(ctx, "UpdateMemeSource")
+	defer end()
+	source := req.Source
+	if source == nil {
+		return nil, s.handleError(ctx, "Missing source", nil, codes.InvalidArgument)
+	}
+	if source.Platform == "" {
+		source.Platform = classifyPlatform(source.PostUrl, source.Url)
+	}
+	if !isSourcePlatform(source.Platform) {
+		return nil, s.handleError(ctx, fmt.Sprintf("Unknown platform %q", source.Platform), nil, codes.InvalidArgument)
+	}
+
+	txn, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, s.handleError(ctx, "Error starting transaction", err, codes.Internal)
+	}
+	defer txn.Rollback()
+
+	var exists bool
+	if err := txn.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM meme WHERE id = $1)`, req.Id).Scan(&exists); err != nil {
+		return nil, s.handleError(ctx, "Error getting meme", err, codes.Internal)
+	}
+	if !exists {
+		return nil, s.handleError(ctx, "Meme not found", nil, codes.NotFound)
+	}
+
+	result, err := txn.ExecContext(ctx, `
+		UPDATE images
+		SET url = $2, post_url = NULLIF($3, ''), social_media_platform = $4, author = NULLIF($5, '')
+		WHERE id = (SELECT id FROM images WHERE meme_id = $1 ORDER BY id DESC LIMIT 1)
+	`, req.Id, source.Url, source.PostUrl, source.Platform, source.Author)
+	if err != nil {
+		return nil, s.handleError(ctx, "Error updating the meme source", err, codes.Internal)
+	}
+	if updated, _ := result.RowsAffected(); updated == 0 {
+		if _, err := txn.ExecContext(ctx, `
+			INSERT INTO images (url, post_url, social_media_platform, author, meme_id)
+			VALUES ($1, NULLIF($2, ''), $3, NULLIF($4, ''), $5)
+		`, source.Url, source.PostUrl, source.Platform, source.Author, req.Id); err != nil {
+			return nil, s.handleError(ctx, "Error saving the meme source", err, codes.Internal)
+		}
+	}
+	if err := txn.Commit(); err != nil {
+		return nil, s.handleError(ctx, "Error committing the transaction", err, codes.Internal)
+	}
+	return &pb.UpdateMemeSourceResponse{Source: source}, nil
+}
+
+// PUT /api/admin/meme/{id}/source
+func (s *Server) UpdateMemeSource(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := uuid.Validate(id); err != nil {
+		s.handleError(w, r, err, "Bad ID", http.StatusBadRequest)
+		return
+	}
+	var req api.SourceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.handleError(w, r, err, "Error parsing the json", http.StatusBadRequest)
+		return
+	}
+	if err := s.structValidator.Struct(req); err != nil {
+		s.handleError(w, r, err, "The source is invalid or missing required fields", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+	resp, err := s.memeService.UpdateMemeSource(ctx, &pb.UpdateMemeSourceRequest{
+		Id: id,
+		Source: &pb.MemeSource{
+			Platform: req.Platform,
+			Url:      req.URL,
+			PostUrl:  req.PostURL,
+			Author:   req.Author,
+		},
+	})
+	if err != nil {
+		s.handleServiceError(w, r, err, "Failed to update the meme source")
+		return
+	}
+	// the cached meme still has the old source
+	s.cache.Delete(fmt.Sprintf("meme_%s", id))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(toSource(resp.Source))
+}
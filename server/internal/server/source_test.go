@@ -0,0 +1,224 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/BassemHalim/memesHub/internal/proto/memeService"
+)
+
+func TestClassifyPlatform(t *testing.T) {
+	tests := []struct {
+		name     string
+		urls     []string
+		platform string
+	}{
+		{name: "x image", urls: []string{"https://pbs.twimg.com/media/GAbCdEf.jpg"}, platform: "X"},
+		{name: "x post", urls: []string{"https://x.com/someone/status/123"}, platform: "X"},
+		{name: "twitter post", urls: []string{"https://mobile.twitter.com/someone/status/123"}, platform: "X"},
+		{name: "reddit image", urls: []string{"https://i.redd.it/abcdef.png"}, platform: "Reddit"},
+		{name: "reddit preview", urls: []string{"https://preview.redd.it/abcdef.png?width=640"}, platform: "Reddit"},
+		{name: "instagram image", urls: []string{"https://scontent.cdninstagram.com/v/123_n.jpg"}, platform: "Instagram"},
+		{name: "facebook image", urls: []string{"https://scontent.xx.fbcdn.net/v/123_n.jpg"}, platform: "FB"},
+		{name: "pinterest image", urls: []string{"https://i.pinimg.com/736x/ab/cd/ef.jpg"}, platform: "Pinterest"},
+		{name: "linkedin image", urls: []string{"https://media.licdn.com/dms/image/123"}, platform: "LinkedIn"},
+		{name: "imgur image", urls: []string{"https://i.imgur.com/AbCd123.jpeg"}, platform: "Imgur"},
+		{name: "unknown", urls: []string{"https://encrypted-tbn0.gstatic.com/images?q=tbn"}, platform: "Other"},
+		{name: "empty", urls: []string{""}, platform: "Other"},
+		{name: "post wins over shared cdn", urls: []string{"https://www.instagram.com/p/C1a2B3c/", "https://scontent.xx.fbcdn.net/v/123_n.jpg"}, platform: "Instagram"},
+		{name: "missing post falls back to image", urls: []string{"", "https://pbs.twimg.com/media/GAbCdEf.jpg"}, platform: "X"},
+		// the old substring matching classified these by the text anywhere in the URL
+		{name: "lookalike domain", urls: []string{"https://nottwimg.com/meme.png"}, platform: "Other"},
+		{name: "domain in the path", urls: []string{"https://example.com/imgur.com/meme.png"}, platform: "Other"},
+		{name: "domain in the query", urls: []string{"https://example.com/meme.png?from=redd.it"}, platform: "Other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if platform := classifyPlatform(tt.urls...); platform != tt.platform {
+				t.Errorf("Expected %s, got %s", tt.platform, platform)
+			}
+		})
+	}
+}
+
+func TestStoreImageSourceReturnsInsertError(t *testing.T) {
+	service, mock := newTestMemeService(t, &failingStorage{})
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO images`).
+		WithArgs("https://i.redd.it/abcdef.png", "https://www.reddit.com/r/memes/comments/1abc2de/", "Reddit", testMemeID).
+		WillReturnError(errors.New("value too long"))
+	mock.ExpectRollback()
+
+	txn, err := service.db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer txn.Rollback()
+	err = service.storeImageSource(context.Background(), txn, testMemeID, "https://i.redd.it/abcdef.png", "https://www.reddit.com/r/memes/comments/1abc2de/")
+	if err == nil {
+		t.Fatal("The insert error should be returned")
+	}
+}
+
+func TestGetMemeIncludesSource(t *testing.T) {
+	service, mock := newTestMemeService(t, &failingStorage{})
+
+	mock.ExpectQuery(`SELECT media_url, media_type, name, dimensions, download_count, share_count\s+FROM meme`).
+		WithArgs(testMemeID).
+		WillReturnRows(sqlmock.NewRows([]string{"media_url", "media_type", "name", "dimensions", "download_count", "share_count"}).
+			AddRow("https://imgs.example.com/imgs/meme.png", "image/png", "meme", "{4,3}", 0, 0))
+	mock.ExpectQuery(`SELECT social_media_platform, url, COALESCE\(post_url, ''\), COALESCE\(author, ''\)\s+FROM images`).
+		WithArgs(testMemeID).
+		WillReturnRows(sqlmock.NewRows([]string{"social_media_platform", "url", "post_url", "author"}).
+			AddRow("X", "https://pbs.twimg.com/media/GAbCdEf.jpg", "https://x.com/someone/status/123", "someone"))
+	mock.ExpectQuery(`SELECT t.name\s+FROM tag t`).
+		WithArgs(testMemeID).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("funny"))
+
+	resp, err := service.GetMeme(context.Background(), &pb.GetMemeRequest{Id: testMemeID})
+	if err != nil {
+		t.Fatal("GetMeme should succeed", err)
+	}
+	if resp.Source == nil || resp.Source.Platform != "X" || resp.Source.PostUrl != "https://x.com/someone/status/123" || resp.Source.Author != "someone" {
+		t.Errorf("Unexpected source %v", resp.Source)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUpdateMemeSource(t *testing.T) {
+	t.Run("updates the latest source", func(t *testing.T) {
+		service, mock := newTestMemeService(t, &failingStorage{})
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT EXISTS`).WithArgs(testMemeID).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectExec(`UPDATE images`).
+			WithArgs(testMemeID, "https://i.redd.it/abcdef.png", "", "Reddit", "u/someone").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		resp, err := service.UpdateMemeSource(context.Background(), &pb.UpdateMemeSourceRequest{
+			Id:     testMemeID,
+			Source: &pb.MemeSource{Url: "https://i.redd.it/abcdef.png", Author: "u/someone"},
+		})
+		if err != nil {
+			t.Fatal("Update should succeed", err)
+		}
+		if resp.Source.Platform != "Reddit" {
+			t.Errorf("The platform should be detected from the URL, got %s", resp.Source.Platform)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("records a source for memes without one", func(t *testing.T) {
+		service, mock := newTestMemeService(t, &failingStorage{})
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT EXISTS`).WithArgs(testMemeID).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectExec(`UPDATE images`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`INSERT INTO images`).
+			WithArgs("", "", "Other", "someone", testMemeID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		if _, err := service.UpdateMemeSource(context.Background(), &pb.UpdateMemeSourceRequest{
+			Id:     testMemeID,
+			Source: &pb.MemeSource{Platform: "Other", Author: "someone"},
+		}); err != nil {
+			t.Fatal("Update should succeed", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("missing meme", func(t *testing.T) {
+		service, mock := newTestMemeService(t, &failingStorage{})
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT EXISTS`).WithArgs(testMemeID).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectRollback()
+
+		_, err := service.UpdateMemeSource(context.Background(), &pb.UpdateMemeSourceRequest{
+			Id:     testMemeID,
+			Source: &pb.MemeSource{Url: "https://i.redd.it/abcdef.png"},
+		})
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("Expected NotFound, got %v", err)
+		}
+	})
+
+	t.Run("unknown platform", func(t *testing.T) {
+		service, _ := newTestMemeService(t, &failingStorage{})
+		_, err := service.UpdateMemeSource(context.Background(), &pb.UpdateMemeSourceRequest{
+			Id:     testMemeID,
+			Source: &pb.MemeSource{Platform: "MySpace"},
+		})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument, got %v", err)
+		}
+	})
+}
+
+func TestUpdateMemeSourceHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "valid", body: `{"url":"https://i.redd.it/abcdef.png","author":"u/someone"}`, expectedStatus: http.StatusOK},
+		{name: "invalid url", body: `{"url":"not a url"}`, expectedStatus: http.StatusBadRequest},
+		{name: "unknown platform", body: `{"platform":"MySpace"}`, expectedStatus: http.StatusBadRequest},
+		{name: "missing meme", body: `{"platform":"X"}`, err: status.Error(codes.NotFound, "Meme not found"), expectedStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &MockMemeService{
+				UpdateMemeSourceFunc: func(ctx context.Context, in *pb.UpdateMemeSourceRequest) (*pb.UpdateMemeSourceResponse, error) {
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return &pb.UpdateMemeSourceResponse{Source: in.Source}, nil
+				},
+			}
+			server, err := NewWithMemeService(client, nil, nil, GetDebugLogger(), nil, MemCache)
+			if err != nil {
+				t.Fatal("Failed to create server")
+			}
+			MemCache.Set("meme_"+testMemeID, &pb.MemeResponse{Id: testMemeID}, 0)
+
+			request := httptest.NewRequest(http.MethodPut, "/api/admin/meme/"+testMemeID+"/source", strings.NewReader(tt.body))
+			request.SetPathValue("id", testMemeID)
+			w := httptest.NewRecorder()
+			server.UpdateMemeSource(w, request)
+			res := w.Result()
+			if res.StatusCode != tt.expectedStatus {
+				body, _ := io.ReadAll(res.Body)
+				t.Fatalf("Expected status %d, got %d. Body: %s", tt.expectedStatus, res.StatusCode, string(body))
+			}
+			if res.StatusCode != http.StatusOK {
+				return
+			}
+			var source pb.MemeSource
+			json.NewDecoder(res.Body).Decode(&source)
+			if source.Author != "u/someone" {
+				t.Errorf("Unexpected source %v", &source)
+			}
+			if _, found := MemCache.Get("meme_" + testMemeID); found {
+				t.Error("The cached meme should be invalidated")
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Storage side effects are kept consistent with the database using two rules:
+//  1. objects a row is about to reference (new uploads, restores) are written before the
+//     transaction commits and are compensated if it fails
+//  2. objects a row stops referencing (soft deletes, renames of old versions, purges) are
+//     recorded in the storage_outbox table inside the transaction and only moved after commit.
+//     A failed move stays in the outbox and is retried by RunStorageReconciler.
+
+const (
+	opSoftDelete = "soft_delete"
+	opRename     = "rename"
+	opPurge      = "purge"
+)
+
+// outbox entries that failed this many times are left for manual inspection
+const maxStorageOpAttempts = 10
+
+type storageOp struct {
+	id        int64
+	operation string
+	filename  string
+	target    string
+	attempts  int
+}
+
+// enqueueStorageOp records a storage operation in the outbox as part of tx and returns its id
+func enqueueStorageOp(ctx context.Context, tx *sql.Tx, operation string, filename string, target string) (int64, error) {
+	var id int64
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO storage_outbox (operation, filename, target)
+		VALUES ($1, $2, NULLIF($3, ''))
+		RETURNING id
+	`, operation, filename, target).Scan(&id)
+	return id, err
+}
+
+func (s *MemeService) applyStorageOp(ctx context.Context, op storageOp) error {
+	switch op.operation {
+	case opSoftDelete:
+		return s.storage.SoftDeleteImage(ctx, op.filename)
+	case opRename:
+		_, err := s.storage.RenameImage(ctx, op.filename, op.target)
+		return err
+	case opPurge:
+		return s.storage.PurgeImage(ctx, op.filename)
+	default:
+		return fmt.Errorf("unknown storage operation %q", op.operation)
+	}
+}
+
+// processStorageOp claims a pending outbox entry, applies it and records the outcome.
+// Entries already processed or claimed by another worker are skipped.
+func (s *MemeService) processStorageOp(ctx context.Context, id int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var op storageOp
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, operation, filename, COALESCE(target, ''), attempts
+		FROM storage_outbox
+		WHERE id = $1 AND processed_at IS NULL
+		FOR UPDATE SKIP LOCKED
+	`, id).Scan(&op.id, &op.operation, &op.filename, &op.target, &op.attempts)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if opErr := s.applyStorageOp(ctx, op); opErr != nil {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE storage_outbox
+			SET attempts = attempts + 1, last_error = $2
+			WHERE id = $1
+		`, op.id, opErr.Error()); err != nil {
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		return opErr
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE storage_outbox
+		SET attempts = attempts + 1, last_error = NULL, processed_at = NOW()
+		WHERE id = $1
+	`, op.id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// runStorageOps applies freshly committed outbox entries. Failures are only logged
+// since the reconciler will pick the entries up again.
+func (s *MemeService) runStorageOps(ctx context.Context, ids ...int64) {
+	for _, id := range ids {
+		if err := s.processStorageOp(ctx, id); err != nil {
+			s.log.ErrorContext(ctx, "Storage operation failed, will be retried by the reconciler", "Error", err, "OutboxID", id)
+		}
+	}
+}
+
+// ReconcileStorageOps retries every pending outbox entry and returns how many are still pending
+func (s *MemeService) ReconcileStorageOps(ctx context.Context) (int, error) {
+	ctx, end := s.observe(ctx, "ReconcileStorageOps")
+	defer end()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id
+		FROM storage_outbox
+		WHERE processed_at IS NULL AND attempts < $1
+		ORDER BY id
+		LIMIT 100
+	`, maxStorageOpAttempts)
+	if err != nil {
+		return 0, err
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	pending := 0
+	for _, id := range ids {
+		if err := s.processStorageOp(ctx, id); err != nil {
+			s.log.WarnContext(ctx, "Storage operation retry failed", "Error", err, "OutboxID", id)
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+// RunStorageReconciler retries failed storage operations once every interval until ctx is cancelled
+func (s *MemeService) RunStorageReconciler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pending, err := s.ReconcileStorageOps(ctx)
+			if err != nil {
+				s.log.ErrorContext(ctx, "Failed to reconcile storage operations", "Error", err)
+				continue
+			}
+			if pending > 0 {
+				s.log.WarnContext(ctx, "Storage operations still pending", "Pending", pending)
+			}
+		}
+	}
+}
+
+// RunStorageReconciler retries failed storage operations of the in-process meme service.
+// It returns immediately when the meme service runs elsewhere.
+func (s *Server) RunStorageReconciler(ctx context.Context, interval time.Duration) {
+	memeService, ok := s.memeService.(*MemeService)
+	if !ok {
+		return
+	}
+	memeService.RunStorageReconciler(ctx, interval)
+}
@@ -0,0 +1,243 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/patrickmn/go-cache"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/BassemHalim/memesHub/internal/apierror"
+	"github.com/BassemHalim/memesHub/internal/metrics"
+	pb "github.com/BassemHalim/memesHub/internal/proto/memeService"
+)
+
+const (
+	// SearchMemes suggests other queries when it finds fewer memes than sparseResults
+	sparseResults  = 5
+	maxSuggestions = 5
+
+	defaultCompletions = 10
+	maxCompletions     = 20
+)
+
+// suggest returns the tag and meme names close to the terms of a search, the spelling
+// suggestions, and the popular tags of the memes whose name or tags are close to them. The
+// normalized names are matched with the % operator of pg_trgm so the trigram indexes are used.
+func (s *MemeService) suggest(ctx context.Context, terms []string) ([]string, []string, error) {
+	terms = normalizeTags(terms)
+	var suggestions []string
+	rows, err := s.db.QueryContext(ctx, `
+		WITH terms AS (SELECT unnest($1::text[]) AS term)
+		SELECT name FROM (
+			SELECT DISTINCT ON (c.normalized_name) c.name, c.sim
+			FROM (
+				SELECT t.name, t.normalized_name, similarity(t.normalized_name, terms.term) AS sim
+				FROM tag t JOIN terms ON t.normalized_name % terms.term
+				UNION ALL
+				SELECT m.name, m.normalized_name, similarity(m.normalized_name, terms.term)
+				FROM meme m JOIN terms ON m.normalized_name % terms.term
+				WHERE m.approval_status = 'approved' AND m.deleted_at IS NULL
+			) c
+			WHERE c.normalized_name NOT IN (SELECT term FROM terms)
+			ORDER BY c.normalized_name, c.sim DESC
+		) suggestions
+		ORDER BY sim DESC, name
+		LIMIT $2
+	`, pq.Array(terms), maxSuggestions)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, nil, err
+		}
+		suggestions = append(suggestions, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var related []string
+	rows, err = s.db.QueryContext(ctx, `
+		WITH terms AS (SELECT unnest($1::text[]) AS term),
+		similar_memes AS (
+			SELECT m.id FROM meme m JOIN terms ON m.normalized_name % terms.term
+			UNION
+			SELECT mt.meme_id FROM meme_tag mt JOIN tag t ON t.id = mt.tag_id JOIN terms ON t.normalized_name % terms.term
+		)
+		SELECT t.name
+		FROM similar_memes sm
+		JOIN meme m ON m.id = sm.id AND m.approval_status = 'approved' AND m.deleted_at IS NULL
+		JOIN meme_tag mt ON mt.meme_id = m.id
+		JOIN tag t ON t.id = mt.tag_id
+		WHERE t.normalized_name NOT IN (SELECT term FROM terms)
+		GROUP BY t.name
+		ORDER BY COUNT(*) DESC, SUM(m.download_count) DESC, t.name
+		LIMIT $2
+	`, pq.Array(terms), maxSuggestions+len(suggestions))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, nil, err
+		}
+		// a tag suggested as a spelling isn't repeated as a related tag
+		if !slices.ContainsFunc(suggestions, sameTag(name)) && len(related) < maxSuggestions {
+			related = append(related, name)
+		}
+	}
+	return suggestions, related, rows.Err()
+}
+
+// sameTag returns a function reporting whether a name is spelled like name once normalized
+func sameTag(name string) func(string) bool {
+	normalized := normalizeTag(name)
+	return func(other string) bool {
+		return normalizeTag(other) == normalized
+	}
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// likePrefix escapes the wildcards of prefix for a LIKE pattern matching the text starting with it
+func likePrefix(prefix string) string {
+	return likeEscaper.Replace(prefix) + "%"
+}
+
+// Autocomplete returns the tag and meme names starting with the prefix, ignoring case and the
+// Arabic spelling variants. The tags used by the most published memes and the most downloaded
+// meme names come first, alternating between the two.
+func (s *MemeService) Autocomplete(ctx context.Context, req *pb.AutocompleteRequest) (*pb.AutocompleteResponse, error) {
+	ctx, end := s.observe(ctx, "Autocomplete")
+	defer end()
+	prefix := normalizeTag(req.Prefix)
+	if prefix == "" {
+		return nil, status.Error(codes.InvalidArgument, "The prefix can't be empty")
+	}
+	limit := int(req.Limit)
+	if limit < 1 {
+		limit = defaultCompletions
+	}
+	limit = min(limit, maxCompletions)
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT name, kind FROM (
+			(SELECT t.name, 0 AS kind, COUNT(*) AS popularity
+			FROM tag t
+			JOIN meme_tag mt ON mt.tag_id = t.id
+			JOIN meme m ON m.id = mt.meme_id AND m.approval_status = 'approved' AND m.deleted_at IS NULL
+			WHERE t.normalized_name LIKE $1
+			GROUP BY t.name
+			ORDER BY popularity DESC, t.name
+			LIMIT $2)
+			UNION ALL
+			(SELECT min(m.name), 1, SUM(m.download_count)
+			FROM meme m
+			WHERE m.normalized_name LIKE $1 AND m.approval_status = 'approved' AND m.deleted_at IS NULL
+			GROUP BY m.normalized_name
+			ORDER BY 3 DESC, 1
+			LIMIT $2)
+		) completions
+		ORDER BY kind, popularity DESC, name
+	`, likePrefix(prefix), limit)
+	if err != nil {
+		return nil, s.handleError(ctx, "error autocompleting", err, codes.Internal)
+	}
+	defer rows.Close()
+
+	var tags, memes []string
+	for rows.Next() {
+		var name string
+		var kind pb.CompletionKind
+		if err := rows.Scan(&name, &kind); err != nil {
+			return nil, s.handleError(ctx, "error scanning completion", err, codes.Internal)
+		}
+		if kind == pb.CompletionKind_COMPLETION_TAG {
+			tags = append(tags, name)
+		} else {
+			memes = append(memes, name)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, s.handleError(ctx, "error autocompleting", err, codes.Internal)
+	}
+	return &pb.AutocompleteResponse{Completions: mixCompletions(tags, memes, limit)}, nil
+}
+
+// mixCompletions alternates between the tags and the meme names, starting with a tag, up to
+// limit completions. A meme named like a completed tag is left out.
+func mixCompletions(tags, memes []string, limit int) []*pb.Completion {
+	var completions []*pb.Completion
+	add := func(name string, kind pb.CompletionKind) {
+		if len(completions) < limit {
+			completions = append(completions, &pb.Completion{Text: name, Kind: kind})
+		}
+	}
+	for i := 0; i < len(tags) || i < len(memes); i++ {
+		if i < len(tags) {
+			add(tags[i], pb.CompletionKind_COMPLETION_TAG)
+		}
+		if i < len(memes) && !slices.ContainsFunc(tags, sameTag(memes[i])) {
+			add(memes[i], pb.CompletionKind_COMPLETION_MEME)
+		}
+	}
+	return completions
+}
+
+// GET /api/autocomplete?query=prefix&limit=num
+func (s *Server) Autocomplete(w http.ResponseWriter, r *http.Request) {
+	queryParams := r.URL.Query()
+	query := strings.TrimSpace(queryParams.Get("query"))
+	if query == "" {
+		apierror.Write(w, r, http.StatusBadRequest, "Invalid query parameters", nil)
+		return
+	}
+	limit := 0
+	if l := queryParams.Get("limit"); l != "" {
+		var err error
+		if limit, err = strconv.Atoi(l); err != nil {
+			s.handleError(w, r, err, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	cacheKey := fmt.Sprintf("autocomplete_%q_%d", normalizeTag(query), limit)
+	cached, found := s.cache.Get(cacheKey)
+	metrics.CacheLookup("autocomplete", found)
+	if found {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(cached)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+	resp, err := s.memeService.Autocomplete(ctx, &pb.AutocompleteRequest{
+		Prefix: query,
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		s.handleServiceError(w, r, err, "Failed to autocomplete")
+		return
+	}
+	completions := toCompletionList(resp)
+	s.cache.Set(cacheKey, completions, cache.DefaultExpiration)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(completions)
+}
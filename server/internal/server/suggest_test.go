@@ -0,0 +1,201 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/patrickmn/go-cache"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/BassemHalim/memesHub/internal/proto/memeService"
+	"github.com/BassemHalim/memesHub/pkg/api"
+)
+
+func TestSearchMemesSuggestsWhenSparse(t *testing.T) {
+	service, mock := newTestMemeService(t, &failingStorage{})
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM`).
+		WithArgs("funy", "nsfw", "drak").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`LIMIT \$4 OFFSET \$5`).
+		WithArgs("funy", "nsfw", "drak", 40, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	// the tag:funy filter is a term a misspelling can hide in too
+	mock.ExpectQuery(`SELECT DISTINCT ON \(c.normalized_name\) c.name, c.sim`).
+		WithArgs(`{"drak","funy"}`, maxSuggestions).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("drake").AddRow("funny"))
+	mock.ExpectQuery(`similar_memes AS`).
+		WithArgs(`{"drak","funy"}`, maxSuggestions+2).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Funny").AddRow("hotline bling").AddRow("music"))
+
+	resp, err := service.SearchMemes(context.Background(), &pb.SearchMemesRequest{Query: "drak tag:funy -tag:nsfw"})
+	if err != nil {
+		t.Fatal("Search should succeed", err)
+	}
+	if want := []string{"drake", "funny"}; !reflect.DeepEqual(resp.Suggestions, want) {
+		t.Errorf("Expected the suggestions %q, got %q", want, resp.Suggestions)
+	}
+	// the suggested funny tag isn't related again
+	if want := []string{"hotline bling", "music"}; !reflect.DeepEqual(resp.RelatedTags, want) {
+		t.Errorf("Expected the related tags %q, got %q", want, resp.RelatedTags)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSearchMemesSuggestions(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		count   int
+		suggest bool
+	}{
+		{name: "enough results", query: "cat", count: sparseResults},
+		{name: "only filters", query: "type:gif", count: 0},
+		{name: "suggestion error", query: "cat", count: 1, suggest: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newTestMemeService(t, &failingStorage{})
+			mock.ExpectQuery(`SELECT COUNT\(\*\) FROM`).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.count))
+			mock.ExpectQuery(`LIMIT \$\d OFFSET \$\d`).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			if tt.suggest {
+				mock.ExpectQuery(`SELECT DISTINCT ON`).WillReturnError(errors.New("pg_trgm isn't installed"))
+			}
+
+			resp, err := service.SearchMemes(context.Background(), &pb.SearchMemesRequest{Query: tt.query})
+			if err != nil {
+				t.Fatal("Search should succeed without suggestions", err)
+			}
+			if len(resp.Suggestions) != 0 || len(resp.RelatedTags) != 0 {
+				t.Errorf("Expected no suggestions, got %q and %q", resp.Suggestions, resp.RelatedTags)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestAutocomplete(t *testing.T) {
+	service, mock := newTestMemeService(t, &failingStorage{})
+
+	// the wildcards of the prefix are escaped
+	mock.ExpectQuery(`SELECT name, kind FROM`).
+		WithArgs(`dr\_%`, defaultCompletions).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "kind"}).
+			AddRow("dr_who", 0).
+			AddRow("dr_strange", 0).
+			AddRow("Dr_Who", 1).
+			AddRow("dr_strange meme", 1))
+	mock.ExpectQuery(`SELECT name, kind FROM`).
+		WithArgs(`cat%`, maxCompletions).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "kind"}))
+
+	resp, err := service.Autocomplete(context.Background(), &pb.AutocompleteRequest{Prefix: " DR_"})
+	if err != nil {
+		t.Fatal("Autocomplete should succeed", err)
+	}
+	var got []string
+	for _, c := range resp.Completions {
+		got = append(got, c.Kind.String()+" "+c.Text)
+	}
+	want := []string{"COMPLETION_TAG dr_who", "COMPLETION_TAG dr_strange", "COMPLETION_MEME dr_strange meme"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	if _, err := service.Autocomplete(context.Background(), &pb.AutocompleteRequest{Prefix: "cat", Limit: 100}); err != nil {
+		t.Fatal("Autocomplete should succeed", err)
+	}
+	if _, err := service.Autocomplete(context.Background(), &pb.AutocompleteRequest{Prefix: "  "}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("An empty prefix should be an invalid argument, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMixCompletions(t *testing.T) {
+	tests := []struct {
+		name  string
+		tags  []string
+		memes []string
+		limit int
+		want  []string
+	}{
+		{name: "alternates", tags: []string{"a", "b"}, memes: []string{"x", "y", "z"}, limit: 10, want: []string{"a", "x", "b", "y", "z"}},
+		{name: "limited", tags: []string{"a", "b", "c"}, memes: []string{"x"}, limit: 3, want: []string{"a", "x", "b"}},
+		{name: "no tags", memes: []string{"x"}, limit: 3, want: []string{"x"}},
+		{name: "memes named like a tag", tags: []string{"Cat"}, memes: []string{"cat", "cats"}, limit: 3, want: []string{"Cat", "cats"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, c := range mixCompletions(tt.tags, tt.memes, tt.limit) {
+				got = append(got, c.Text)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAutocompleteHandler(t *testing.T) {
+	calls := 0
+	client := &MockMemeService{
+		AutocompleteFunc: func(ctx context.Context, in *pb.AutocompleteRequest) (*pb.AutocompleteResponse, error) {
+			calls++
+			if in.Prefix != "d" || in.Limit != 3 {
+				t.Errorf("Unexpected request %v", in)
+			}
+			return &pb.AutocompleteResponse{Completions: []*pb.Completion{
+				{Text: "drake", Kind: pb.CompletionKind_COMPLETION_TAG},
+				{Text: "distracted boyfriend", Kind: pb.CompletionKind_COMPLETION_MEME},
+			}}, nil
+		},
+	}
+	server, err := NewWithMemeService(client, nil, nil, GetDebugLogger(), nil, cache.New(time.Minute, time.Minute))
+	if err != nil {
+		t.Fatal("Failed to create server")
+	}
+
+	for range 2 {
+		w := httptest.NewRecorder()
+		server.Autocomplete(w, httptest.NewRequest(http.MethodGet, "/api/autocomplete?query=d&limit=3", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		var completions api.CompletionList
+		if err := json.NewDecoder(w.Body).Decode(&completions); err != nil {
+			t.Fatal(err)
+		}
+		want := []api.Completion{{Text: "drake", Kind: api.CompletionTag}, {Text: "distracted boyfriend", Kind: api.CompletionMeme}}
+		if !reflect.DeepEqual(completions.Completions, want) {
+			t.Errorf("Expected %v, got %v", want, completions.Completions)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("The completions should be cached, the service was called %d times", calls)
+	}
+
+	for _, query := range []string{"query=", "query=d&limit=ten"} {
+		w := httptest.NewRecorder()
+		server.Autocomplete(w, httptest.NewRequest(http.MethodGet, "/api/autocomplete?"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", query, w.Code)
+		}
+	}
+}
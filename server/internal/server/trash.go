@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	pb "github.com/BassemHalim/memesHub/internal/proto/memeService"
+)
+
+// GET /api/admin/memes/trash
+func (s *Server) GetTrash(w http.ResponseWriter, r *http.Request) {
+	queryParams := r.URL.Query()
+	page, pageSize := 1, 10
+	if p := queryParams.Get("page"); p != "" {
+		if v, err := strconv.Atoi(p); err == nil && v > 0 {
+			page = v
+		}
+	}
+	if ps := queryParams.Get("pageSize"); ps != "" {
+		if v, err := strconv.Atoi(ps); err == nil && v > 0 {
+			pageSize = v
+		}
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+	resp, err := s.memeService.GetDeletedMemes(ctx, &pb.GetDeletedMemesRequest{
+		Page:     int32(page),
+		PageSize: int32(pageSize),
+	})
+	if err != nil {
+		s.handleServiceError(w, r, err, "Failed to fetch deleted memes")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toMemePage(resp))
+}
+
+// PATCH /api/admin/meme/{id}/restore
+func (s *Server) RestoreMeme(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := uuid.Validate(id); err != nil {
+		s.handleError(w, r, err, "Bad ID", http.StatusBadRequest)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+	_, err := s.memeService.RestoreMeme(ctx, &pb.RestoreMemeRequest{MemeId: id})
+	if err != nil {
+		s.handleServiceError(w, r, err, "Failed to restore meme")
+		return
+	}
+	s.log.InfoContext(r.Context(), "Meme restored", "ID", id)
+	w.WriteHeader(http.StatusOK)
+}
+
+// PurgeTrash permanently deletes memes that have been in the trash for longer than retentionDays.
+// It runs once every interval until ctx is cancelled.
+func (s *Server) PurgeTrash(ctx context.Context, interval time.Duration, retentionDays int32) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purgeCtx, cancel := context.WithTimeout(ctx, time.Minute)
+			resp, err := s.memeService.PurgeDeletedMemes(purgeCtx, &pb.PurgeDeletedMemesRequest{OlderThanDays: retentionDays})
+			cancel()
+			if err != nil {
+				s.log.ErrorContext(ctx, "Failed to purge trash", "ERROR", err)
+				continue
+			}
+			s.log.InfoContext(ctx, "Purged trash", "Purged", resp.Purged, "RetentionDays", retentionDays)
+		}
+	}
+}
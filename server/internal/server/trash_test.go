@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "github.com/BassemHalim/memesHub/internal/proto/memeService"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRestoreMeme(t *testing.T) {
+	tests := []struct {
+		name           string
+		memeID         string
+		mockFunc       func(ctx context.Context, in *pb.RestoreMemeRequest) (*pb.RestoreMemeResponse, error)
+		expectedStatus int
+	}{
+		{
+			name:           "Valid meme ID - success",
+			memeID:         "7218d21c-ac37-4ebe-b436-c51486d23b95",
+			mockFunc:       nil,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid UUID format",
+			memeID:         "invalid-uuid",
+			mockFunc:       nil,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Meme not in trash",
+			memeID: "7218d21c-ac37-4ebe-b436-c51486d23b95",
+			mockFunc: func(ctx context.Context, in *pb.RestoreMemeRequest) (*pb.RestoreMemeResponse, error) {
+				return &pb.RestoreMemeResponse{Success: false, Error: "Meme not found in trash"}, status.Error(codes.NotFound, "Meme not found in trash")
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "Storage error",
+			memeID: "7218d21c-ac37-4ebe-b436-c51486d23b95",
+			mockFunc: func(ctx context.Context, in *pb.RestoreMemeRequest) (*pb.RestoreMemeResponse, error) {
+				return &pb.RestoreMemeResponse{Success: false}, status.Error(codes.Internal, "error restoring image")
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &MockMemeService{
+				RestoreMemeFunc: tt.mockFunc,
+			}
+
+			server, err := NewWithMemeService(client, nil, nil, GetDebugLogger(), nil, MemCache)
+			if err != nil {
+				t.Fatal("Failed to create server")
+			}
+
+			request := httptest.NewRequest(http.MethodPatch, "/api/admin/meme/"+tt.memeID+"/restore", nil)
+			request.SetPathValue("id", tt.memeID)
+			w := httptest.NewRecorder()
+
+			server.RestoreMeme(w, request)
+
+			res := w.Result()
+			if res.StatusCode != tt.expectedStatus {
+				body, _ := io.ReadAll(res.Body)
+				t.Errorf("Expected status %d, got %d. Body: %s", tt.expectedStatus, res.StatusCode, string(body))
+			}
+		})
+	}
+}
+
+func TestGetTrash(t *testing.T) {
+	client := &MockMemeService{
+		GetDeletedMemesFunc: func(ctx context.Context, in *pb.GetDeletedMemesRequest) (*pb.MemesResponse, error) {
+			if in.Page != 2 || in.PageSize != 5 {
+				t.Errorf("Expected page 2 and pageSize 5, got %d and %d", in.Page, in.PageSize)
+			}
+			return &pb.MemesResponse{
+				Memes: []*pb.MemeResponse{
+					{
+						Id:        "7218d21c-ac37-4ebe-b436-c51486d23b95",
+						MediaUrl:  "https://example.com/image/1.jpg",
+						Name:      "deleted",
+						DeletedAt: "2026-01-02T03:04:05Z",
+					},
+				},
+				TotalCount: 1,
+				Page:       2,
+				TotalPages: 1,
+			}, nil
+		},
+	}
+
+	server, err := NewWithMemeService(client, nil, nil, GetDebugLogger(), nil, MemCache)
+	if err != nil {
+		t.Fatal("Failed to create server")
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/api/admin/memes/trash?page=2&pageSize=5", nil)
+	w := httptest.NewRecorder()
+	server.GetTrash(w, request)
+	res := w.Result()
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(res.Body)
+		t.Fatal("Request should succeed", res.Status, string(body))
+	}
+
+	var response map[string]interface{}
+	json.NewDecoder(res.Body).Decode(&response)
+	memes, ok := response["memes"].([]interface{})
+	if !ok || len(memes) != 1 {
+		t.Fatal("Response should contain the deleted meme")
+	}
+	if deletedAt := memes[0].(map[string]interface{})["deleted_at"]; deletedAt != "2026-01-02T03:04:05Z" {
+		t.Errorf("Expected deleted_at to be set, got %v", deletedAt)
+	}
+}
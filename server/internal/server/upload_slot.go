@@ -0,0 +1,293 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+
+	pb "github.com/BassemHalim/memesHub/internal/proto/memeService"
+	"github.com/BassemHalim/memesHub/internal/storage"
+	"github.com/BassemHalim/memesHub/internal/utils"
+	"github.com/BassemHalim/memesHub/pkg/api"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Direct uploads skip the gateway: the client asks for an upload slot, PUTs the image to the
+// presigned URL and then finalizes the slot. The presigned URL only reaches a staging key, stored
+// in upload_slot.filename, that is never served. Finalize moves the staged object to a new random
+// image the client can't write to and checks that copy against the slot before creating the meme.
+// Staged objects of slots that are never finalized are deleted when the slots are cleaned up.
+
+// how long a presigned upload URL stays valid
+const uploadSlotTTL = 15 * time.Minute
+
+// the formats image.DecodeConfig understands
+var uploadMediaTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+func (s *MemeService) CreateUploadSlot(ctx context.Context, req *pb.CreateUploadSlotRequest) (*pb.UploadSlotResponse, error) {
+	ctx, end := s.observe(ctx, "CreateUploadSlot")
+	defer end()
+	if req.Size <= 0 {
+		return nil, s.handleError(ctx, "Invalid image size", nil, codes.InvalidArgument)
+	}
+	if !uploadMediaTypes[req.MediaType] {
+		return nil, s.handleError(ctx, "Unsupported media type", nil, codes.InvalidArgument)
+	}
+	ext, err := utils.MimeToExtension(req.MediaType)
+	if err != nil {
+		return nil, s.handleError(ctx, "Invalid mime type", err, codes.InvalidArgument)
+	}
+	uploadKey := utils.RandomUUID() + ext
+
+	upload, err := s.storage.PresignUpload(ctx, uploadKey, req.MediaType, req.Size, uploadSlotTTL)
+	if err != nil {
+		return nil, s.handleError(ctx, "Error creating the upload URL", err, codes.Internal)
+	}
+
+	s.cleanUpExpiredUploadSlots(ctx)
+
+	var slotID string
+	err = s.db.QueryRowContext(ctx, `
+		INSERT INTO upload_slot (filename, media_type, size, expires_at)
+		VALUES ($1, $2, $3, NOW() + make_interval(secs => $4))
+		RETURNING id::text
+	`, uploadKey, req.MediaType, req.Size, uploadSlotTTL.Seconds()).Scan(&slotID)
+	if err != nil {
+		return nil, s.handleError(ctx, "Error saving the upload slot", err, codes.Internal)
+	}
+
+	return &pb.UploadSlotResponse{
+		Id:        slotID,
+		UploadUrl: upload.URL,
+		Method:    upload.Method,
+		Headers:   upload.Headers,
+		ExpiresAt: upload.ExpiresAt.UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// cleanUpExpiredUploadSlots drops the slots that expired long ago along with their staged uploads
+func (s *MemeService) cleanUpExpiredUploadSlots(ctx context.Context) {
+	rows, err := s.db.QueryContext(ctx, `
+		DELETE FROM upload_slot
+		WHERE finalized_at IS NULL AND expires_at < NOW() - INTERVAL '1 day'
+		RETURNING filename
+	`)
+	if err != nil {
+		s.log.WarnContext(ctx, "Failed to clean up expired upload slots", "Error", err)
+		return
+	}
+	var uploadKeys []string
+	for rows.Next() {
+		var uploadKey string
+		if err := rows.Scan(&uploadKey); err != nil {
+			s.log.WarnContext(ctx, "Failed to clean up expired upload slots", "Error", err)
+			break
+		}
+		uploadKeys = append(uploadKeys, uploadKey)
+	}
+	rows.Close()
+	for _, uploadKey := range uploadKeys {
+		if err := s.storage.DeleteUpload(ctx, uploadKey); err != nil {
+			s.log.WarnContext(ctx, "Failed to delete the upload of an expired slot", "Error", err, "Upload", uploadKey)
+		}
+	}
+}
+
+// FinalizeUpload publishes the image uploaded to a slot, verifies it and creates its meme.
+// An image that doesn't match the slot is discarded and the slot can't be finalized again.
+func (s *MemeService) FinalizeUpload(ctx context.Context, req *pb.FinalizeUploadRequest) (*pb.MemeResponse, error) {
+	ctx, end := s.observe(ctx, "FinalizeUpload")
+	defer end()
+	if req.Name == "" {
+		return nil, s.handleError(ctx, "Missing meme name", nil, codes.InvalidArgument)
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, s.handleError(ctx, "Error starting transaction", err, codes.Internal)
+	}
+	defer tx.Rollback()
+
+	var uploadKey, mediaType string
+	var size int64
+	err = tx.QueryRowContext(ctx, `
+		SELECT filename, media_type, size
+		FROM upload_slot
+		WHERE id = $1 AND finalized_at IS NULL AND expires_at > NOW()
+		FOR UPDATE
+	`, req.SlotId).Scan(&uploadKey, &mediaType, &size)
+	if err == sql.ErrNoRows {
+		return nil, s.handleError(ctx, "Upload slot not found or expired", err, codes.NotFound)
+	}
+	if err != nil {
+		return nil, s.handleError(ctx, "Error getting the upload slot", err, codes.Internal)
+	}
+	ext, err := utils.MimeToExtension(mediaType)
+	if err != nil {
+		return nil, s.handleError(ctx, "Invalid mime type", err, codes.Internal)
+	}
+
+	// the image is verified after it is published since the client can overwrite the staged
+	// object until its upload URL expires
+	filename := utils.RandomUUID() + ext
+	if _, err := s.storage.PublishUpload(ctx, uploadKey, filename); err != nil {
+		// the client may finalize before its upload completes, the slot stays usable
+		return nil, s.handleError(ctx, "The image hasn't been uploaded yet", err, codes.FailedPrecondition)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			s.discardImage(ctx, filename)
+		}
+	}()
+
+	body, info, err := s.storage.OpenImage(ctx, filename)
+	if err != nil {
+		return nil, s.handleError(ctx, "Error reading the uploaded image", err, codes.Internal)
+	}
+	dimensions, err := verifyUploadedImage(body, info, mediaType, size)
+	body.Close()
+	if err != nil {
+		if _, err := tx.ExecContext(ctx, `UPDATE upload_slot SET finalized_at = NOW() WHERE id = $1`, req.SlotId); err != nil {
+			return nil, s.handleError(ctx, "Error closing the upload slot", err, codes.Internal)
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, s.handleError(ctx, "Error committing the transaction", err, codes.Internal)
+		}
+		return nil, s.handleError(ctx, fmt.Sprintf("Invalid upload: %s", err), err, codes.InvalidArgument)
+	}
+
+	mediaURL := s.storage.ImageUrl(filename)
+	var memeID string
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO meme (media_url, media_type, name, dimensions)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id::text
+	`, mediaURL, mediaType, req.Name, pq.Array(dimensions)).Scan(&memeID)
+	if err != nil {
+		return nil, s.handleError(ctx, "Error inserting meme", err, codes.Internal)
+	}
+	if err := saveTags(ctx, memeID, req.Tags, tx); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE upload_slot
+		SET finalized_at = NOW(), meme_id = $2
+		WHERE id = $1
+	`, req.SlotId, memeID); err != nil {
+		return nil, s.handleError(ctx, "Error closing the upload slot", err, codes.Internal)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, s.handleError(ctx, "Error committing the transaction", err, codes.Internal)
+	}
+	committed = true
+
+	return &pb.MemeResponse{
+		Id:         memeID,
+		MediaUrl:   mediaURL,
+		MediaType:  mediaType,
+		Name:       req.Name,
+		Tags:       req.Tags,
+		Dimensions: dimensions,
+	}, nil
+}
+
+// verifyUploadedImage checks the uploaded bytes against the slot and returns the image dimensions
+func verifyUploadedImage(body io.Reader, info storage.ImageInfo, mediaType string, size int64) ([]int32, error) {
+	if info.Size != size {
+		return nil, fmt.Errorf("uploaded %d bytes instead of %d", info.Size, size)
+	}
+	head := make([]byte, 512)
+	n, err := io.ReadFull(body, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("failed to read the image: %w", err)
+	}
+	head = head[:n]
+	// detect the real MIME type from the bytes, ignoring the declared one
+	if detected := http.DetectContentType(head); detected != mediaType {
+		return nil, fmt.Errorf("uploaded %s instead of %s", detected, mediaType)
+	}
+	config, _, err := image.DecodeConfig(io.MultiReader(bytes.NewReader(head), body))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported image format: %w", err)
+	}
+	return []int32{int32(config.Width), int32(config.Height)}, nil
+}
+
+// POST /api/meme/upload
+// Returns a presigned URL the client uploads the image to before finalizing it
+func (s *Server) CreateUploadSlot(w http.ResponseWriter, r *http.Request) {
+	var req api.UploadSlotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.handleError(w, r, err, "Error parsing the upload request", http.StatusBadRequest)
+		return
+	}
+	if err := s.structValidator.Struct(req); err != nil {
+		s.handleError(w, r, err, "The upload request is invalid or missing required fields", http.StatusBadRequest)
+		return
+	}
+	if maxUploadSize := s.config.Current().MaxUploadSize; req.Size > maxUploadSize {
+		s.handleError(w, r, nil, fmt.Sprintf("Uploaded file is too big it must be <= %d", maxUploadSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+	resp, err := s.memeService.CreateUploadSlot(ctx, &pb.CreateUploadSlotRequest{
+		MediaType: req.MediaType,
+		Size:      req.Size,
+	})
+	if err != nil {
+		s.handleServiceError(w, r, err, "Error creating the upload")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toUploadSlot(resp))
+}
+
+// POST /api/meme/upload/{id}/finalize
+func (s *Server) FinalizeUpload(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := uuid.Validate(id); err != nil {
+		s.handleError(w, r, err, "Bad ID", http.StatusBadRequest)
+		return
+	}
+	var req api.FinalizeUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.handleError(w, r, err, "Error parsing the meme data", http.StatusBadRequest)
+		return
+	}
+	if err := s.structValidator.Struct(req); err != nil {
+		s.handleError(w, r, err, "The meme data is invalid or missing required fields", http.StatusBadRequest)
+		return
+	}
+
+	// finalizing reads the uploaded image back from storage
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+	resp, err := s.memeService.FinalizeUpload(ctx, &pb.FinalizeUploadRequest{
+		SlotId: id,
+		Name:   req.Name,
+		Tags:   req.Tags,
+	})
+	if err != nil {
+		s.handleServiceError(w, r, err, "Error uploading the meme")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(toMeme(resp))
+}
@@ -0,0 +1,367 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/BassemHalim/memesHub/internal/auth"
+	"github.com/BassemHalim/memesHub/internal/config"
+	pb "github.com/BassemHalim/memesHub/internal/proto/memeService"
+	rateLimiter "github.com/BassemHalim/memesHub/internal/rate-limiter/IP_ratelimiter"
+	"github.com/BassemHalim/memesHub/internal/storage"
+)
+
+const testSlotID = "0b0e8c1f-6a57-4d8f-9a53-5d1c2f0e2a11"
+
+func testPNG(t *testing.T) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 3))); err != nil {
+		t.Fatal("Failed to encode test image", err)
+	}
+	return buf.Bytes()
+}
+
+func expectUploadSlot(mock sqlmock.Sqlmock, mediaType string, size int) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT filename, media_type, size\s+FROM upload_slot`).
+		WithArgs(testSlotID).
+		WillReturnRows(sqlmock.NewRows([]string{"filename", "media_type", "size"}).AddRow("upload.png", mediaType, size))
+}
+
+// publishedImageURL matches the URL of an image published from a staged upload
+type publishedImageURL struct{}
+
+func (publishedImageURL) Match(v driver.Value) bool {
+	url, ok := v.(string)
+	return ok && strings.HasPrefix(url, "https://imgs.example.com/imgs/") && url != "https://imgs.example.com/imgs/upload.png"
+}
+
+// capturedArg matches any string argument and keeps it
+type capturedArg struct {
+	value *string
+}
+
+func (c capturedArg) Match(v driver.Value) bool {
+	s, ok := v.(string)
+	*c.value = s
+	return ok
+}
+
+// A meme is uploaded to the local storage through the gateway: the slot's URL is PUT to the
+// signed /uploads/ endpoint and finalizing publishes it
+func TestLocalStorageUploadSlot(t *testing.T) {
+	t.Chdir(t.TempDir())
+	img := testPNG(t)
+
+	var router http.Handler
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { router.ServeHTTP(w, r) }))
+	defer srv.Close()
+	store := storage.NewLocalStorage(srv.URL, "secret")
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal("Failed to create sqlmock", err)
+	}
+	defer db.Close()
+	gateway, err := NewWithMemeService(NewMemeService(db, GetDebugLogger(), store), config.NewStore(&config.Config{MaxUploadSize: 2000}), rateLimiter.NewRateLimiter(rate.Inf, 1, GetDebugLogger()), GetDebugLogger(), nil, MemCache)
+	if err != nil {
+		t.Fatal("Failed to create server")
+	}
+	router = gateway.Router(RouterOptions{Admin: &auth.Admin{}, Uploads: http.HandlerFunc(store.ServeUpload)})
+
+	var uploadKey string
+	mock.ExpectQuery(`DELETE FROM upload_slot`).WillReturnRows(sqlmock.NewRows([]string{"filename"}))
+	mock.ExpectQuery(`INSERT INTO upload_slot`).
+		WithArgs(capturedArg{&uploadKey}, "image/png", int64(len(img)), uploadSlotTTL.Seconds()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testSlotID))
+	res, err := http.Post(srv.URL+"/api/v1/meme/upload", "application/json", strings.NewReader(fmt.Sprintf(`{"media_type":"image/png","size":%d}`, len(img))))
+	if err != nil {
+		t.Fatal("Failed to create the upload slot", err)
+	}
+	var slot struct {
+		ID        string            `json:"id"`
+		UploadURL string            `json:"upload_url"`
+		Method    string            `json:"method"`
+		Headers   map[string]string `json:"headers"`
+	}
+	json.NewDecoder(res.Body).Decode(&slot)
+	res.Body.Close()
+	if res.StatusCode != http.StatusCreated || !strings.HasPrefix(slot.UploadURL, srv.URL+"/uploads/") {
+		t.Fatalf("Expected an upload slot on the local storage, got %d %+v", res.StatusCode, slot)
+	}
+
+	request, err := http.NewRequest(slot.Method, slot.UploadURL, bytes.NewReader(img))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range slot.Headers {
+		request.Header.Set(name, value)
+	}
+	res, err = http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal("Failed to upload the image", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected the upload to succeed, got %d", res.StatusCode)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT filename, media_type, size\s+FROM upload_slot`).
+		WithArgs(testSlotID).
+		WillReturnRows(sqlmock.NewRows([]string{"filename", "media_type", "size"}).AddRow(uploadKey, "image/png", len(img)))
+	mock.ExpectQuery(`INSERT INTO meme`).
+		WithArgs(sqlmock.AnyArg(), "image/png", "meme", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testMemeID))
+	mock.ExpectExec(`UPDATE upload_slot\s+SET finalized_at = NOW\(\), meme_id = \$2`).
+		WithArgs(testSlotID, testMemeID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	res, err = http.Post(srv.URL+"/api/v1/meme/upload/"+testSlotID+"/finalize", "application/json", strings.NewReader(`{"name":"meme","tags":[]}`))
+	if err != nil {
+		t.Fatal("Failed to finalize the upload", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(res.Body)
+		t.Fatalf("Expected the upload to be finalized, got %d. Body: %s", res.StatusCode, string(body))
+	}
+	images, err := store.ListImages(context.Background())
+	if err != nil || len(images) != 1 || images[0].Size != int64(len(img)) {
+		t.Errorf("Expected the upload to be published as an image, got %v, %v", images, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCreateUploadSlotCleansUpExpiredSlots(t *testing.T) {
+	store := &failingStorage{uploads: map[string][]byte{"expired.png": {}}}
+	service, mock := newTestMemeService(t, store)
+
+	mock.ExpectQuery(`DELETE FROM upload_slot\s+WHERE finalized_at IS NULL AND expires_at < NOW\(\) - INTERVAL '1 day'\s+RETURNING filename`).
+		WillReturnRows(sqlmock.NewRows([]string{"filename"}).AddRow("expired.png"))
+	mock.ExpectQuery(`INSERT INTO upload_slot`).
+		WithArgs(sqlmock.AnyArg(), "image/png", int64(100), uploadSlotTTL.Seconds()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testSlotID))
+
+	resp, err := service.CreateUploadSlot(context.Background(), &pb.CreateUploadSlotRequest{MediaType: "image/png", Size: 100})
+	if err != nil {
+		t.Fatal("Creating the upload slot should succeed", err)
+	}
+	if !strings.HasPrefix(resp.UploadUrl, "https://bucket.example.com/uploads/") {
+		t.Errorf("The upload should be staged outside of the images, got %s", resp.UploadUrl)
+	}
+	if _, ok := store.uploads["expired.png"]; ok {
+		t.Error("The upload of the expired slot should be deleted")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFinalizeUploadCreatesMeme(t *testing.T) {
+	img := testPNG(t)
+	store := &failingStorage{uploads: map[string][]byte{"upload.png": img}}
+	service, mock := newTestMemeService(t, store)
+
+	expectUploadSlot(mock, "image/png", len(img))
+	mock.ExpectQuery(`INSERT INTO meme`).
+		WithArgs(publishedImageURL{}, "image/png", "meme", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testMemeID))
+	mock.ExpectExec(`UPDATE upload_slot\s+SET finalized_at = NOW\(\), meme_id = \$2`).
+		WithArgs(testSlotID, testMemeID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	resp, err := service.FinalizeUpload(context.Background(), &pb.FinalizeUploadRequest{SlotId: testSlotID, Name: "meme"})
+	if err != nil {
+		t.Fatal("Finalize should succeed", err)
+	}
+	if resp.Id != testMemeID || len(resp.Dimensions) != 2 || resp.Dimensions[0] != 4 || resp.Dimensions[1] != 3 {
+		t.Errorf("Unexpected meme %v", resp)
+	}
+	if !(publishedImageURL{}).Match(resp.MediaUrl) || len(store.images) != 1 {
+		t.Errorf("The upload should be published to a new image, got %s", resp.MediaUrl)
+	}
+	if len(store.uploads) != 0 || store.called("SoftDeleteImage") {
+		t.Errorf("Only the staged upload should be removed, calls: %v", store.calls)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFinalizeUploadRejectsMismatchedImage(t *testing.T) {
+	img := testPNG(t)
+	tests := []struct {
+		name      string
+		mediaType string
+		size      int
+	}{
+		{name: "wrong media type", mediaType: "image/jpeg", size: len(img)},
+		{name: "wrong size", mediaType: "image/png", size: len(img) + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &failingStorage{uploads: map[string][]byte{"upload.png": img}}
+			service, mock := newTestMemeService(t, store)
+
+			expectUploadSlot(mock, tt.mediaType, tt.size)
+			mock.ExpectExec(`UPDATE upload_slot SET finalized_at = NOW\(\)`).
+				WithArgs(testSlotID).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+
+			_, err := service.FinalizeUpload(context.Background(), &pb.FinalizeUploadRequest{SlotId: testSlotID, Name: "meme"})
+			if status.Code(err) != codes.InvalidArgument {
+				t.Fatal("Expected InvalidArgument, got", err)
+			}
+			if !store.called("SoftDeleteImage") {
+				t.Error("The rejected upload should be discarded")
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestFinalizeUploadDiscardsImageOnError(t *testing.T) {
+	img := testPNG(t)
+	store := &failingStorage{uploads: map[string][]byte{"upload.png": img}}
+	service, mock := newTestMemeService(t, store)
+
+	expectUploadSlot(mock, "image/png", len(img))
+	mock.ExpectQuery(`INSERT INTO meme`).WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	_, err := service.FinalizeUpload(context.Background(), &pb.FinalizeUploadRequest{SlotId: testSlotID, Name: "meme"})
+	if status.Code(err) != codes.Internal {
+		t.Fatal("Expected Internal, got", err)
+	}
+	if !store.called("SoftDeleteImage") {
+		t.Error("The published image of a failed finalize should be discarded")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFinalizeUploadBeforeImageIsUploaded(t *testing.T) {
+	store := &failingStorage{}
+	service, mock := newTestMemeService(t, store)
+
+	expectUploadSlot(mock, "image/png", 100)
+	mock.ExpectRollback()
+
+	_, err := service.FinalizeUpload(context.Background(), &pb.FinalizeUploadRequest{SlotId: testSlotID, Name: "meme"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatal("Expected FailedPrecondition, got", err)
+	}
+	if store.called("SoftDeleteImage") {
+		t.Error("The slot should stay usable until the image is uploaded")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCreateUploadSlotHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{name: "valid", body: `{"media_type":"image/png","size":1000}`, expectedStatus: http.StatusCreated},
+		{name: "too big", body: `{"media_type":"image/png","size":3000}`, expectedStatus: http.StatusRequestEntityTooLarge},
+		{name: "missing size", body: `{"media_type":"image/png"}`, expectedStatus: http.StatusBadRequest},
+		{name: "unsupported type", body: `{"media_type":"image/webp","size":1000}`, expectedStatus: http.StatusBadRequest},
+	}
+	client := &MockMemeService{
+		CreateUploadSlotFunc: func(ctx context.Context, in *pb.CreateUploadSlotRequest) (*pb.UploadSlotResponse, error) {
+			if in.MediaType != "image/png" {
+				return nil, status.Error(codes.InvalidArgument, "Unsupported media type")
+			}
+			return &pb.UploadSlotResponse{Id: testSlotID, UploadUrl: "https://bucket.example.com/imgs/upload.png", Method: http.MethodPut}, nil
+		},
+	}
+	server, err := NewWithMemeService(client, config.NewStore(&config.Config{MaxUploadSize: 2000}), nil, GetDebugLogger(), nil, MemCache)
+	if err != nil {
+		t.Fatal("Failed to create server")
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/api/meme/upload", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			server.CreateUploadSlot(w, request)
+			res := w.Result()
+			if res.StatusCode != tt.expectedStatus {
+				body, _ := io.ReadAll(res.Body)
+				t.Fatalf("Expected status %d, got %d. Body: %s", tt.expectedStatus, res.StatusCode, string(body))
+			}
+			if res.StatusCode == http.StatusCreated {
+				var slot map[string]interface{}
+				json.NewDecoder(res.Body).Decode(&slot)
+				if slot["id"] != testSlotID || slot["upload_url"] == "" {
+					t.Errorf("Unexpected upload slot %v", slot)
+				}
+			}
+		})
+	}
+}
+
+func TestFinalizeUploadHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "finalized", expectedStatus: http.StatusOK},
+		{name: "expired", err: status.Error(codes.NotFound, "Upload slot not found or expired"), expectedStatus: http.StatusNotFound},
+		{name: "not uploaded", err: status.Error(codes.FailedPrecondition, "The image hasn't been uploaded yet"), expectedStatus: http.StatusConflict},
+		{name: "invalid image", err: status.Error(codes.InvalidArgument, "Invalid upload"), expectedStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &MockMemeService{
+				FinalizeUploadFunc: func(ctx context.Context, in *pb.FinalizeUploadRequest) (*pb.MemeResponse, error) {
+					if in.SlotId != testSlotID || in.Name != "meme" {
+						t.Errorf("Unexpected finalize request %v", in)
+					}
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return &pb.MemeResponse{Id: testMemeID}, nil
+				},
+			}
+			server, err := NewWithMemeService(client, nil, nil, GetDebugLogger(), nil, MemCache)
+			if err != nil {
+				t.Fatal("Failed to create server")
+			}
+			request := httptest.NewRequest(http.MethodPost, "/api/meme/upload/"+testSlotID+"/finalize", strings.NewReader(`{"name":"meme","tags":["funny"]}`))
+			request.SetPathValue("id", testSlotID)
+			w := httptest.NewRecorder()
+			server.FinalizeUpload(w, request)
+			if res := w.Result(); res.StatusCode != tt.expectedStatus {
+				body, _ := io.ReadAll(res.Body)
+				t.Errorf("Expected status %d, got %d. Body: %s", tt.expectedStatus, res.StatusCode, string(body))
+			}
+		})
+	}
+}
@@ -1,56 +1,84 @@
 package server
 
 import (
-	"net/url"
-	"strings"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
 
-	"github.com/BassemHalim/memesHub/internal/utils"
-)
+	"go.opentelemetry.io/otel/attribute"
 
-// returns true if the provided url is whitelisted to download from
-func isWhitelisted(domain *url.URL, whitelisted_domains []string) bool {
+	"github.com/BassemHalim/memesHub/internal/apierror"
+	"github.com/BassemHalim/memesHub/internal/fetcher"
+	"github.com/BassemHalim/memesHub/internal/tracing"
+)
 
-	host := domain.Host
-	for _, whitelisted := range whitelisted_domains {
-		if strings.HasSuffix(host, whitelisted) {
-			return true
+// fetchImage downloads the image at memeURL through the SSRF hardened fetcher, resolving social
+// media post URLs to their image. It writes the error response and returns nil when the image
+// can't be used.
+func (s *Server) fetchImage(w http.ResponseWriter, r *http.Request, memeURL string) *fetcher.Image {
+	ctx, span := tracing.Start(r.Context(), "fetchImage", attribute.String("url.full", memeURL))
+	fetched, err := s.fetcher.FetchMeme(ctx, memeURL)
+	tracing.End(span, err)
+	if err != nil {
+		s.log.ErrorContext(r.Context(), "Failed to fetch meme url", "URL", memeURL, "IP", r.RemoteAddr, "ERROR", err)
+		switch {
+		case errors.Is(err, fetcher.ErrTooLarge):
+			apierror.Write(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("Uploaded file is too big it must be <= %d", s.config.Current().MaxUploadSize), nil)
+		case errors.Is(err, fetcher.ErrInvalidURL), errors.Is(err, fetcher.ErrDomainNotAllowed), errors.Is(err, fetcher.ErrForbiddenAddress):
+			apierror.Write(w, r, http.StatusBadRequest, "Invalid media URL, only https URLs from whitelisted domains are allowed", nil)
+		case errors.Is(err, fetcher.ErrNotImage):
+			apierror.Write(w, r, http.StatusBadRequest, "Invalid media type: the URL is not an image", nil)
+		case errors.Is(err, fetcher.ErrNoMedia):
+			apierror.Write(w, r, http.StatusBadRequest, "Couldn't find an image in the post", nil)
+		default:
+			apierror.Write(w, r, http.StatusBadRequest, "Error downloading the image from the provided URL", nil)
 		}
+		return nil
 	}
-	return false
+	return fetched
 }
 
-/*
-validates provided url to make sure it is
-1. a valid url
-2. uses https
-3. is from a whitelisted domain
-4. file size is < MAX_FILE_SIZE (uses a HEAD request not GET)
-5. file mime type is image
-*/
-func (s *Server) ValidateUploadURL(memeURL string) bool {
-	s.log.Debug("Validating Upload URL")
-	parsed, err := url.Parse(memeURL)
-	if err != nil {
-		s.log.Debug("Badly formatted URL")
+// multipartOverhead is the room an upload request has on top of the image for the meme field
+// and the part headers
+const multipartOverhead = 1 << 20
+
+// errUploadTooLarge is returned by readUploadedImage when the image is over the upload limit
+var errUploadTooLarge = errors.New("uploaded image is too large")
+
+// parseUploadForm parses the multipart form of an upload, reading at most maxUploadSize bytes of
+// image so a too large request is rejected while it is read instead of once it is buffered.
+// It writes the error response and returns false when the form can't be used.
+func (s *Server) parseUploadForm(w http.ResponseWriter, r *http.Request, maxUploadSize int64) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize+multipartOverhead)
+	err := r.ParseMultipartForm(maxUploadSize)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		s.handleError(w, r, err, fmt.Sprintf("Uploaded file is too big it must be <= %d", maxUploadSize), http.StatusRequestEntityTooLarge)
 		return false
-	}
-	if parsed.Scheme != "https" {
-		s.log.Debug("insecure connection HTTP")
-		return false
-	}
-	if !isWhitelisted(parsed, s.config.WhitelistedDomains) {
-		s.log.Debug("Not whitelisted", "URL", parsed)
+	case err != nil:
+		s.handleError(w, r, err, "Error parsing the multipart request", http.StatusBadRequest)
 		return false
 	}
+	return true
+}
 
-	ok, err := utils.ValidateImageContent(memeURL, s.client)
-	if err != nil { // TODO: fixme
-		s.log.Error("Failed to run HEAD request but will still proceed with GET", "Error", err, "URL", memeURL) // some websites block HEAD requests
-		return true
+// readUploadedImage reads the "image" part of the form, failing with errUploadTooLarge as soon
+// as it is over maxUploadSize bytes
+func readUploadedImage(r *http.Request, maxUploadSize int64) ([]byte, error) {
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		return nil, err
 	}
-	if !ok {
-		s.log.Debug("Invalid file content")
-		return false
+	defer file.Close()
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(io.LimitReader(file, maxUploadSize+1)); err != nil {
+		return nil, err
 	}
-	return true
+	if int64(buf.Len()) > maxUploadSize {
+		return nil, errUploadTooLarge
+	}
+	return buf.Bytes(), nil
 }
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/BassemHalim/memesHub/internal/metrics"
+	"github.com/BassemHalim/memesHub/internal/tracing"
+)
+
+// instrumented traces every operation of a Storage and records its latency and errors
+type instrumented struct {
+	Storage
+}
+
+// Instrument wraps s so its operations are traced and exported as metrics
+func Instrument(s Storage) Storage {
+	return &instrumented{Storage: s}
+}
+
+// start starts the span of an operation, the returned function ends it with the operation's error
+func (i *instrumented) start(ctx context.Context, operation string, filename string) (context.Context, func(error)) {
+	start := time.Now()
+	var attrs []attribute.KeyValue
+	if filename != "" {
+		attrs = append(attrs, attribute.String("storage.filename", filename))
+	}
+	ctx, span := tracing.Start(ctx, "storage."+operation, attrs...)
+	return ctx, func(err error) {
+		tracing.End(span, err)
+		metrics.ObserveStorage(operation, start, err)
+	}
+}
+
+func (i *instrumented) SaveImage(ctx context.Context, filename string, image io.Reader) (info ImageInfo, err error) {
+	ctx, end := i.start(ctx, "SaveImage", filename)
+	defer func() {
+		trace.SpanFromContext(ctx).SetAttributes(attribute.Int64("storage.size", info.Size))
+		end(err)
+	}()
+	return i.Storage.SaveImage(ctx, filename, image)
+}
+
+func (i *instrumented) SoftDeleteImage(ctx context.Context, filename string) (err error) {
+	ctx, end := i.start(ctx, "SoftDeleteImage", filename)
+	defer func() { end(err) }()
+	return i.Storage.SoftDeleteImage(ctx, filename)
+}
+
+func (i *instrumented) RestoreImage(ctx context.Context, filename string) (url string, err error) {
+	ctx, end := i.start(ctx, "RestoreImage", filename)
+	defer func() { end(err) }()
+	return i.Storage.RestoreImage(ctx, filename)
+}
+
+func (i *instrumented) PurgeImage(ctx context.Context, filename string) (err error) {
+	ctx, end := i.start(ctx, "PurgeImage", filename)
+	defer func() { end(err) }()
+	return i.Storage.PurgeImage(ctx, filename)
+}
+
+func (i *instrumented) RenameImage(ctx context.Context, oldFilename string, newFilename string) (url string, err error) {
+	ctx, end := i.start(ctx, "RenameImage", oldFilename)
+	defer func() { end(err) }()
+	return i.Storage.RenameImage(ctx, oldFilename, newFilename)
+}
+
+func (i *instrumented) ListImages(ctx context.Context) (images []ImageInfo, err error) {
+	ctx, end := i.start(ctx, "ListImages", "")
+	defer func() { end(err) }()
+	return i.Storage.ListImages(ctx)
+}
+
+// OpenImage only measures opening the image, not reading it
+func (i *instrumented) OpenImage(ctx context.Context, filename string) (image io.ReadCloser, info ImageInfo, err error) {
+	ctx, end := i.start(ctx, "OpenImage", filename)
+	defer func() { end(err) }()
+	return i.Storage.OpenImage(ctx, filename)
+}
+
+func (i *instrumented) PresignUpload(ctx context.Context, key string, contentType string, size int64, expires time.Duration) (upload PresignedUpload, err error) {
+	ctx, end := i.start(ctx, "PresignUpload", key)
+	defer func() { end(err) }()
+	return i.Storage.PresignUpload(ctx, key, contentType, size, expires)
+}
+
+func (i *instrumented) PublishUpload(ctx context.Context, key string, filename string) (info ImageInfo, err error) {
+	ctx, end := i.start(ctx, "PublishUpload", filename)
+	defer func() { end(err) }()
+	return i.Storage.PublishUpload(ctx, key, filename)
+}
+
+func (i *instrumented) DeleteUpload(ctx context.Context, key string) (err error) {
+	ctx, end := i.start(ctx, "DeleteUpload", key)
+	defer func() { end(err) }()
+	return i.Storage.DeleteUpload(ctx, key)
+}
+
+func (i *instrumented) Ping(ctx context.Context) (err error) {
+	ctx, end := i.start(ctx, "Ping", "")
+	defer func() { end(err) }()
+	return i.Storage.Ping(ctx)
+}
@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestInstrumentTracesOperations(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	store := Instrument(&localStorage{directory: t.TempDir(), base_url: testBaseUrl})
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "request")
+	if _, err := store.SaveImage(ctx, "meme.png", strings.NewReader("image")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.RenameImage(ctx, "missing.png", "other.png"); err == nil {
+		t.Fatal("Renaming a missing image should fail")
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("Expected 2 storage spans and the parent, got %d", len(spans))
+	}
+	save, rename := spans[0], spans[1]
+	if save.Name() != "storage.SaveImage" || save.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("The save span should be a child of the request, got %s", save.Name())
+	}
+	if save.Status().Code == codes.Error {
+		t.Error("The save span should not be an error")
+	}
+	if rename.Name() != "storage.RenameImage" || rename.Status().Code != codes.Error {
+		t.Errorf("The failed rename should be recorded as an error, got %s %v", rename.Name(), rename.Status())
+	}
+}
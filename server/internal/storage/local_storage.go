@@ -1,40 +1,90 @@
 package storage
 
 import (
+	"context"
+	"crypto/rand"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-
-	"github.com/BassemHalim/memesHub/internal/utils"
+	"strings"
+	"time"
 )
 
 type localStorage struct {
 	directory string
 	base_url  string
+	// presigned uploads are staged here, outside of the served directory, until published
+	uploads string
+	// key signing the presigned upload URLs served by ServeUpload
+	signingKey []byte
 }
 
-func NewLocalStorage() *localStorage {
-	base_url := utils.GetEnvOrExit("STORAGE_BASE_URL")
+func NewLocalStorage(baseURL string, uploadSigningKey string) *localStorage {
+	// without a configured key upload URLs are only valid until the process restarts
+	signingKey := []byte(uploadSigningKey)
+	if len(signingKey) == 0 {
+		signingKey = make([]byte, 32)
+		rand.Read(signingKey)
+	}
+	directory := uploadDir()
 	return &localStorage{
-		directory: uploadDir(),
-		base_url:  base_url,
+		directory:  directory,
+		base_url:   baseURL,
+		uploads:    filepath.Join(filepath.Dir(directory), "uploads"),
+		signingKey: signingKey,
 	}
 }
 
-func (l *localStorage) SaveImage(filename string, image []byte) (string, error) {
-	filePath := filepath.Join(l.directory, filename)
-	if err := os.MkdirAll(l.directory, 0755); err != nil {
-		return "", fmt.Errorf("error creating memes directory, err:%s", err)
+// Streams the image to a temporary file in the upload dir and moves it in place once complete
+func (l *localStorage) SaveImage(ctx context.Context, filename string, image io.Reader) (ImageInfo, error) {
+	return l.save(ctx, l.directory, filename, image)
+}
+
+// Streams the image to a temporary file in dir and moves it to dir/filename once complete
+func (l *localStorage) save(ctx context.Context, dir string, filename string, image io.Reader) (ImageInfo, error) {
+	filePath := filepath.Join(dir, filename)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return ImageInfo{}, fmt.Errorf("error creating memes directory, err:%s", err)
+	}
+	contentType, image, err := sniffContentType(image)
+	if err != nil {
+		return ImageInfo{}, fmt.Errorf("error reading image err:%s", err)
 	}
 
-	if err := os.WriteFile(filePath, image, 0666); err != nil {
-		return "", fmt.Errorf("error saving image to disk err:%s", err)
+	tmp, err := os.CreateTemp(dir, ".upload-*")
+	if err != nil {
+		return ImageInfo{}, fmt.Errorf("error saving image to disk err:%s", err)
 	}
-	return filePath, nil
+	defer os.Remove(tmp.Name())
+	body := &countingReader{ctx: ctx, r: image}
+	_, err = io.Copy(tmp, body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return ImageInfo{}, fmt.Errorf("error saving image to disk err:%w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0666); err != nil {
+		return ImageInfo{}, fmt.Errorf("error saving image to disk err:%s", err)
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return ImageInfo{}, fmt.Errorf("error saving image to disk err:%s", err)
+	}
+	return ImageInfo{
+		Filename:     filename,
+		URL:          l.ImageUrl(filename),
+		ContentType:  contentType,
+		Size:         body.n,
+		LastModified: time.Now(),
+	}, nil
 }
 
 // Soft deletes the image at {upload dir}/filename by just renaming it to deleted_filename
-func (l *localStorage) SoftDeleteImage(filename string) error {
+func (l *localStorage) SoftDeleteImage(ctx context.Context, filename string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	oldPath := filepath.Join(l.directory, filename)
 	newPath := filepath.Join(l.directory, "deleted_"+filename)
 	if err := os.Rename(oldPath, newPath); err != nil {
@@ -43,7 +93,35 @@ func (l *localStorage) SoftDeleteImage(filename string) error {
 	return nil
 }
 
-func (l *localStorage) RenameImage(oldFilename string, newFilename string) (string, error) {
+// Restores a soft deleted image by renaming deleted_filename back to filename
+func (l *localStorage) RestoreImage(ctx context.Context, filename string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	oldPath := filepath.Join(l.directory, "deleted_"+filename)
+	newPath := filepath.Join(l.directory, filename)
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return "", fmt.Errorf("error restoring image %s", err)
+	}
+	return newPath, nil
+}
+
+// Permanently removes a soft deleted image
+func (l *localStorage) PurgeImage(ctx context.Context, filename string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	path := filepath.Join(l.directory, "deleted_"+filename)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error purging image %s", err)
+	}
+	return nil
+}
+
+func (l *localStorage) RenameImage(ctx context.Context, oldFilename string, newFilename string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
 	dir := l.directory
 	oldPath := filepath.Join(dir, oldFilename)
 	newPath := filepath.Join(dir, newFilename)
@@ -53,6 +131,49 @@ func (l *localStorage) RenameImage(oldFilename string, newFilename string) (stri
 	return newPath, nil
 }
 
+// Lists the images in the upload dir skipping the soft deleted ones
+func (l *localStorage) ListImages(ctx context.Context) ([]ImageInfo, error) {
+	entries, err := os.ReadDir(l.directory)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error listing images %s", err)
+	}
+	var images []ImageInfo
+	for _, entry := range entries {
+		// unfinished uploads are hidden dot files
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), "deleted_") || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("error listing images %s", err)
+		}
+		images = append(images, ImageInfo{
+			Filename:     entry.Name(),
+			Size:         info.Size(),
+			LastModified: info.ModTime(),
+		})
+	}
+	return images, nil
+}
+
+// Check the upload dir is a directory, a missing one is created by the next upload
+func (l *localStorage) Ping(ctx context.Context) error {
+	info, err := os.Stat(l.directory)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading the upload dir %s", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("the upload dir %s is not a directory", l.directory)
+	}
+	return nil
+}
+
 func (l *localStorage) ImageUrl(filename string) string {
 	filePath := filepath.Join(l.directory, filename)
 	return fmt.Sprintf("%s/%s", l.base_url, filePath)
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalSoftDeleteAndRestore(t *testing.T) {
+	l := &localStorage{directory: t.TempDir(), base_url: testBaseUrl}
+	if _, err := l.SaveImage(context.Background(), "meme.png", strings.NewReader("image")); err != nil {
+		t.Fatal("Failed to save image:", err)
+	}
+	if err := l.SoftDeleteImage(context.Background(), "meme.png"); err != nil {
+		t.Fatal("Failed to soft delete image:", err)
+	}
+	if _, err := os.Stat(filepath.Join(l.directory, "meme.png")); !os.IsNotExist(err) {
+		t.Fatal("Soft deleted image should not exist under its original name")
+	}
+	if _, err := l.RestoreImage(context.Background(), "meme.png"); err != nil {
+		t.Fatal("Failed to restore image:", err)
+	}
+	data, err := os.ReadFile(filepath.Join(l.directory, "meme.png"))
+	if err != nil || string(data) != "image" {
+		t.Fatal("Restored image should have its original content", err)
+	}
+}
+
+func TestLocalPurgeImage(t *testing.T) {
+	l := &localStorage{directory: t.TempDir(), base_url: testBaseUrl}
+	if _, err := l.SaveImage(context.Background(), "meme.png", strings.NewReader("image")); err != nil {
+		t.Fatal("Failed to save image:", err)
+	}
+	if err := l.SoftDeleteImage(context.Background(), "meme.png"); err != nil {
+		t.Fatal("Failed to soft delete image:", err)
+	}
+	if err := l.PurgeImage(context.Background(), "meme.png"); err != nil {
+		t.Fatal("Failed to purge image:", err)
+	}
+	if _, err := os.Stat(filepath.Join(l.directory, "deleted_meme.png")); !os.IsNotExist(err) {
+		t.Fatal("Purged image should be removed from disk")
+	}
+	// purging twice is not an error so the purge job can retry safely
+	if err := l.PurgeImage(context.Background(), "meme.png"); err != nil {
+		t.Fatal("Purging a missing image should not fail:", err)
+	}
+	if _, err := l.RestoreImage(context.Background(), "meme.png"); err == nil {
+		t.Fatal("Restoring a purged image should fail")
+	}
+}
+
+func TestLocalListImagesSkipsDeleted(t *testing.T) {
+	l := &localStorage{directory: t.TempDir(), base_url: testBaseUrl}
+	for _, name := range []string{"a.png", "b.png"} {
+		if _, err := l.SaveImage(context.Background(), name, strings.NewReader("image")); err != nil {
+			t.Fatal("Failed to save image:", err)
+		}
+	}
+	if err := l.SoftDeleteImage(context.Background(), "b.png"); err != nil {
+		t.Fatal("Failed to soft delete image:", err)
+	}
+	images, err := l.ListImages(context.Background())
+	if err != nil {
+		t.Fatal("Failed to list images:", err)
+	}
+	if len(images) != 1 || images[0].Filename != "a.png" || images[0].Size != 5 {
+		t.Fatalf("Expected only a.png to be listed, got %+v", images)
+	}
+}
+
+func TestLocalSaveImageStreams(t *testing.T) {
+	l := &localStorage{directory: t.TempDir(), base_url: testBaseUrl}
+	gif := "GIF89a" + strings.Repeat("x", 4096)
+	info, err := l.SaveImage(context.Background(), "meme.gif", strings.NewReader(gif))
+	if err != nil {
+		t.Fatal("Failed to save image:", err)
+	}
+	if info.Size != int64(len(gif)) || info.ContentType != "image/gif" || info.URL != l.ImageUrl("meme.gif") {
+		t.Fatalf("Unexpected image info %+v", info)
+	}
+	data, err := os.ReadFile(filepath.Join(l.directory, "meme.gif"))
+	if err != nil || string(data) != gif {
+		t.Fatal("Saved image should have the streamed content", err)
+	}
+}
+
+func TestLocalSaveImageCancelled(t *testing.T) {
+	l := &localStorage{directory: t.TempDir(), base_url: testBaseUrl}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := l.SaveImage(ctx, "meme.png", strings.NewReader("image"))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatal("Expected the upload to be cancelled, got", err)
+	}
+	images, err := l.ListImages(context.Background())
+	if err != nil || len(images) != 0 {
+		t.Fatalf("A cancelled upload should leave nothing behind, got %+v %v", images, err)
+	}
+	entries, _ := os.ReadDir(l.directory)
+	if len(entries) != 0 {
+		t.Fatalf("The temporary upload file should be removed, got %d entries", len(entries))
+	}
+}
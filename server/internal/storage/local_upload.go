@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BassemHalim/memesHub/internal/apierror"
+)
+
+// Presigned uploads to the local storage are PUT /uploads/{key} requests whose query carries
+// the upload constraints and an HMAC of them. ServeUpload checks the signature and constraints
+// before streaming the body to the staging dir, PublishUpload then moves it to the upload dir.
+
+// Open an image in the upload dir for reading
+func (l *localStorage) OpenImage(ctx context.Context, filename string) (io.ReadCloser, ImageInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, ImageInfo{}, err
+	}
+	file, err := os.Open(filepath.Join(l.directory, filename))
+	if err != nil {
+		return nil, ImageInfo{}, fmt.Errorf("error opening image %w", err)
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, ImageInfo{}, fmt.Errorf("error opening image %w", err)
+	}
+	return file, ImageInfo{
+		Filename:     filename,
+		Size:         stat.Size(),
+		LastModified: stat.ModTime(),
+	}, nil
+}
+
+func (l *localStorage) uploadSignature(key string, contentType string, size int64, expires int64) string {
+	mac := hmac.New(sha256.New, l.signingKey)
+	fmt.Fprintf(mac, "%s\n%s\n%d\n%d", key, contentType, size, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (l *localStorage) PresignUpload(ctx context.Context, key string, contentType string, size int64, expires time.Duration) (PresignedUpload, error) {
+	if err := ctx.Err(); err != nil {
+		return PresignedUpload{}, err
+	}
+	expiresAt := time.Now().Add(expires)
+	query := url.Values{}
+	query.Set("content_type", contentType)
+	query.Set("size", strconv.FormatInt(size, 10))
+	query.Set("expires", strconv.FormatInt(expiresAt.Unix(), 10))
+	query.Set("signature", l.uploadSignature(key, contentType, size, expiresAt.Unix()))
+	return PresignedUpload{
+		URL:       fmt.Sprintf("%s/uploads/%s?%s", l.base_url, url.PathEscape(key), query.Encode()),
+		Method:    http.MethodPut,
+		Headers:   map[string]string{"Content-Type": contentType},
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// PUT /uploads/{key}
+// ServeUpload stages the body of a request created by PresignUpload
+func (l *localStorage) ServeUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		apierror.Write(w, r, http.StatusMethodNotAllowed, "Method not allowed", nil)
+		return
+	}
+	key := path.Base(r.URL.Path)
+	if key == "." || key == "/" || strings.HasPrefix(key, ".") {
+		apierror.Write(w, r, http.StatusBadRequest, "Invalid upload key", nil)
+		return
+	}
+	query := r.URL.Query()
+	contentType := query.Get("content_type")
+	size, err := strconv.ParseInt(query.Get("size"), 10, 64)
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, "Invalid upload size", nil)
+		return
+	}
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, "Invalid upload expiry", nil)
+		return
+	}
+	expected := l.uploadSignature(key, contentType, size, expires)
+	if !hmac.Equal([]byte(expected), []byte(query.Get("signature"))) {
+		apierror.Write(w, r, http.StatusForbidden, "Invalid signature", nil)
+		return
+	}
+	if time.Now().Unix() > expires {
+		apierror.Write(w, r, http.StatusForbidden, "Upload URL expired", nil)
+		return
+	}
+	if r.Header.Get("Content-Type") != contentType {
+		apierror.Write(w, r, http.StatusBadRequest, "Content-Type doesn't match the signed upload", nil)
+		return
+	}
+	if r.ContentLength != size {
+		apierror.Write(w, r, http.StatusBadRequest, "Content-Length doesn't match the signed upload", nil)
+		return
+	}
+
+	info, err := l.save(r.Context(), l.uploads, key, http.MaxBytesReader(w, r.Body, size))
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, "Failed to save the image", nil)
+		return
+	}
+	if info.Size != size {
+		os.Remove(filepath.Join(l.uploads, key))
+		apierror.Write(w, r, http.StatusBadRequest, "Body doesn't match the signed upload size", nil)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Move a staged upload to a new image in the upload dir
+func (l *localStorage) PublishUpload(ctx context.Context, key string, filename string) (ImageInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return ImageInfo{}, err
+	}
+	if err := os.MkdirAll(l.directory, 0755); err != nil {
+		return ImageInfo{}, fmt.Errorf("error creating memes directory, err:%s", err)
+	}
+	filePath := filepath.Join(l.directory, filename)
+	if err := os.Rename(filepath.Join(l.uploads, key), filePath); err != nil {
+		return ImageInfo{}, fmt.Errorf("error publishing upload %w", err)
+	}
+	stat, err := os.Stat(filePath)
+	if err != nil {
+		return ImageInfo{}, fmt.Errorf("error publishing upload %w", err)
+	}
+	return ImageInfo{
+		Filename:     filename,
+		URL:          l.ImageUrl(filename),
+		Size:         stat.Size(),
+		LastModified: stat.ModTime(),
+	}, nil
+}
+
+// Delete a staged upload, deleting a missing key succeeds
+func (l *localStorage) DeleteUpload(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(l.uploads, key)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error deleting upload %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalOpenImage(t *testing.T) {
+	l := &localStorage{directory: t.TempDir(), base_url: testBaseUrl}
+	gif := "GIF89a" + strings.Repeat("x", 100)
+	if _, err := l.SaveImage(context.Background(), "meme.gif", strings.NewReader(gif)); err != nil {
+		t.Fatal("Failed to save image:", err)
+	}
+
+	body, info, err := l.OpenImage(context.Background(), "meme.gif")
+	if err != nil {
+		t.Fatal("Failed to open image:", err)
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatal("Failed to read image:", err)
+	}
+	if string(data) != gif || info.Size != int64(len(gif)) {
+		t.Errorf("Expected the %d saved bytes, got %d", len(gif), info.Size)
+	}
+
+	if _, _, err := l.OpenImage(context.Background(), "missing.gif"); err == nil {
+		t.Error("Opening a missing image should fail")
+	}
+}
+
+func TestLocalPresignedUpload(t *testing.T) {
+	gif := "GIF89a" + strings.Repeat("x", 100)
+	tests := []struct {
+		name           string
+		body           string
+		contentType    string
+		expires        time.Duration
+		tamper         func(query url.Values)
+		expectedStatus int
+	}{
+		{name: "valid", body: gif, contentType: "image/gif", expires: time.Minute, expectedStatus: http.StatusOK},
+		{name: "expired", body: gif, contentType: "image/gif", expires: -time.Minute, expectedStatus: http.StatusForbidden},
+		{name: "tampered size", body: gif + "x", contentType: "image/gif", expires: time.Minute, tamper: func(q url.Values) { q.Set("size", "107") }, expectedStatus: http.StatusForbidden},
+		{name: "wrong size", body: gif + "x", contentType: "image/gif", expires: time.Minute, expectedStatus: http.StatusBadRequest},
+		{name: "wrong content type", body: gif, contentType: "image/png", expires: time.Minute, expectedStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			l := &localStorage{directory: filepath.Join(dir, "images"), base_url: testBaseUrl, uploads: filepath.Join(dir, "uploads"), signingKey: []byte("secret")}
+			upload, err := l.PresignUpload(context.Background(), "key.gif", "image/gif", int64(len(gif)), tt.expires)
+			if err != nil {
+				t.Fatal("Failed to presign upload:", err)
+			}
+			uploadURL, err := url.Parse(upload.URL)
+			if err != nil {
+				t.Fatal("Invalid upload URL:", err)
+			}
+			if tt.tamper != nil {
+				query := uploadURL.Query()
+				tt.tamper(query)
+				uploadURL.RawQuery = query.Encode()
+			}
+
+			request := httptest.NewRequest(upload.Method, uploadURL.RequestURI(), strings.NewReader(tt.body))
+			request.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			l.ServeUpload(w, request)
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+
+			_, err = os.Stat(filepath.Join(l.uploads, "key.gif"))
+			if uploaded := err == nil; uploaded != (tt.expectedStatus == http.StatusOK) {
+				t.Fatalf("Upload should only be staged by a valid request, staged: %v", uploaded)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			if images, _ := l.ListImages(context.Background()); len(images) != 0 {
+				t.Fatalf("A staged upload shouldn't be an image before it is published, got %v", images)
+			}
+			info, err := l.PublishUpload(context.Background(), "key.gif", "meme.gif")
+			if err != nil {
+				t.Fatal("Failed to publish upload:", err)
+			}
+			if info.Size != int64(len(gif)) || info.URL != l.ImageUrl("meme.gif") {
+				t.Errorf("Unexpected published image %+v", info)
+			}
+			body, _, err := l.OpenImage(context.Background(), "meme.gif")
+			if err != nil {
+				t.Fatal("Failed to open published image:", err)
+			}
+			body.Close()
+			if _, err := os.Stat(filepath.Join(l.uploads, "key.gif")); !os.IsNotExist(err) {
+				t.Error("The staged upload should be moved by publishing it")
+			}
+		})
+	}
+}
+
+func TestLocalDeleteUpload(t *testing.T) {
+	dir := t.TempDir()
+	l := &localStorage{directory: filepath.Join(dir, "images"), base_url: testBaseUrl, uploads: filepath.Join(dir, "uploads")}
+	if _, err := l.save(context.Background(), l.uploads, "key.gif", strings.NewReader("GIF89a")); err != nil {
+		t.Fatal("Failed to stage upload:", err)
+	}
+	if err := l.DeleteUpload(context.Background(), "key.gif"); err != nil {
+		t.Fatal("Failed to delete upload:", err)
+	}
+	if _, err := os.Stat(filepath.Join(l.uploads, "key.gif")); !os.IsNotExist(err) {
+		t.Error("The staged upload should be deleted")
+	}
+	if err := l.DeleteUpload(context.Background(), "key.gif"); err != nil {
+		t.Error("Deleting a missing upload should succeed, got", err)
+	}
+}
@@ -1,80 +1,99 @@
 package storage
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
-	"net/http"
+	"io"
 	"os"
+	"strings"
+	"time"
 
 	"log/slog"
 
-	"github.com/BassemHalim/memesHub/internal/utils"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 )
 
+// uploads are sent in parts of this size, one at a time, so an upload holds at most one part in memory
+const uploadPartSize = manager.MinUploadPartSize
+
 type R2 struct {
 	Bucket   *string
 	s3Client *s3.Client
+	uploader *manager.Uploader
 	base_url string
 	log      *slog.Logger
 }
 
-func NewR2(bucket string, log *slog.Logger) *R2 {
-	accessKeyId := utils.GetEnvOrExit("R2_ACCESS_KEY_ID")
-	accessKeySecret := utils.GetEnvOrExit("R2_ACCESS_KEY_SECRET")
-	accountId := utils.GetEnvOrExit("R2_ACCOUNT_ID")
-	base_url := utils.GetEnvOrExit("STORAGE_BASE_URL")
+type R2Options struct {
+	Bucket          string
+	AccountID       string
+	AccessKeyID     string
+	AccessKeySecret string
+	// public URL the images are served from
+	BaseURL string
+}
 
+func NewR2(opts R2Options, log *slog.Logger) *R2 {
 	cfg, err := config.LoadDefaultConfig(context.TODO(),
-		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKeyId, accessKeySecret, "")),
+		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(opts.AccessKeyID, opts.AccessKeySecret, "")),
 		config.WithRegion("auto"),
 	)
 	if err != nil {
 		log.Error(err.Error())
 		os.Exit(1)
 	}
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.BaseEndpoint = aws.String(fmt.Sprintf("https://%s.r2.cloudflarestorage.com", opts.AccountID))
+	})
 	return &R2{
-		Bucket: aws.String(bucket),
-		s3Client: s3.NewFromConfig(cfg, func(o *s3.Options) {
-			o.BaseEndpoint = aws.String(fmt.Sprintf("https://%s.r2.cloudflarestorage.com", accountId))
+		Bucket:   aws.String(opts.Bucket),
+		s3Client: client,
+		uploader: manager.NewUploader(client, func(u *manager.Uploader) {
+			u.PartSize = uploadPartSize
+			u.Concurrency = 1
 		}),
 		log:      log,
-		base_url: base_url,
+		base_url: opts.BaseURL,
 	}
 }
 
-// Saves the images in r2 bucket under "imgs/" prefix and returns the public URL
-func (r *R2) SaveImage(filename string, image []byte) (string, error) {
-	// return unimplemented exception
+// Streams the image to the r2 bucket under "imgs/" prefix, cancelling the upload when ctx is done
+func (r *R2) SaveImage(ctx context.Context, filename string, image io.Reader) (ImageInfo, error) {
 	key := fmt.Sprintf("imgs/%s", filename)
-	contentType := http.DetectContentType(image)
-	if len(contentType) < 6 || contentType[:6] != "image/" {
-		contentType = "image/png"
+	contentType, image, err := sniffContentType(image)
+	if err != nil {
+		return ImageInfo{}, fmt.Errorf("failed to read image: %w", err)
 	}
-	response, err := r.s3Client.PutObject(context.TODO(), &s3.PutObjectInput{
+	body := &countingReader{ctx: ctx, r: image}
+	response, err := r.uploader.Upload(ctx, &s3.PutObjectInput{
 		Bucket:      r.Bucket,
 		Key:         aws.String(key),
-		Body:        bytes.NewReader(image),
+		Body:        body,
 		ContentType: aws.String(contentType),
 	})
 	if err != nil {
-		return "", errors.New("failed to upload image to R2: " + err.Error())
+		return ImageInfo{}, fmt.Errorf("failed to upload image to R2: %w", err)
 	}
-	r.log.Debug("Image uploaded to R2", "Response", response)
-	imageURL := fmt.Sprintf("%s/%s", r.base_url, key)
-	return imageURL, nil
+	r.log.Debug("Image uploaded to R2", "Key", response.Key, "Size", body.n)
+	return ImageInfo{
+		Filename:     filename,
+		URL:          r.ImageUrl(filename),
+		ContentType:  contentType,
+		Size:         body.n,
+		LastModified: time.Now(),
+	}, nil
 }
 
 // Move the image to a different bucket and deletes the original
-func (r *R2) SoftDeleteImage(filename string) error {
+func (r *R2) SoftDeleteImage(ctx context.Context, filename string) error {
 	// move the file to the  trash bucket
 	key := fmt.Sprintf("imgs/%s", filename)
-	resp, err := r.s3Client.CopyObject(context.TODO(), &s3.CopyObjectInput{
+	resp, err := r.s3Client.CopyObject(ctx, &s3.CopyObjectInput{
 		Bucket:     aws.String(*r.Bucket + "-trash"),
 		CopySource: aws.String(*r.Bucket + "/" + key),
 		Key:        aws.String(key),
@@ -84,7 +103,7 @@ func (r *R2) SoftDeleteImage(filename string) error {
 	}
 	r.log.Debug("Image soft deleted in R2", "Response", resp)
 	// delete the original file
-	_, err = r.s3Client.DeleteObject(context.TODO(), &s3.DeleteObjectInput{
+	_, err = r.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
 		Bucket: r.Bucket,
 		Key:    aws.String(key),
 	})
@@ -94,13 +113,50 @@ func (r *R2) SoftDeleteImage(filename string) error {
 	return nil
 }
 
+// Move a soft deleted image from the trash bucket back to the main bucket
+func (r *R2) RestoreImage(ctx context.Context, filename string) (string, error) {
+	key := fmt.Sprintf("imgs/%s", filename)
+	trash := *r.Bucket + "-trash"
+	_, err := r.s3Client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:     r.Bucket,
+		CopySource: aws.String(trash + "/" + key),
+		Key:        aws.String(key),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to restore image from R2 trash: %w", err)
+	}
+	_, err = r.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(trash),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to delete trash copy after restore in R2: %w", err)
+	}
+	r.log.Debug("Image restored in R2", "Key", key)
+	return r.ImageUrl(filename), nil
+}
+
+// Permanently delete a soft deleted image from the trash bucket
+func (r *R2) PurgeImage(ctx context.Context, filename string) error {
+	key := fmt.Sprintf("imgs/%s", filename)
+	_, err := r.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(*r.Bucket + "-trash"),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to purge image from R2 trash: %w", err)
+	}
+	r.log.Debug("Image purged from R2 trash", "Key", key)
+	return nil
+}
+
 // Rename the image by copying it to a new key and deleting the old one
-func (r *R2) RenameImage(oldFilename string, newFilename string) (string, error) {
+func (r *R2) RenameImage(ctx context.Context, oldFilename string, newFilename string) (string, error) {
 	srcKey := fmt.Sprintf("imgs/%s", oldFilename)
 	dstKey := fmt.Sprintf("imgs/%s", newFilename)
 
 	// Copy the object to the new key
-	_, err := r.s3Client.CopyObject(context.TODO(), &s3.CopyObjectInput{
+	_, err := r.s3Client.CopyObject(ctx, &s3.CopyObjectInput{
 		Bucket:     r.Bucket,
 		CopySource: aws.String(*r.Bucket + "/" + srcKey),
 		Key:        aws.String(dstKey),
@@ -110,7 +166,7 @@ func (r *R2) RenameImage(oldFilename string, newFilename string) (string, error)
 	}
 
 	// Delete the old object
-	_, err = r.s3Client.DeleteObject(context.TODO(), &s3.DeleteObjectInput{
+	_, err = r.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
 		Bucket: r.Bucket,
 		Key:    aws.String(srcKey),
 	})
@@ -124,6 +180,125 @@ func (r *R2) RenameImage(oldFilename string, newFilename string) (string, error)
 
 }
 
+// List every image under the "imgs/" prefix of the main bucket
+func (r *R2) ListImages(ctx context.Context) ([]ImageInfo, error) {
+	var images []ImageInfo
+	paginator := s3.NewListObjectsV2Paginator(r.s3Client, &s3.ListObjectsV2Input{
+		Bucket: r.Bucket,
+		Prefix: aws.String("imgs/"),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list images in R2: %w", err)
+		}
+		for _, object := range page.Contents {
+			filename := strings.TrimPrefix(aws.ToString(object.Key), "imgs/")
+			if filename == "" {
+				continue
+			}
+			images = append(images, ImageInfo{
+				Filename:     filename,
+				Size:         aws.ToInt64(object.Size),
+				LastModified: aws.ToTime(object.LastModified),
+			})
+		}
+	}
+	return images, nil
+}
+
+// Open an image in the main bucket for reading
+func (r *R2) OpenImage(ctx context.Context, filename string) (io.ReadCloser, ImageInfo, error) {
+	key := fmt.Sprintf("imgs/%s", filename)
+	object, err := r.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: r.Bucket,
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, ImageInfo{}, fmt.Errorf("failed to open image in R2: %w", err)
+	}
+	return object.Body, ImageInfo{
+		Filename:     filename,
+		ContentType:  aws.ToString(object.ContentType),
+		Size:         aws.ToInt64(object.ContentLength),
+		LastModified: aws.ToTime(object.LastModified),
+	}, nil
+}
+
+// Presign a PUT of the image to the "uploads/" staging prefix of the main bucket, nothing
+// references or serves it until PublishUpload moves it under "imgs/". The content type and
+// length are signed so the bucket rejects any other upload
+func (r *R2) PresignUpload(ctx context.Context, key string, contentType string, size int64, expires time.Duration) (PresignedUpload, error) {
+	key = fmt.Sprintf("uploads/%s", key)
+	request, err := s3.NewPresignClient(r.s3Client).PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket:        r.Bucket,
+		Key:           aws.String(key),
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+	}, s3.WithPresignExpires(expires))
+	if err != nil {
+		return PresignedUpload{}, fmt.Errorf("failed to presign upload to R2: %w", err)
+	}
+	headers := map[string]string{}
+	for name, values := range request.SignedHeader {
+		// the client sets Host and Content-Length itself
+		if name == "Host" || name == "Content-Length" || len(values) == 0 {
+			continue
+		}
+		headers[name] = values[0]
+	}
+	return PresignedUpload{
+		URL:       request.URL,
+		Method:    request.Method,
+		Headers:   headers,
+		ExpiresAt: time.Now().Add(expires),
+	}, nil
+}
+
+// Copy a staged upload to a new image under "imgs/" and delete the staged object, the client
+// can keep uploading to the staging key until its URL expires without touching the image
+func (r *R2) PublishUpload(ctx context.Context, key string, filename string) (ImageInfo, error) {
+	srcKey := fmt.Sprintf("uploads/%s", key)
+	dstKey := fmt.Sprintf("imgs/%s", filename)
+	_, err := r.s3Client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:     r.Bucket,
+		CopySource: aws.String(*r.Bucket + "/" + srcKey),
+		Key:        aws.String(dstKey),
+	})
+	if err != nil {
+		return ImageInfo{}, fmt.Errorf("failed to publish upload in R2: %w", err)
+	}
+	if err := r.DeleteUpload(ctx, key); err != nil {
+		r.log.Warn("Failed to delete published upload", "Key", srcKey, "Error", err)
+	}
+	r.log.Debug("Upload published in R2", "UploadKey", srcKey, "Key", dstKey)
+	return ImageInfo{
+		Filename:     filename,
+		URL:          r.ImageUrl(filename),
+		LastModified: time.Now(),
+	}, nil
+}
+
+// Delete a staged upload, deleting a missing key succeeds
+func (r *R2) DeleteUpload(ctx context.Context, key string) error {
+	_, err := r.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: r.Bucket,
+		Key:    aws.String(fmt.Sprintf("uploads/%s", key)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete upload in R2: %w", err)
+	}
+	return nil
+}
+
+// Check the main bucket exists and the credentials can access it
+func (r *R2) Ping(ctx context.Context) error {
+	if _, err := r.s3Client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: r.Bucket}); err != nil {
+		return fmt.Errorf("failed to reach the R2 bucket: %w", err)
+	}
+	return nil
+}
+
 func (r *R2) ImageUrl(filename string) string {
 	return fmt.Sprintf("%s/imgs/%s", r.base_url, filename)
 }
@@ -1,6 +1,8 @@
 package storage
 
 import (
+	"bytes"
+	"context"
 	"log/slog"
 	"os"
 	"testing"
@@ -10,12 +12,26 @@ var testBaseUrl string
 
 func TestMain(m *testing.M) {
 	testBaseUrl = "https://imgs.qasrelmemez.com"
-	os.Setenv("STORAGE_BASE_URL", testBaseUrl)
 	m.Run()
 }
 
+// newTestR2 connects to the bucket of the R2 credentials in the environment
+func newTestR2(t *testing.T) *R2 {
+	opts := R2Options{
+		Bucket:          "qasrelmemez",
+		AccountID:       os.Getenv("R2_ACCOUNT_ID"),
+		AccessKeyID:     os.Getenv("R2_ACCESS_KEY_ID"),
+		AccessKeySecret: os.Getenv("R2_ACCESS_KEY_SECRET"),
+		BaseURL:         testBaseUrl,
+	}
+	if opts.AccountID == "" || opts.AccessKeyID == "" || opts.AccessKeySecret == "" {
+		t.Skip("R2_ACCOUNT_ID, R2_ACCESS_KEY_ID and R2_ACCESS_KEY_SECRET must be set to test R2")
+	}
+	return NewR2(opts, slog.Default())
+}
+
 func TestImageUrl(t *testing.T) {
-	r2 := NewR2("qasrelmemez", slog.Default())
+	r2 := NewR2(R2Options{Bucket: "qasrelmemez", BaseURL: testBaseUrl}, slog.Default())
 	filename := "test_image.png"
 	expectedUrl := testBaseUrl + "/imgs/" + filename
 	url := r2.ImageUrl(filename)
@@ -24,7 +40,7 @@ func TestImageUrl(t *testing.T) {
 	}
 }
 func TestSaveImage(t *testing.T) {
-	r2 := NewR2("qasrelmemez", slog.Default())
+	r2 := newTestR2(t)
 	// small red dot image
 	image := []byte{
 		0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00,
@@ -33,10 +49,11 @@ func TestSaveImage(t *testing.T) {
 		0x00, 0x3B,
 	}
 
-	url, err := r2.SaveImage("test.png", image)
+	info, err := r2.SaveImage(context.Background(), "test.png", bytes.NewReader(image))
 	if err != nil {
 		t.Fatal("Failed to save image to R2:", err)
 	}
+	url := info.URL
 	// accessKeyId := utils.GetEnvOrExit("R2_ACCESS_KEY_ID")
 	// accessKeySecret := utils.GetEnvOrExit("R2_ACCESS_KEY_SECRET")
 	// accountId := utils.GetEnvOrExit("R2_ACCOUNT_ID")
@@ -49,10 +66,10 @@ func TestSaveImage(t *testing.T) {
 }
 
 func TestRenameImage(t *testing.T) {
-	r2 := NewR2("qasrelmemez", slog.Default())
+	r2 := newTestR2(t)
 	oldKey := "test.png"
 	newKey := "renamed_test.png"
-	url, err := r2.RenameImage(oldKey, newKey)
+	url, err := r2.RenameImage(context.Background(), oldKey, newKey)
 	if err != nil {
 		t.Fatal("Failed to rename image in R2:", err)
 	}
@@ -63,9 +80,9 @@ func TestRenameImage(t *testing.T) {
 }
 
 func TestDeleteImage(t *testing.T) {
-	r2 := NewR2("qasrelmemez", slog.Default())
+	r2 := newTestR2(t)
 	key := "renamed_test.png"
-	err := r2.SoftDeleteImage(key)
+	err := r2.SoftDeleteImage(context.Background(), key)
 	if err != nil {
 		t.Fatal("Failed to delete image from R2:", err)
 	}
@@ -1,8 +1,83 @@
 package storage
 
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ImageInfo describes a stored image. URL is only set by SaveImage and ContentType
+// is empty when the backend doesn't keep it
+type ImageInfo struct {
+	Filename     string
+	URL          string
+	ContentType  string
+	Size         int64
+	LastModified time.Time
+}
+
+// PresignedUpload is a request a client can send to upload an image straight to storage
+type PresignedUpload struct {
+	URL    string
+	Method string
+	// headers the request must send unchanged since they are part of the signature
+	Headers   map[string]string
+	ExpiresAt time.Time
+}
+
+// Every method honours ctx so a request timeout also cancels the storage call.
 type Storage interface {
-	SaveImage(filename string, image []byte) (string,error)
-	SoftDeleteImage(filename string) error
-	RenameImage(oldFilename string, newFilename string) (string, error)
+	// SaveImage streams image into filename without buffering it whole in memory
+	SaveImage(ctx context.Context, filename string, image io.Reader) (ImageInfo, error)
+	SoftDeleteImage(ctx context.Context, filename string) error
+	RestoreImage(ctx context.Context, filename string) (string, error)
+	PurgeImage(ctx context.Context, filename string) error
+	RenameImage(ctx context.Context, oldFilename string, newFilename string) (string, error)
 	ImageUrl(filename string) string
+	// ListImages lists the live images, soft deleted images are not included
+	ListImages(ctx context.Context) ([]ImageInfo, error)
+	// OpenImage opens a live image for reading, the caller must close it
+	OpenImage(ctx context.Context, filename string) (io.ReadCloser, ImageInfo, error)
+	// PresignUpload returns a request that uploads exactly size bytes of contentType to the staging
+	// key until expires. Staged uploads aren't live images, they are only served once published
+	PresignUpload(ctx context.Context, key string, contentType string, size int64, expires time.Duration) (PresignedUpload, error)
+	// PublishUpload moves the upload staged at key to the live image filename
+	PublishUpload(ctx context.Context, key string, filename string) (ImageInfo, error)
+	// DeleteUpload deletes the upload staged at key, a missing upload isn't an error
+	DeleteUpload(ctx context.Context, key string) error
+	// Ping checks the storage is reachable
+	Ping(ctx context.Context) error
+}
+
+// sniffContentType detects the content type from the first 512 bytes of image and returns
+// a reader that still yields the whole image
+func sniffContentType(image io.Reader) (string, io.Reader, error) {
+	buffered := bufio.NewReaderSize(image, 512)
+	head, err := buffered.Peek(512)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return "", nil, err
+	}
+	contentType := http.DetectContentType(head)
+	if len(contentType) < 6 || contentType[:6] != "image/" {
+		contentType = "image/png"
+	}
+	return contentType, buffered, nil
+}
+
+// countingReader counts the bytes read and stops with ctx.Err() once ctx is done
+type countingReader struct {
+	ctx context.Context
+	r   io.Reader
+	n   int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
 }
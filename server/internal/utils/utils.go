@@ -1,70 +1,14 @@
 package utils
 
 import (
-	"os"
 	"fmt"
-	"log"
 	"mime"
+	"net"
 	"net/http"
-	"net/url"
-	"strconv"
 	"strings"
 	"github.com/google/uuid"
 )
 
-const MAX_FILE_SIZE = 2 * 1024 * 1024
-
-func GetEnvOrDefault(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
-// validates that the provided url is a valid image with size < 2MB
-// data is validated with HEAD request so you should still re-validate the result
-func ValidateImageContent(URL string, client *http.Client) (bool, error) {
-
-	// Check MIME type via HEAD
-	parsedURL, err := url.Parse(URL)
-	if err != nil {
-		return false, err
-	}
-
-	req := &http.Request{
-		Method: http.MethodHead,
-		URL:    parsedURL,
-		Header: http.Header{
-			"Accept": []string{"image/*"},
-		},
-	}
-	resp, err := client.Do(req)
-	if err != nil || resp.StatusCode != 200 {
-		return false, err
-	}
-
-	contentType := resp.Header.Get("Content-Type")
-	if !strings.HasPrefix(contentType, "image/") {
-		return false, nil
-	}
-
-	sizeBytes := resp.Header.Get("Content-Length")
-	if size, err := strconv.Atoi(sizeBytes); err != nil || size > MAX_FILE_SIZE {
-		return false, nil
-	}
-	return true, nil
-}
-
-
-func GetEnvOrExit(key string) string {
-	if value, ok := os.LookupEnv(key); ok && value != "" {
-		return value
-	}
-	log.Println("Environment variable", key, "not set or empty")
-	os.Exit(1)
-	return ""
-}
-
 // returns a random UUID
 func RandomUUID() string {
 	return uuid.New().String()
@@ -84,3 +28,19 @@ func MimeToExtension(mimeType string) (string, error) {
 	}
 	return ext[len(ext)-1], nil
 }
+
+// ClientIP returns the IP of the client that sent r, using the proxy headers when set
+func ClientIP(r *http.Request) string {
+	// Check common proxy headers
+	if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+		return realIP
+	}
+	if forwardedFor := r.Header.Get("X-Forwarded-For"); forwardedFor != "" {
+		// Take first IP in X-Forwarded-For list
+		ip, _, _ := strings.Cut(forwardedFor, ",")
+		return strings.TrimSpace(ip)
+	}
+	// Fallback to remote address
+	ip, _, _ := net.SplitHostPort(r.RemoteAddr)
+	return ip
+}
@@ -0,0 +1,114 @@
+-- Migration: Soft delete memes
+-- Date: 2026-10-19
+-- Description: Adds deleted_at to meme so deletes can be undone from the admin trash,
+--              and excludes deleted memes from the fuzzy search function
+
+ALTER TABLE meme
+ADD COLUMN deleted_at TIMESTAMP;
+
+-- Trash listing and the purge job only ever look at deleted rows
+CREATE INDEX idx_meme_deleted_at ON meme(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Recreate the fuzzy meme search function with the deleted_at filter
+DROP FUNCTION IF EXISTS public.search_memes_fuzzy(text);
+
+CREATE OR REPLACE FUNCTION public.search_memes_fuzzy(search_query text)
+RETURNS TABLE(id uuid, media_url text, media_type text, name text, dimensions integer[], rank double precision)
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+    RETURN QUERY
+    WITH
+    -- Full-text search results
+    fts_results AS (
+        SELECT
+            m.id,
+            m.media_url,
+            m.media_type,
+            m.name,
+            m.dimensions,
+            ts_rank(m.search_vector,
+                websearch_to_tsquery('english', search_query) ||
+                websearch_to_tsquery('arabic', search_query)
+            ) AS fts_rank,
+            0.0::double precision AS trgm_rank
+        FROM
+            meme m
+        WHERE
+            m.approval_status = 'approved'
+            AND m.deleted_at IS NULL
+            AND m.search_vector @@ (
+                websearch_to_tsquery('english', search_query) ||
+                websearch_to_tsquery('arabic', search_query)
+            )
+    ),
+    -- Trigram similarity search on meme names
+    trgm_meme_results AS (
+        SELECT
+            m.id,
+            m.media_url,
+            m.media_type,
+            m.name,
+            m.dimensions,
+            0.0::double precision AS fts_rank,
+            similarity(m.name, search_query)::double precision AS trgm_rank
+        FROM
+            meme m
+        WHERE
+            m.approval_status = 'approved'
+            AND m.deleted_at IS NULL
+            AND similarity(m.name, search_query) > 0.3
+    ),
+    -- Trigram similarity search on tag names
+    trgm_tag_results AS (
+        SELECT DISTINCT
+            m.id,
+            m.media_url,
+            m.media_type,
+            m.name,
+            m.dimensions,
+            0.0::double precision AS fts_rank,
+            MAX(similarity(t.name, search_query))::double precision AS trgm_rank
+        FROM
+            meme m
+            JOIN meme_tag mt ON m.id = mt.meme_id
+            JOIN tag t ON mt.tag_id = t.id
+        WHERE
+            m.approval_status = 'approved'
+            AND m.deleted_at IS NULL
+            AND similarity(t.name, search_query) > 0.3
+        GROUP BY m.id, m.media_url, m.media_type, m.name, m.dimensions
+    ),
+    -- Combine all results
+    combined_results AS (
+        SELECT * FROM fts_results
+        UNION ALL
+        SELECT * FROM trgm_meme_results
+        UNION ALL
+        SELECT * FROM trgm_tag_results
+    )
+    -- Deduplicate and rank
+    SELECT
+        cr.id,
+        cr.media_url,
+        cr.media_type,
+        cr.name,
+        cr.dimensions,
+        -- Prioritize FTS results over trigram results
+        (MAX(cr.fts_rank) * 2.0 + MAX(cr.trgm_rank)) AS rank
+    FROM
+        combined_results cr
+    GROUP BY
+        cr.id, cr.media_url, cr.media_type, cr.name, cr.dimensions
+    ORDER BY
+        rank DESC;
+END;
+$$;
+
+ALTER FUNCTION public.search_memes_fuzzy(text) OWNER TO postgres;
+
+-- Rollback instructions (commented out):
+-- To rollback this migration, run:
+-- DROP INDEX IF EXISTS idx_meme_deleted_at;
+-- ALTER TABLE meme DROP COLUMN IF EXISTS deleted_at;
+-- and restore search_memes_fuzzy from 003_filter_approved_memes_in_search.sql
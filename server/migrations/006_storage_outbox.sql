@@ -0,0 +1,23 @@
+-- Migration: Storage outbox
+-- Date: 2026-10-19
+-- Description: Records object storage operations (soft deletes, renames, purges) in the same
+--              transaction as the database change so they only run after commit and can be retried
+
+CREATE TABLE IF NOT EXISTS storage_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    operation VARCHAR(20) NOT NULL,
+    filename TEXT NOT NULL,
+    target TEXT,
+    attempts INTEGER DEFAULT 0 NOT NULL,
+    last_error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    processed_at TIMESTAMP,
+    CONSTRAINT valid_storage_operation CHECK (operation IN ('soft_delete', 'rename', 'purge'))
+);
+
+-- The reconciler only scans entries that haven't been processed yet
+CREATE INDEX idx_storage_outbox_pending ON storage_outbox(id) WHERE processed_at IS NULL;
+
+-- Rollback instructions (commented out):
+-- To rollback this migration, run:
+-- DROP TABLE IF EXISTS storage_outbox;
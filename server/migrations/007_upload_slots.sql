@@ -0,0 +1,23 @@
+-- Migration: Upload slots
+-- Date: 2026-10-19
+-- Description: Tracks presigned direct-to-bucket uploads between the moment a client asks for
+--              an upload URL and the moment it finalizes the upload into a meme
+
+CREATE TABLE IF NOT EXISTS upload_slot (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    filename TEXT NOT NULL UNIQUE,
+    media_type VARCHAR(50) NOT NULL,
+    size BIGINT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    finalized_at TIMESTAMP,
+    meme_id UUID REFERENCES meme(id) ON DELETE SET NULL,
+    CONSTRAINT positive_upload_size CHECK (size > 0)
+);
+
+-- Expired slots that were never finalized are cleaned up by expiry
+CREATE INDEX idx_upload_slot_expires_at ON upload_slot(expires_at) WHERE finalized_at IS NULL;
+
+-- Rollback instructions (commented out):
+-- To rollback this migration, run:
+-- DROP TABLE IF EXISTS upload_slot;
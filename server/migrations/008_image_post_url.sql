@@ -0,0 +1,21 @@
+-- Migration: Image post URL
+-- Date: 2026-10-19
+-- Description: Records the social media post an image was resolved from next to the CDN URL it
+--              was downloaded from, and allows Imgur as a platform
+
+ALTER TABLE images ADD COLUMN IF NOT EXISTS post_url TEXT;
+
+-- CDN URLs carry signed query strings that don't fit in 255 characters
+ALTER TABLE images ALTER COLUMN url TYPE TEXT;
+
+ALTER TABLE images DROP CONSTRAINT IF EXISTS valid_platform;
+ALTER TABLE images ADD CONSTRAINT valid_platform
+    CHECK (social_media_platform IN ('FB', 'X', 'Instagram', 'LinkedIn', 'Pinterest', 'Reddit', 'Imgur', 'Other'));
+
+-- Rollback instructions (commented out):
+-- To rollback this migration, run:
+-- ALTER TABLE images DROP CONSTRAINT IF EXISTS valid_platform;
+-- ALTER TABLE images ADD CONSTRAINT valid_platform
+--     CHECK (social_media_platform IN ('FB', 'X', 'Instagram', 'LinkedIn', 'Pinterest', 'Reddit', 'Other'));
+-- ALTER TABLE images ALTER COLUMN url TYPE VARCHAR(255);
+-- ALTER TABLE images DROP COLUMN IF EXISTS post_url;
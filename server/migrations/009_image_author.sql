@@ -0,0 +1,9 @@
+-- Migration: Image author
+-- Date: 2026-10-19
+-- Description: Stores the handle of the account that posted a meme's image so it can be credited
+
+ALTER TABLE images ADD COLUMN IF NOT EXISTS author TEXT;
+
+-- Rollback instructions (commented out):
+-- To rollback this migration, run:
+-- ALTER TABLE images DROP COLUMN IF EXISTS author;
@@ -0,0 +1,20 @@
+-- Migration: Timeline filters
+-- Date: 2026-10-19
+-- Description: Indexes the timeline filters on tags and media type, tags are matched ignoring
+--              case by the timeline and the tag: search filter
+
+-- Tag names are looked up as lower(name) = ANY(...)
+CREATE INDEX IF NOT EXISTS idx_tag_name_lower ON tag (lower(name));
+
+-- The primary key of meme_tag starts with meme_id, category pages start from the tag
+CREATE INDEX IF NOT EXISTS idx_meme_tag_tag_id ON meme_tag (tag_id, meme_id);
+
+-- The newest first pages of a media type, e.g. the GIFs only view, only list published memes
+CREATE INDEX IF NOT EXISTS idx_meme_published_media_type ON meme (media_type, created_at DESC)
+    WHERE approval_status = 'approved' AND deleted_at IS NULL;
+
+-- Rollback instructions (commented out):
+-- To rollback this migration, run:
+-- DROP INDEX IF EXISTS idx_meme_published_media_type;
+-- DROP INDEX IF EXISTS idx_meme_tag_tag_id;
+-- DROP INDEX IF EXISTS idx_tag_name_lower;
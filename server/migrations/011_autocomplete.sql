@@ -0,0 +1,17 @@
+-- Migration: Autocomplete
+-- Date: 2026-10-19
+-- Description: Indexes the tag and meme names for the prefix lookups of autocomplete, the
+--              trigram indexes of the suggestions don't help with one or two characters
+
+-- lower(name) LIKE 'prefix%' only uses an index with the pattern operator class, unless the
+-- collation is C
+CREATE INDEX IF NOT EXISTS idx_tag_name_prefix ON tag (lower(name) text_pattern_ops);
+
+-- Only published memes are completed
+CREATE INDEX IF NOT EXISTS idx_meme_published_name_prefix ON meme (lower(name) text_pattern_ops)
+    WHERE approval_status = 'approved' AND deleted_at IS NULL;
+
+-- Rollback instructions (commented out):
+-- To rollback this migration, run:
+-- DROP INDEX IF EXISTS idx_meme_published_name_prefix;
+-- DROP INDEX IF EXISTS idx_tag_name_prefix;
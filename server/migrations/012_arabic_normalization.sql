@@ -0,0 +1,228 @@
+-- Migration: Arabic normalization
+-- Date: 2026-10-19
+-- Description: Matches tags and searches ignoring the Arabic spelling variants: alef with hamza
+--              or madda, taa marbuta and haa, alef maqsura and yaa, tashkeel and tatweel.
+--              normalize_arabic must fold text exactly like arabic.Normalize in Go, which
+--              normalizes the tags when they are saved.
+
+-- Removes the marks and folds the letters listed in internal/arabic/arabic.go
+CREATE OR REPLACE FUNCTION public.normalize_arabic(input text) RETURNS text
+    LANGUAGE sql IMMUTABLE STRICT PARALLEL SAFE
+    AS $$
+    SELECT translate(
+        regexp_replace(input, U&'[\0610-\061A\064B-\065F\0640\0670\06D6-\06ED]', '', 'g'),
+        U&'\0623\0625\0622\0671\0629\0649',
+        U&'\0627\0627\0627\0627\0647\064A'
+    );
+$$;
+
+ALTER FUNCTION public.normalize_arabic(text) OWNER TO postgres;
+
+-- Tags are identified by their normalized name in lower case
+ALTER TABLE tag ADD COLUMN normalized_name TEXT;
+UPDATE tag SET normalized_name = lower(normalize_arabic(btrim(name)));
+
+-- Merge the tags spelled differently until now into the oldest one
+CREATE TEMPORARY TABLE merged_tag AS
+SELECT id, min(id) OVER (PARTITION BY normalized_name) AS kept_id FROM tag;
+
+INSERT INTO meme_tag (meme_id, tag_id)
+SELECT mt.meme_id, merged.kept_id
+FROM meme_tag mt JOIN merged_tag merged ON merged.id = mt.tag_id
+WHERE merged.id <> merged.kept_id
+ON CONFLICT (meme_id, tag_id) DO NOTHING;
+
+DELETE FROM meme_tag mt USING merged_tag merged
+WHERE merged.id = mt.tag_id AND merged.id <> merged.kept_id;
+
+DELETE FROM tag t USING merged_tag merged
+WHERE merged.id = t.id AND merged.id <> merged.kept_id;
+
+DROP TABLE merged_tag;
+
+ALTER TABLE tag ALTER COLUMN normalized_name SET NOT NULL;
+ALTER TABLE tag ADD CONSTRAINT tag_normalized_name_key UNIQUE (normalized_name);
+
+-- Meme names are only searched, the database keeps their normalized name up to date
+ALTER TABLE meme ADD COLUMN normalized_name TEXT GENERATED ALWAYS AS (lower(normalize_arabic(name))) STORED;
+
+-- The lookups on the raw names move to the normalized ones
+DROP INDEX IF EXISTS idx_tag_name_trgm;
+DROP INDEX IF EXISTS idx_meme_name_trgm;
+DROP INDEX IF EXISTS idx_tag_name_lower;
+DROP INDEX IF EXISTS idx_tag_name_prefix;
+DROP INDEX IF EXISTS idx_meme_published_name_prefix;
+
+CREATE INDEX IF NOT EXISTS idx_tag_normalized_name_trgm ON tag USING GIN (normalized_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_meme_normalized_name_trgm ON meme USING GIN (normalized_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_tag_normalized_name_prefix ON tag (normalized_name text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_meme_published_normalized_name_prefix ON meme (normalized_name text_pattern_ops)
+    WHERE approval_status = 'approved' AND deleted_at IS NULL;
+
+-- The search vector indexes the normalized name and tags, the searches are normalized too
+CREATE OR REPLACE FUNCTION public.meme_search_vector(meme_name text, id uuid) RETURNS tsvector
+    LANGUAGE sql STABLE
+    AS $$
+    SELECT
+        setweight(to_tsvector('english', normalize_arabic(COALESCE(meme_name, ''))), 'A') ||
+        setweight(to_tsvector('arabic', normalize_arabic(COALESCE(meme_name, ''))), 'A') ||
+        setweight(to_tsvector('english', COALESCE(tags.names, '')), 'B') ||
+        setweight(to_tsvector('arabic', COALESCE(tags.names, '')), 'B')
+    FROM (
+        SELECT string_agg(t.normalized_name, ' ') AS names
+        FROM tag t
+        JOIN meme_tag mt ON mt.tag_id = t.id
+        WHERE mt.meme_id = meme_search_vector.id
+    ) tags;
+$$;
+
+CREATE OR REPLACE FUNCTION update_meme_search_vector() RETURNS trigger AS $$
+BEGIN
+    UPDATE meme
+    SET search_vector = meme_search_vector(meme.name, meme.id)
+    WHERE id = NEW.meme_id;
+    RETURN NEW;
+END;
+$$
+ LANGUAGE plpgsql;
+
+UPDATE meme SET search_vector = meme_search_vector(meme.name, meme.id);
+
+CREATE OR REPLACE FUNCTION public.search_tags_fuzzy(
+    search_query text,
+    similarity_threshold real DEFAULT 0.3,
+    result_limit integer DEFAULT 10
+) RETURNS TABLE(name text, similarity real)
+    LANGUAGE plpgsql
+    AS $$
+DECLARE
+    normalized_query text := lower(normalize_arabic(search_query));
+BEGIN
+    RETURN QUERY
+    SELECT
+        t.name,
+        similarity(t.normalized_name, normalized_query) AS sim
+    FROM
+        tag t
+    WHERE
+        similarity(t.normalized_name, normalized_query) > similarity_threshold
+    ORDER BY
+        sim DESC,
+        t.name ASC
+    LIMIT result_limit;
+END;
+$$;
+
+ALTER FUNCTION public.search_tags_fuzzy(text, real, integer) OWNER TO postgres;
+
+CREATE OR REPLACE FUNCTION public.search_memes_fuzzy(search_query text)
+RETURNS TABLE(id uuid, media_url text, media_type text, name text, dimensions integer[], rank double precision)
+    LANGUAGE plpgsql
+    AS $$
+DECLARE
+    normalized_query text := lower(normalize_arabic(search_query));
+BEGIN
+    RETURN QUERY
+    WITH
+    -- Full-text search results
+    fts_results AS (
+        SELECT
+            m.id,
+            m.media_url,
+            m.media_type,
+            m.name,
+            m.dimensions,
+            ts_rank(m.search_vector,
+                websearch_to_tsquery('english', normalized_query) ||
+                websearch_to_tsquery('arabic', normalized_query)
+            ) AS fts_rank,
+            0.0::double precision AS trgm_rank
+        FROM
+            meme m
+        WHERE
+            m.approval_status = 'approved'
+            AND m.deleted_at IS NULL
+            AND m.search_vector @@ (
+                websearch_to_tsquery('english', normalized_query) ||
+                websearch_to_tsquery('arabic', normalized_query)
+            )
+    ),
+    -- Trigram similarity search on meme names
+    trgm_meme_results AS (
+        SELECT
+            m.id,
+            m.media_url,
+            m.media_type,
+            m.name,
+            m.dimensions,
+            0.0::double precision AS fts_rank,
+            similarity(m.normalized_name, normalized_query)::double precision AS trgm_rank
+        FROM
+            meme m
+        WHERE
+            m.approval_status = 'approved'
+            AND m.deleted_at IS NULL
+            AND similarity(m.normalized_name, normalized_query) > 0.3
+    ),
+    -- Trigram similarity search on tag names
+    trgm_tag_results AS (
+        SELECT DISTINCT
+            m.id,
+            m.media_url,
+            m.media_type,
+            m.name,
+            m.dimensions,
+            0.0::double precision AS fts_rank,
+            MAX(similarity(t.normalized_name, normalized_query))::double precision AS trgm_rank
+        FROM
+            meme m
+            JOIN meme_tag mt ON m.id = mt.meme_id
+            JOIN tag t ON mt.tag_id = t.id
+        WHERE
+            m.approval_status = 'approved'
+            AND m.deleted_at IS NULL
+            AND similarity(t.normalized_name, normalized_query) > 0.3
+        GROUP BY m.id, m.media_url, m.media_type, m.name, m.dimensions
+    ),
+    -- Combine all results
+    combined_results AS (
+        SELECT * FROM fts_results
+        UNION ALL
+        SELECT * FROM trgm_meme_results
+        UNION ALL
+        SELECT * FROM trgm_tag_results
+    )
+    -- Deduplicate and rank
+    SELECT
+        cr.id,
+        cr.media_url,
+        cr.media_type,
+        cr.name,
+        cr.dimensions,
+        -- Prioritize FTS results over trigram results
+        (MAX(cr.fts_rank) * 2.0 + MAX(cr.trgm_rank)) AS rank
+    FROM
+        combined_results cr
+    GROUP BY
+        cr.id, cr.media_url, cr.media_type, cr.name, cr.dimensions
+    ORDER BY
+        rank DESC;
+END;
+$$;
+
+ALTER FUNCTION public.search_memes_fuzzy(text) OWNER TO postgres;
+
+-- Rollback instructions (commented out):
+-- To rollback this migration, run:
+-- restore search_memes_fuzzy from 005_soft_delete.sql, search_tags_fuzzy from
+-- 001_add_fuzzy_search.sql and update_meme_search_vector from 000_init.sql
+-- DROP FUNCTION IF EXISTS public.meme_search_vector(text, uuid);
+-- DROP INDEX IF EXISTS idx_meme_published_normalized_name_prefix;
+-- DROP INDEX IF EXISTS idx_tag_normalized_name_prefix;
+-- DROP INDEX IF EXISTS idx_meme_normalized_name_trgm;
+-- DROP INDEX IF EXISTS idx_tag_normalized_name_trgm;
+-- ALTER TABLE meme DROP COLUMN IF EXISTS normalized_name;
+-- ALTER TABLE tag DROP COLUMN IF EXISTS normalized_name;
+-- DROP FUNCTION IF EXISTS public.normalize_arabic(text);
+-- and recreate the indexes of 000_init.sql, 010_timeline_filters.sql and 011_autocomplete.sql.
+-- The merged tags aren't split again.
@@ -0,0 +1,142 @@
+// Package api has the request and response bodies of the REST API v1, which is documented in
+// openapi.yaml. The handlers never encode the generated protobuf structs directly so the JSON
+// shapes only change when this package does.
+package api
+
+import _ "embed"
+
+// OpenAPI is the OpenAPI 3 document of the API, served at /api/v1/openapi.yaml
+//
+//go:embed openapi.yaml
+var OpenAPI []byte
+
+// Meme is a meme as returned by every endpoint. The counts are always present, even when 0.
+type Meme struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	MediaURL      string   `json:"media_url"`
+	MediaType     string   `json:"media_type"`
+	Tags          []string `json:"tags"`
+	Dimensions    []int32  `json:"dimensions"`
+	DownloadCount int32    `json:"download_count"`
+	ShareCount    int32    `json:"share_count"`
+	// only set for memes in the trash, RFC 3339
+	DeletedAt string `json:"deleted_at,omitempty"`
+	// only set by GET /meme/{id} for memes with a known source
+	Source *Source `json:"source,omitempty"`
+}
+
+// Source is where a meme was found
+type Source struct {
+	// one of FB, X, Instagram, LinkedIn, Pinterest, Reddit, Imgur, Other
+	Platform string `json:"platform"`
+	URL      string `json:"url"`
+	PostURL  string `json:"post_url"`
+	Author   string `json:"author"`
+}
+
+// MemePage is a page of the timeline, search results, pending memes or trash
+type MemePage struct {
+	Memes      []Meme `json:"memes"`
+	TotalCount int32  `json:"total_count"`
+	Page       int32  `json:"page"`
+	TotalPages int32  `json:"total_pages"`
+	// only set by the search when it finds few memes, tag and meme names spelled like the query,
+	// e.g. "drake" for "drak"
+	Suggestions []string `json:"suggestions,omitempty"`
+	// only set by the search when it finds few memes, popular tags of memes like the query
+	RelatedTags []string `json:"related_tags,omitempty"`
+}
+
+type TagList struct {
+	Tags []string `json:"tags"`
+}
+
+// The kinds of Completion
+const (
+	CompletionTag  = "tag"
+	CompletionMeme = "meme"
+)
+
+// Completion is a tag or meme name starting with the autocompleted prefix
+type Completion struct {
+	Text string `json:"text"`
+	// CompletionTag or CompletionMeme
+	Kind string `json:"kind"`
+}
+
+type CompletionList struct {
+	Completions []Completion `json:"completions"`
+}
+
+// UploadSlot is where the client uploads an image before finalizing it
+type UploadSlot struct {
+	ID        string `json:"id"`
+	UploadURL string `json:"upload_url"`
+	Method    string `json:"method"`
+	// headers the upload request must send as is
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt string            `json:"expires_at"`
+}
+
+type LoginResponse struct {
+	Token string `json:"token"`
+	Role  string `json:"role"`
+}
+
+type Banner struct {
+	Text    string `json:"text"`
+	BgColor string `json:"bgColor"`
+	FgColor string `json:"fgColor"`
+}
+
+// Error is the body of every error response. Code is the name of the gRPC status code matching
+// the HTTP status, e.g. NOT_FOUND.
+type Error struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+	Details   any    `json:"details,omitempty"`
+}
+
+// UploadRequest is the "meme" field of the multipart POST /meme request, the image is either in
+// the "image" field or downloaded from MediaURL
+type UploadRequest struct {
+	Name      string   `json:"name" validate:"required"`
+	MediaURL  string   `json:"media_url,omitempty" validate:"omitempty,url"`
+	MimeType  string   `json:"mime_type,omitempty"`
+	Tags      []string `json:"tags" validate:"required"`
+	ImageData []byte   `json:"image,omitempty" validate:"omitempty,datauri"`
+}
+
+// UploadSlotRequest asks for a presigned URL to upload an image of Size bytes directly to storage
+type UploadSlotRequest struct {
+	MediaType string `json:"media_type" validate:"required"`
+	Size      int64  `json:"size" validate:"required,gt=0"`
+}
+
+type FinalizeUploadRequest struct {
+	Name string   `json:"name" validate:"required"`
+	Tags []string `json:"tags" validate:"required"`
+}
+
+// SourceRequest credits a meme to where it was found, the platform is detected from the URLs when empty
+type SourceRequest struct {
+	Platform string `json:"platform,omitempty" validate:"omitempty,oneof=FB X Instagram LinkedIn Pinterest Reddit Imgur Other"`
+	URL      string `json:"url,omitempty" validate:"omitempty,url"`
+	PostURL  string `json:"post_url,omitempty" validate:"omitempty,url"`
+	Author   string `json:"author,omitempty" validate:"omitempty,max=100"`
+}
+
+type AddTagsRequest struct {
+	Tags []string `json:"tags" validate:"required"`
+}
+
+// PatchRequest is the "meme" field of the multipart PATCH /admin/meme/{id} request
+type PatchRequest struct {
+	Name      string   `json:"name,omitempty" validate:"omitempty"`
+	MediaURL  string   `json:"media_url,omitempty" validate:"omitempty,url"`
+	MimeType  string   `json:"mime_type,omitempty"`
+	Tags      []string `json:"tags,omitempty" validate:"omitempty"`
+	ImageData []byte   `json:"image,omitempty" validate:"omitempty,datauri"`
+}
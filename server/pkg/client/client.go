@@ -0,0 +1,259 @@
+// Package client is a Go client of the memesHub REST API v1, see pkg/api/openapi.yaml.
+//
+//	c := client.New(client.Options{BaseURL: "https://qasrelmemez.com", Username: "admin", Password: pass})
+//	for meme, err := range c.PendingAll(ctx, client.PageOptions{}) {
+//		...
+//	}
+//
+// Responses with status 429, and 503 for GET, PUT and DELETE, are retried with exponential
+// backoff. A 503 may come after the API handled the request so other methods aren't sent twice,
+// e.g. a retried upload could create the meme twice. The admin methods log in with the
+// credentials of Options on first use and log in again when the token is rejected.
+//
+// The download and share counters are only updated for requests made by the web client so they
+// have no method.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BassemHalim/memesHub/pkg/api"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultRetryDelay = 500 * time.Millisecond
+	// the longest a Retry-After header can make a retry wait
+	maxRetryDelay = 30 * time.Second
+)
+
+// ErrNoCredentials is returned by Login when Options has no username or password
+var ErrNoCredentials = errors.New("no admin credentials")
+
+type Options struct {
+	// where memesHub is served, e.g. https://qasrelmemez.com, the requests go to BaseURL/api/v1
+	BaseURL string
+	// defaults to http.DefaultClient
+	HTTPClient *http.Client
+	// admin credentials, only needed by the admin methods
+	Username string
+	Password string
+	// admin token to use until it is rejected, e.g. one saved from a previous Login
+	Token string
+	// retries of a request answered with 429, or 503 for idempotent methods, defaults to 3.
+	// Negative disables retries.
+	MaxRetries int
+	// delay before the first retry, doubled for every following one. Defaults to 500ms, a
+	// Retry-After header takes precedence.
+	RetryDelay time.Duration
+}
+
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	username   string
+	password   string
+	maxRetries int
+	retryDelay time.Duration
+
+	mu    sync.Mutex
+	token string
+}
+
+func New(opts Options) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(opts.BaseURL, "/") + "/api/v1",
+		httpClient: opts.HTTPClient,
+		username:   opts.Username,
+		password:   opts.Password,
+		token:      opts.Token,
+		maxRetries: opts.MaxRetries,
+		retryDelay: opts.RetryDelay,
+	}
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+	switch {
+	case c.maxRetries == 0:
+		c.maxRetries = defaultMaxRetries
+	case c.maxRetries < 0:
+		c.maxRetries = 0
+	}
+	if c.retryDelay <= 0 {
+		c.retryDelay = defaultRetryDelay
+	}
+	return c
+}
+
+// Error is an error response of the API
+type Error struct {
+	StatusCode int
+	// name of the gRPC status code matching StatusCode, e.g. NOT_FOUND
+	Code      string
+	Message   string
+	RequestID string
+}
+
+func (e *Error) Error() string {
+	if e.RequestID != "" {
+		return fmt.Sprintf("memesHub: %d %s: %s (request %s)", e.StatusCode, e.Code, e.Message, e.RequestID)
+	}
+	return fmt.Sprintf("memesHub: %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// Token returns the admin token, empty before the first login
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+// Login logs in with the credentials of Options, the admin methods call it when needed
+func (c *Client) Login(ctx context.Context) error {
+	if c.username == "" || c.password == "" {
+		return ErrNoCredentials
+	}
+	var resp api.LoginResponse
+	err := c.do(ctx, request{method: http.MethodPost, path: "/login", basicAuth: true}, &resp)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.token = resp.Token
+	c.mu.Unlock()
+	return nil
+}
+
+// request is an API call, body is kept as bytes so it can be sent again on retries
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	body        []byte
+	contentType string
+	// sends the admin token, logging in first if there is none
+	admin     bool
+	basicAuth bool
+}
+
+func jsonRequest(method, path string, body any) (request, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return request{}, err
+	}
+	return request{method: method, path: path, body: data, contentType: "application/json"}, nil
+}
+
+// do sends req, retrying it when the API is overloaded, and decodes the response into out when
+// it isn't nil
+func (c *Client) do(ctx context.Context, req request, out any) error {
+	loggedIn := false
+	if req.admin && c.Token() == "" && c.username != "" {
+		if err := c.Login(ctx); err != nil {
+			return err
+		}
+		loggedIn = true
+	}
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, req)
+		if err != nil {
+			return err
+		}
+		switch {
+		case retryable(req, resp) && attempt < c.maxRetries:
+			delay := retryAfter(resp, c.retryDelay<<attempt)
+			resp.Body.Close()
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+			continue
+		case resp.StatusCode == http.StatusUnauthorized && req.admin && !loggedIn && c.username != "":
+			// the token expired or the secret was rotated, log in again once
+			resp.Body.Close()
+			if err := c.Login(ctx); err != nil {
+				return err
+			}
+			loggedIn = true
+			continue
+		}
+		defer resp.Body.Close()
+		return decode(resp, out)
+	}
+}
+
+func (c *Client) send(ctx context.Context, req request) (*http.Response, error) {
+	u := c.baseURL + req.path
+	if len(req.query) > 0 {
+		u += "?" + req.query.Encode()
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u, bytes.NewReader(req.body))
+	if err != nil {
+		return nil, err
+	}
+	if req.contentType != "" {
+		httpReq.Header.Set("Content-Type", req.contentType)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if req.basicAuth {
+		httpReq.SetBasicAuth(c.username, c.password)
+	}
+	if token := c.Token(); req.admin && token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+	return c.httpClient.Do(httpReq)
+}
+
+// retryable reports whether req can be sent again after resp. The rate limiter answers 429 before
+// the request is handled, a 503 may come from a handler that already made changes.
+func retryable(req request, resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusServiceUnavailable:
+		return req.method == http.MethodGet || req.method == http.MethodPut || req.method == http.MethodDelete
+	}
+	return false
+}
+
+// retryAfter returns the delay of the Retry-After header in seconds, or fallback
+func retryAfter(resp *http.Response, fallback time.Duration) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return fallback
+	}
+	return min(time.Duration(seconds)*time.Second, maxRetryDelay)
+}
+
+func decode(resp *http.Response, out any) error {
+	if resp.StatusCode >= 400 {
+		apiErr := &Error{StatusCode: resp.StatusCode}
+		var body api.Error
+		if err := json.NewDecoder(resp.Body).Decode(&body); err == nil {
+			apiErr.Code, apiErr.Message, apiErr.RequestID = body.Code, body.Message, body.RequestID
+		} else {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return apiErr
+	}
+	if out == nil {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode the %s response: %w", resp.Request.URL.Path, err)
+	}
+	return nil
+}
@@ -0,0 +1,569 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/patrickmn/go-cache"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/BassemHalim/memesHub/internal/auth"
+	"github.com/BassemHalim/memesHub/internal/config"
+	"github.com/BassemHalim/memesHub/internal/fetcher"
+	pb "github.com/BassemHalim/memesHub/internal/proto/memeService"
+	rateLimiter "github.com/BassemHalim/memesHub/internal/rate-limiter/IP_ratelimiter"
+	"github.com/BassemHalim/memesHub/internal/server"
+	"github.com/BassemHalim/memesHub/pkg/api"
+)
+
+const (
+	testUser     = "admin"
+	testPassword = "password"
+)
+
+// fakeMemeService keeps the memes in memory, in upload order
+type fakeMemeService struct {
+	pb.UnimplementedMemeServiceServer
+	mu       sync.Mutex
+	memes    []*pb.MemeResponse
+	pending  map[string]bool
+	timeline atomic.Int32
+}
+
+func newFakeMemeService() *fakeMemeService {
+	return &fakeMemeService{pending: map[string]bool{}}
+}
+
+// add stores an approved meme
+func (f *fakeMemeService) add(name string, tags ...string) *pb.MemeResponse {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	meme := &pb.MemeResponse{Id: uuid.NewString(), Name: name, Tags: tags, Dimensions: []int32{1, 1}}
+	f.memes = append(f.memes, meme)
+	return meme
+}
+
+func (f *fakeMemeService) find(id string) *pb.MemeResponse {
+	for _, m := range f.memes {
+		if m.Id == id {
+			return m
+		}
+	}
+	return nil
+}
+
+// page returns the memes matching keep
+func (f *fakeMemeService) page(page, pageSize int32, keep func(*pb.MemeResponse) bool) *pb.MemesResponse {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var matching []*pb.MemeResponse
+	for _, m := range f.memes {
+		if keep(m) {
+			matching = append(matching, m)
+		}
+	}
+	total := int32(len(matching))
+	start := min((page-1)*pageSize, total)
+	return &pb.MemesResponse{
+		Memes:      matching[start:min(start+pageSize, total)],
+		TotalCount: total,
+		Page:       page,
+		TotalPages: (total + pageSize - 1) / pageSize,
+	}
+}
+
+func (f *fakeMemeService) public(m *pb.MemeResponse) bool {
+	return !f.pending[m.Id] && m.DeletedAt == ""
+}
+
+func (f *fakeMemeService) UploadMeme(ctx context.Context, in *pb.UploadMemeRequest) (*pb.MemeResponse, error) {
+	meme := f.add(in.Name, in.Tags...)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	meme.MediaType, meme.Dimensions = in.MediaType, in.Dimensions
+	f.pending[meme.Id] = true
+	return meme, nil
+}
+
+func (f *fakeMemeService) UpdateMeme(ctx context.Context, in *pb.UpdateMemeRequest) (*pb.UpdateMemeResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	meme := f.find(in.Id)
+	if meme == nil {
+		return nil, status.Error(codes.NotFound, "Meme not found")
+	}
+	if in.Name != "" {
+		meme.Name = in.Name
+	}
+	if len(in.Image) > 0 {
+		meme.MediaType, meme.Dimensions = in.MediaType, in.Dimensions
+	}
+	return &pb.UpdateMemeResponse{}, nil
+}
+
+func (f *fakeMemeService) GetMeme(ctx context.Context, in *pb.GetMemeRequest) (*pb.MemeResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if meme := f.find(in.Id); meme != nil {
+		return meme, nil
+	}
+	return nil, status.Error(codes.NotFound, "Meme not found")
+}
+
+func (f *fakeMemeService) GetTimelineMemes(ctx context.Context, in *pb.GetTimelineRequest) (*pb.MemesResponse, error) {
+	f.timeline.Add(1)
+	return f.page(in.Page, in.PageSize, func(m *pb.MemeResponse) bool {
+		has := func(tag string) bool { return slices.Contains(m.Tags, tag) }
+		matches := len(in.Tags) == 0 || slices.ContainsFunc(in.Tags, has)
+		if in.TagMatch == pb.TagMatch_ALL_TAGS {
+			matches = !slices.ContainsFunc(in.Tags, func(tag string) bool { return !has(tag) })
+		}
+		return f.public(m) && matches && !slices.ContainsFunc(in.ExcludeTags, has) &&
+			(in.MediaType == "" || m.MediaType == in.MediaType)
+	}), nil
+}
+
+func (f *fakeMemeService) SearchMemes(ctx context.Context, in *pb.SearchMemesRequest) (*pb.MemesResponse, error) {
+	return f.page(in.Page, in.PageSize, func(m *pb.MemeResponse) bool {
+		return f.public(m) && (strings.Contains(m.Name, in.Query) || slices.Contains(m.Tags, in.Query))
+	}), nil
+}
+
+func (f *fakeMemeService) SearchTags(ctx context.Context, in *pb.SearchTagsRequest) (*pb.TagsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tags := []string{}
+	for _, m := range f.memes {
+		for _, tag := range m.Tags {
+			if strings.HasPrefix(tag, in.Query) && !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return &pb.TagsResponse{Tags: tags}, nil
+}
+
+func (f *fakeMemeService) Autocomplete(ctx context.Context, in *pb.AutocompleteRequest) (*pb.AutocompleteResponse, error) {
+	tags, _ := f.SearchTags(ctx, &pb.SearchTagsRequest{Query: in.Prefix})
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var completions []*pb.Completion
+	for _, tag := range tags.Tags {
+		completions = append(completions, &pb.Completion{Text: tag, Kind: pb.CompletionKind_COMPLETION_TAG})
+	}
+	for _, m := range f.memes {
+		if f.public(m) && strings.HasPrefix(m.Name, in.Prefix) {
+			completions = append(completions, &pb.Completion{Text: m.Name, Kind: pb.CompletionKind_COMPLETION_MEME})
+		}
+	}
+	return &pb.AutocompleteResponse{Completions: completions}, nil
+}
+
+func (f *fakeMemeService) AddTags(ctx context.Context, in *pb.AddTagsRequest) (*pb.AddTagsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	meme := f.find(in.MemeId)
+	if meme == nil {
+		return nil, status.Error(codes.NotFound, "Meme not found")
+	}
+	meme.Tags = append(meme.Tags, in.Tags...)
+	return &pb.AddTagsResponse{}, nil
+}
+
+func (f *fakeMemeService) GetPendingMemes(ctx context.Context, in *pb.GetPendingMemesRequest) (*pb.MemesResponse, error) {
+	return f.page(in.Page, in.PageSize, func(m *pb.MemeResponse) bool { return f.pending[m.Id] }), nil
+}
+
+func (f *fakeMemeService) ApproveMeme(ctx context.Context, in *pb.ApproveMemeRequest) (*pb.ApproveMemeResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.pending[in.MemeId] {
+		return nil, status.Error(codes.NotFound, "Pending meme not found")
+	}
+	delete(f.pending, in.MemeId)
+	return &pb.ApproveMemeResponse{}, nil
+}
+
+func (f *fakeMemeService) DeleteMeme(ctx context.Context, in *pb.DeleteMemeRequest) (*pb.DeleteMemeResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	meme := f.find(in.Id)
+	if meme == nil {
+		return nil, status.Error(codes.NotFound, "Meme not found")
+	}
+	meme.DeletedAt = time.Now().Format(time.RFC3339)
+	return &pb.DeleteMemeResponse{}, nil
+}
+
+func (f *fakeMemeService) GetDeletedMemes(ctx context.Context, in *pb.GetDeletedMemesRequest) (*pb.MemesResponse, error) {
+	return f.page(in.Page, in.PageSize, func(m *pb.MemeResponse) bool { return m.DeletedAt != "" }), nil
+}
+
+func (f *fakeMemeService) RestoreMeme(ctx context.Context, in *pb.RestoreMemeRequest) (*pb.RestoreMemeResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	meme := f.find(in.MemeId)
+	if meme == nil || meme.DeletedAt == "" {
+		return nil, status.Error(codes.NotFound, "Meme not found in trash")
+	}
+	meme.DeletedAt = ""
+	return &pb.RestoreMemeResponse{}, nil
+}
+
+// newTestAPI serves the real router over memes, wrap is applied to the router when not nil
+func newTestAPI(t *testing.T, memes *fakeMemeService, limiter *rateLimiter.RateLimiter, wrap func(http.Handler) http.Handler) *httptest.Server {
+	t.Helper()
+	log := slog.New(slog.DiscardHandler)
+	cfg := &config.Config{MaxUploadSize: 1 << 20, WhitelistedDomains: []string{"example.com"}}
+	if limiter == nil {
+		limiter = rateLimiter.NewRateLimiter(rate.Inf, 1, log)
+	}
+	memeFetcher := fetcher.New(fetcher.Options{AllowedDomains: cfg.WhitelistedDomains, MaxBytes: cfg.MaxUploadSize})
+	gateway, err := server.NewWithMemeService(memes, config.NewStore(cfg), limiter, log, memeFetcher, cache.New(time.Minute, time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	passHash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var handler http.Handler = gateway.Router(server.RouterOptions{
+		Admin:     auth.New("test-secret", testUser, string(passHash), log),
+		JWTSecret: "test-secret",
+	})
+	if wrap != nil {
+		handler = wrap(handler)
+	}
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 2, 3))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestUploadApproveAndSearch(t *testing.T) {
+	memes := newFakeMemeService()
+	srv := newTestAPI(t, memes, nil, nil)
+	c := New(Options{BaseURL: srv.URL, Username: testUser, Password: testPassword})
+	ctx := context.Background()
+
+	uploaded, err := c.UploadFile(ctx, "surprised cat", []string{"cats"}, bytes.NewReader(testPNG(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uploaded.MediaType != "image/png" || !slices.Equal(uploaded.Dimensions, []int32{2, 3}) {
+		t.Errorf("Expected a 2x3 png, got %+v", uploaded)
+	}
+
+	var pending []string
+	for meme, err := range c.PendingAll(ctx, PageOptions{}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		pending = append(pending, meme.ID)
+	}
+	if !slices.Equal(pending, []string{uploaded.ID}) {
+		t.Fatalf("Expected the upload to be pending, got %v", pending)
+	}
+	if err := c.Approve(ctx, uploaded.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddTags(ctx, uploaded.ID, []string{"catnip"}); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := c.Search(ctx, "cat", SearchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found.Memes) != 1 || found.Memes[0].ID != uploaded.ID {
+		t.Errorf("Expected the approved meme, got %+v", found)
+	}
+	tags, err := c.SearchTags(ctx, "cat", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(tags, []string{"cats", "catnip"}) {
+		t.Errorf("Expected both tags, got %v", tags)
+	}
+	completions, err := c.Autocomplete(ctx, "s", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(completions, []api.Completion{{Text: "surprised cat", Kind: api.CompletionMeme}}) {
+		t.Errorf("Expected the meme name, got %v", completions)
+	}
+}
+
+func TestTrash(t *testing.T) {
+	memes := newFakeMemeService()
+	meme := memes.add("old")
+	srv := newTestAPI(t, memes, nil, nil)
+	c := New(Options{BaseURL: srv.URL, Username: testUser, Password: testPassword})
+	ctx := context.Background()
+
+	if err := c.DeleteMeme(ctx, meme.Id); err != nil {
+		t.Fatal(err)
+	}
+	trash, err := c.Trash(ctx, PageOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trash.Memes) != 1 || trash.Memes[0].DeletedAt == "" {
+		t.Fatalf("Expected the deleted meme in the trash, got %+v", trash)
+	}
+	if err := c.RestoreMeme(ctx, meme.Id); err != nil {
+		t.Fatal(err)
+	}
+	var apiErr *Error
+	if err := c.RestoreMeme(ctx, meme.Id); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Restoring twice should be a 404, got %v", err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	srv := newTestAPI(t, newFakeMemeService(), nil, nil)
+	c := New(Options{BaseURL: srv.URL})
+	ctx := context.Background()
+
+	_, err := c.GetMeme(ctx, uuid.NewString())
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.Code != "NOT_FOUND" {
+		t.Errorf("Expected a NOT_FOUND error, got %v", err)
+	}
+	_, err = c.UploadURL(ctx, "meme", []string{"tag"}, "http://example.com/meme.png")
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected a 400 for an http URL, got %v", err)
+	}
+	if err := c.Login(ctx); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Expected ErrNoCredentials, got %v", err)
+	}
+	if err := c.FlushCache(ctx); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Admin calls without credentials should be a 401, got %v", err)
+	}
+}
+
+func TestTimelineAll(t *testing.T) {
+	memes := newFakeMemeService()
+	for range 25 {
+		memes.add("meme")
+	}
+	srv := newTestAPI(t, memes, nil, nil)
+	c := New(Options{BaseURL: srv.URL})
+
+	count := 0
+	for _, err := range c.TimelineAll(context.Background(), TimelineOptions{PageOptions: PageOptions{PageSize: 10}, Sort: SortNewest}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		count++
+	}
+	if count != 25 || memes.timeline.Load() != 3 {
+		t.Errorf("Expected 25 memes in 3 pages, got %d in %d", count, memes.timeline.Load())
+	}
+
+	memes.timeline.Store(0)
+	for range c.TimelineAll(context.Background(), TimelineOptions{PageOptions: PageOptions{PageSize: 10}}) {
+		break
+	}
+	if memes.timeline.Load() != 1 {
+		t.Errorf("Breaking out of the loop shouldn't fetch more pages, fetched %d", memes.timeline.Load())
+	}
+}
+
+func TestTimelineFilters(t *testing.T) {
+	memes := newFakeMemeService()
+	memes.add("goal", "football", "ucl")
+	memes.add("derby", "football")
+	memes.add("red card", "football", "nsfw")
+	memes.add("cat", "cats").MediaType = "image/gif"
+	srv := newTestAPI(t, memes, nil, nil)
+	c := New(Options{BaseURL: srv.URL})
+
+	tests := []struct {
+		opts TimelineOptions
+		want []string
+	}{
+		{TimelineOptions{Tags: []string{"football"}}, []string{"goal", "derby", "red card"}},
+		{TimelineOptions{Tags: []string{"ucl", "cats"}}, []string{"goal", "cat"}},
+		{TimelineOptions{Tags: []string{"football", "ucl"}, TagMatch: TagMatchAll}, []string{"goal"}},
+		{TimelineOptions{Tags: []string{"football"}, ExcludeTags: []string{"nsfw", "ucl"}}, []string{"derby"}},
+		{TimelineOptions{MediaType: "gif"}, []string{"cat"}},
+	}
+	for _, tt := range tests {
+		page, err := c.Timeline(context.Background(), tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, m := range page.Memes {
+			names = append(names, m.Name)
+		}
+		if !slices.Equal(names, tt.want) {
+			t.Errorf("Timeline(%+v) = %v, want %v", tt.opts, names, tt.want)
+		}
+	}
+}
+
+func TestTokenRefresh(t *testing.T) {
+	var logins atomic.Int32
+	countLogins := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/api/v1/login" {
+				logins.Add(1)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	srv := newTestAPI(t, newFakeMemeService(), nil, countLogins)
+	c := New(Options{BaseURL: srv.URL, Username: testUser, Password: testPassword, Token: "expired"})
+	ctx := context.Background()
+
+	if err := c.FlushCache(ctx); err != nil {
+		t.Fatal("The client should log in again when the token is rejected", err)
+	}
+	if err := c.FlushCache(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if logins.Load() != 1 || c.Token() == "expired" {
+		t.Errorf("Expected a single login replacing the token, got %d logins", logins.Load())
+	}
+
+	wrongPassword := New(Options{BaseURL: srv.URL, Username: testUser, Password: "wrong"})
+	var apiErr *Error
+	if err := wrongPassword.FlushCache(ctx); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a 401 for wrong credentials, got %v", err)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	var requests atomic.Int32
+	// the first two requests of every three are answered with 503
+	unavailable := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1)%3 != 0 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	srv := newTestAPI(t, newFakeMemeService(), nil, unavailable)
+	ctx := context.Background()
+
+	c := New(Options{BaseURL: srv.URL, RetryDelay: time.Millisecond})
+	if _, err := c.GetBanner(ctx); err != nil {
+		t.Fatal("A 503 should be retried", err)
+	}
+	if requests.Load() != 3 {
+		t.Errorf("Expected 3 attempts, got %d", requests.Load())
+	}
+
+	noRetries := New(Options{BaseURL: srv.URL, MaxRetries: -1})
+	var apiErr *Error
+	if _, err := noRetries.GetBanner(ctx); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected the 503 without retries, got %v", err)
+	}
+}
+
+func TestRetriesOnlyIdempotentRequests(t *testing.T) {
+	var posts atomic.Int32
+	unavailable := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				posts.Add(1)
+			}
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+	}
+	srv := newTestAPI(t, newFakeMemeService(), nil, unavailable)
+	c := New(Options{BaseURL: srv.URL, RetryDelay: time.Millisecond})
+
+	// the meme may have been created before the 503, sending it again could duplicate it
+	var apiErr *Error
+	if _, err := c.UploadURL(context.Background(), "meme", []string{"funny"}, "https://example.com/meme.png"); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected the 503, got %v", err)
+	}
+	if posts.Load() != 1 {
+		t.Errorf("A POST answered with 503 shouldn't be retried, sent %d times", posts.Load())
+	}
+}
+
+func TestRetriesRateLimited(t *testing.T) {
+	memes := newFakeMemeService()
+	meme := memes.add("meme")
+	// a token every 20ms
+	srv := newTestAPI(t, memes, rateLimiter.NewRateLimiter(50, 1, slog.New(slog.DiscardHandler)), nil)
+	c := New(Options{BaseURL: srv.URL, RetryDelay: 10 * time.Millisecond})
+
+	for range 3 {
+		if _, err := c.GetMeme(context.Background(), meme.Id); err != nil {
+			t.Fatal("A 429 should be retried", err)
+		}
+	}
+}
+
+func TestBanner(t *testing.T) {
+	// the banner is saved in the working directory
+	t.Chdir(t.TempDir())
+	srv := newTestAPI(t, newFakeMemeService(), nil, nil)
+	c := New(Options{BaseURL: srv.URL, Username: testUser, Password: testPassword})
+	ctx := context.Background()
+
+	banner := api.Banner{Text: "Ramadan Kareem", BgColor: "#000", FgColor: "#fff"}
+	if err := c.SetBanner(ctx, banner); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.GetBanner(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got != banner {
+		t.Errorf("Expected %+v, got %+v", banner, *got)
+	}
+}
+
+func TestUpdateMemeDetectsImageType(t *testing.T) {
+	memes := newFakeMemeService()
+	meme := memes.add("old")
+	srv := newTestAPI(t, memes, nil, nil)
+	c := New(Options{BaseURL: srv.URL, Username: testUser, Password: testPassword})
+	ctx := context.Background()
+
+	if err := c.UpdateMeme(ctx, meme.Id, api.PatchRequest{Name: "new"}, bytes.NewReader(testPNG(t))); err != nil {
+		t.Fatal(err)
+	}
+	if meme.MediaType != "image/png" || !slices.Equal(meme.Dimensions, []int32{2, 3}) {
+		t.Errorf("Expected the image to be sent as a 2x3 png, got %s %v", meme.MediaType, meme.Dimensions)
+	}
+	// without an image only the metadata changes
+	if err := c.UpdateMeme(ctx, meme.Id, api.PatchRequest{Name: "newer"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if meme.Name != "newer" || meme.MediaType != "image/png" {
+		t.Errorf("Expected only the name to change, got %s %s", meme.Name, meme.MediaType)
+	}
+}
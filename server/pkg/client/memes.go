@@ -0,0 +1,386 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"iter"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/BassemHalim/memesHub/pkg/api"
+)
+
+// timeline and search sort orders, search only supports SortRelevance, SortNewest and
+// SortMostDownloaded
+const (
+	SortRelevance      = "relevance"
+	SortNewest         = "newest"
+	SortOldest         = "oldest"
+	SortMostTagged     = "most_tagged"
+	SortMostDownloaded = "most_downloaded"
+	SortMostShared     = "most_shared"
+)
+
+// PageOptions selects a page, the zero value is the API's default of the first page of 10 memes
+type PageOptions struct {
+	Page     int
+	PageSize int
+}
+
+func (o PageOptions) query() url.Values {
+	q := url.Values{}
+	if o.Page > 0 {
+		q.Set("page", strconv.Itoa(o.Page))
+	}
+	if o.PageSize > 0 {
+		q.Set("pageSize", strconv.Itoa(o.PageSize))
+	}
+	return q
+}
+
+// how the memes of a filtered timeline match TimelineOptions.Tags
+const (
+	TagMatchAny = "any"
+	TagMatchAll = "all"
+)
+
+type TimelineOptions struct {
+	PageOptions
+	// one of the Sort constants, empty for the API's default of SortMostDownloaded
+	Sort string
+	// only the memes with any of Tags, or all of them with TagMatchAll
+	Tags     []string
+	TagMatch string
+	// never the memes with any of ExcludeTags
+	ExcludeTags []string
+	// only the memes of an image format: gif, png or jpg
+	MediaType string
+}
+
+func (o TimelineOptions) query() url.Values {
+	q := o.PageOptions.query()
+	if o.Sort != "" {
+		q.Set("sort", o.Sort)
+	}
+	if len(o.Tags) > 0 {
+		q.Set("tags", strings.Join(o.Tags, ","))
+	}
+	if len(o.ExcludeTags) > 0 {
+		q.Set("excludeTags", strings.Join(o.ExcludeTags, ","))
+	}
+	if o.TagMatch != "" {
+		q.Set("tagMatch", o.TagMatch)
+	}
+	if o.MediaType != "" {
+		q.Set("mediaType", o.MediaType)
+	}
+	return q
+}
+
+func memePath(prefix, id string) string {
+	return prefix + url.PathEscape(id)
+}
+
+func (c *Client) getPage(ctx context.Context, path string, query url.Values, admin bool) (*api.MemePage, error) {
+	var page api.MemePage
+	if err := c.do(ctx, request{method: http.MethodGet, path: path, query: query, admin: admin}, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// all yields the memes of every page starting from opts.Page, fetch gets a single page
+func all(opts PageOptions, fetch func(PageOptions) (*api.MemePage, error)) iter.Seq2[api.Meme, error] {
+	return func(yield func(api.Meme, error) bool) {
+		if opts.Page < 1 {
+			opts.Page = 1
+		}
+		for {
+			page, err := fetch(opts)
+			if err != nil {
+				yield(api.Meme{}, err)
+				return
+			}
+			for _, meme := range page.Memes {
+				if !yield(meme, nil) {
+					return
+				}
+			}
+			if len(page.Memes) == 0 || opts.Page >= int(page.TotalPages) {
+				return
+			}
+			opts.Page++
+		}
+	}
+}
+
+// Timeline returns a page of the public timeline
+func (c *Client) Timeline(ctx context.Context, opts TimelineOptions) (*api.MemePage, error) {
+	return c.getPage(ctx, "/memes", opts.query(), false)
+}
+
+// TimelineAll iterates over the public timeline from opts.Page to its last page
+func (c *Client) TimelineAll(ctx context.Context, opts TimelineOptions) iter.Seq2[api.Meme, error] {
+	return all(opts.PageOptions, func(p PageOptions) (*api.MemePage, error) {
+		opts.PageOptions = p
+		return c.Timeline(ctx, opts)
+	})
+}
+
+// AdminTimeline is Timeline without the caching and rate limiting of the public endpoint
+func (c *Client) AdminTimeline(ctx context.Context, opts TimelineOptions) (*api.MemePage, error) {
+	return c.getPage(ctx, "/admin/memes", opts.query(), true)
+}
+
+func (c *Client) AdminTimelineAll(ctx context.Context, opts TimelineOptions) iter.Seq2[api.Meme, error] {
+	return all(opts.PageOptions, func(p PageOptions) (*api.MemePage, error) {
+		opts.PageOptions = p
+		return c.AdminTimeline(ctx, opts)
+	})
+}
+
+type SearchOptions struct {
+	PageOptions
+	// SortRelevance, SortNewest or SortMostDownloaded, empty for the API's default of SortRelevance
+	Sort string
+}
+
+// Search returns a page of the memes matching query by name or tag, the query can use the
+// tag:, source:, type: and date filters of the API, e.g. "drake tag:funny -type:gif"
+func (c *Client) Search(ctx context.Context, query string, opts SearchOptions) (*api.MemePage, error) {
+	q := opts.query()
+	q.Set("query", query)
+	if opts.Sort != "" {
+		q.Set("sort", opts.Sort)
+	}
+	return c.getPage(ctx, "/memes/search", q, false)
+}
+
+func (c *Client) SearchAll(ctx context.Context, query string, opts SearchOptions) iter.Seq2[api.Meme, error] {
+	return all(opts.PageOptions, func(p PageOptions) (*api.MemePage, error) {
+		opts.PageOptions = p
+		return c.Search(ctx, query, opts)
+	})
+}
+
+// SearchTags returns up to limit tags starting with query, which must be at least 3 characters.
+// A limit of 0 uses the API's default of 5.
+func (c *Client) SearchTags(ctx context.Context, query string, limit int) ([]string, error) {
+	q := url.Values{"query": {query}}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	var tags api.TagList
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/tags/search", query: q}, &tags); err != nil {
+		return nil, err
+	}
+	return tags.Tags, nil
+}
+
+// Autocomplete returns up to limit tag and meme names starting with prefix, which can be a
+// single character. A limit of 0 uses the API's default of 10.
+func (c *Client) Autocomplete(ctx context.Context, prefix string, limit int) ([]api.Completion, error) {
+	q := url.Values{"query": {prefix}}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	var completions api.CompletionList
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/autocomplete", query: q}, &completions); err != nil {
+		return nil, err
+	}
+	return completions.Completions, nil
+}
+
+func (c *Client) GetMeme(ctx context.Context, id string) (*api.Meme, error) {
+	var meme api.Meme
+	if err := c.do(ctx, request{method: http.MethodGet, path: memePath("/meme/", id)}, &meme); err != nil {
+		return nil, err
+	}
+	return &meme, nil
+}
+
+// memeForm is the multipart body of the upload and update requests, image is optional
+func memeForm(method, path string, meme any, image io.Reader) (request, error) {
+	metadata, err := json.Marshal(meme)
+	if err != nil {
+		return request{}, err
+	}
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if err := form.WriteField("meme", string(metadata)); err != nil {
+		return request{}, err
+	}
+	if image != nil {
+		part, err := form.CreateFormFile("image", "image")
+		if err != nil {
+			return request{}, err
+		}
+		if _, err := io.Copy(part, image); err != nil {
+			return request{}, err
+		}
+	}
+	if err := form.Close(); err != nil {
+		return request{}, err
+	}
+	return request{method: method, path: path, body: body.Bytes(), contentType: form.FormDataContentType()}, nil
+}
+
+func (c *Client) upload(ctx context.Context, meme api.UploadRequest, image io.Reader) (*api.Meme, error) {
+	req, err := memeForm(http.MethodPost, "/meme", meme, image)
+	if err != nil {
+		return nil, err
+	}
+	var uploaded api.Meme
+	if err := c.do(ctx, req, &uploaded); err != nil {
+		return nil, err
+	}
+	return &uploaded, nil
+}
+
+// UploadFile uploads a JPEG, PNG or GIF image, the meme is pending until an admin approves it
+func (c *Client) UploadFile(ctx context.Context, name string, tags []string, image io.Reader) (*api.Meme, error) {
+	return c.upload(ctx, api.UploadRequest{Name: name, Tags: tags}, image)
+}
+
+// UploadURL makes the API download the image at mediaURL, it must be https on a whitelisted
+// domain or a supported social media post
+func (c *Client) UploadURL(ctx context.Context, name string, tags []string, mediaURL string) (*api.Meme, error) {
+	return c.upload(ctx, api.UploadRequest{Name: name, Tags: tags, MediaURL: mediaURL}, nil)
+}
+
+// CreateUploadSlot returns a presigned URL to upload an image of size bytes to before calling
+// FinalizeUpload
+func (c *Client) CreateUploadSlot(ctx context.Context, mediaType string, size int64) (*api.UploadSlot, error) {
+	req, err := jsonRequest(http.MethodPost, "/meme/upload", api.UploadSlotRequest{MediaType: mediaType, Size: size})
+	if err != nil {
+		return nil, err
+	}
+	var slot api.UploadSlot
+	if err := c.do(ctx, req, &slot); err != nil {
+		return nil, err
+	}
+	return &slot, nil
+}
+
+func (c *Client) FinalizeUpload(ctx context.Context, slotID string, name string, tags []string) (*api.Meme, error) {
+	req, err := jsonRequest(http.MethodPost, memePath("/meme/upload/", slotID)+"/finalize", api.FinalizeUploadRequest{Name: name, Tags: tags})
+	if err != nil {
+		return nil, err
+	}
+	var meme api.Meme
+	if err := c.do(ctx, req, &meme); err != nil {
+		return nil, err
+	}
+	return &meme, nil
+}
+
+func (c *Client) GetBanner(ctx context.Context) (*api.Banner, error) {
+	var banner api.Banner
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/banner"}, &banner); err != nil {
+		return nil, err
+	}
+	return &banner, nil
+}
+
+// SetBanner replaces the site banner, an empty banner hides it
+func (c *Client) SetBanner(ctx context.Context, banner api.Banner) error {
+	req, err := jsonRequest(http.MethodPut, "/admin/banner", banner)
+	if err != nil {
+		return err
+	}
+	req.admin = true
+	return c.do(ctx, req, nil)
+}
+
+// DeleteMeme moves a meme to the trash
+func (c *Client) DeleteMeme(ctx context.Context, id string) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: memePath("/admin/meme/", id), admin: true}, nil)
+}
+
+// RestoreMeme moves a meme out of the trash
+func (c *Client) RestoreMeme(ctx context.Context, id string) error {
+	return c.do(ctx, request{method: http.MethodPatch, path: memePath("/admin/meme/", id) + "/restore", admin: true}, nil)
+}
+
+// AddTags adds tags to a meme, its existing tags are kept
+func (c *Client) AddTags(ctx context.Context, id string, tags []string) error {
+	req, err := jsonRequest(http.MethodPatch, memePath("/admin/meme/", id)+"/tags", api.AddTagsRequest{Tags: tags})
+	if err != nil {
+		return err
+	}
+	req.admin = true
+	return c.do(ctx, req, nil)
+}
+
+// UpdateMeme updates the name and tags of a meme, and its image when image isn't nil or
+// update.MediaURL is set
+func (c *Client) UpdateMeme(ctx context.Context, id string, update api.PatchRequest, image io.Reader) error {
+	if update.MimeType == "" && image != nil {
+		data, err := io.ReadAll(image)
+		if err != nil {
+			return err
+		}
+		update.MimeType = http.DetectContentType(data)
+		image = bytes.NewReader(data)
+	} else if update.MimeType == "" {
+		// the API requires an image type even when the image doesn't change, like the web client
+		update.MimeType = "image/jpeg"
+	}
+	req, err := memeForm(http.MethodPatch, memePath("/admin/meme/", id), update, image)
+	if err != nil {
+		return err
+	}
+	req.admin = true
+	return c.do(ctx, req, nil)
+}
+
+// SetSource credits a meme to where it was found and returns the saved source
+func (c *Client) SetSource(ctx context.Context, id string, source api.SourceRequest) (*api.Source, error) {
+	req, err := jsonRequest(http.MethodPut, memePath("/admin/meme/", id)+"/source", source)
+	if err != nil {
+		return nil, err
+	}
+	req.admin = true
+	var saved api.Source
+	if err := c.do(ctx, req, &saved); err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// Pending returns a page of the memes waiting for approval
+func (c *Client) Pending(ctx context.Context, opts PageOptions) (*api.MemePage, error) {
+	return c.getPage(ctx, "/admin/memes/pending", opts.query(), true)
+}
+
+func (c *Client) PendingAll(ctx context.Context, opts PageOptions) iter.Seq2[api.Meme, error] {
+	return all(opts, func(p PageOptions) (*api.MemePage, error) {
+		return c.Pending(ctx, p)
+	})
+}
+
+// Approve publishes a pending meme
+func (c *Client) Approve(ctx context.Context, id string) error {
+	return c.do(ctx, request{method: http.MethodPatch, path: memePath("/admin/meme/", id) + "/approve", admin: true}, nil)
+}
+
+// Trash returns a page of the deleted memes
+func (c *Client) Trash(ctx context.Context, opts PageOptions) (*api.MemePage, error) {
+	return c.getPage(ctx, "/admin/memes/trash", opts.query(), true)
+}
+
+func (c *Client) TrashAll(ctx context.Context, opts PageOptions) iter.Seq2[api.Meme, error] {
+	return all(opts, func(p PageOptions) (*api.MemePage, error) {
+		return c.Trash(ctx, p)
+	})
+}
+
+// FlushCache empties the response cache of the API
+func (c *Client) FlushCache(ctx context.Context) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: "/admin/cache", admin: true}, nil)
+}